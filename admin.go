@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sftp-gw/handlers"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	adminSessionsPath = "/sessions"
+	adminUsersPath    = "/users"
+	adminReloadPath   = "/reload"
+	adminLogLevelPath = "/log/level"
+	adminMetricsPath  = "/metrics"
+	adminGCPath       = "/gc/"
+
+	adminShutdownTimeout = 5 * time.Second
+)
+
+type adminConfig struct {
+	Enabled bool
+	Address string
+	// Token is required in `Authorization: Bearer <token>` header of every request.
+	Token string
+}
+
+// adminServer is HTTP API for operators to manage active sessions.
+type adminServer struct {
+	log   *zap.Logger
+	app   *handlers.App
+	token string
+	// reload re-reads configuration, `POST /reload` is disabled if nil.
+	reload func() error
+	// level is the logger level changed with `/log/level`, it's disabled if nil.
+	level *zap.AtomicLevel
+}
+
+// logLevel is the request and response body of `/log/level`.
+type logLevel struct {
+	Level string `json:"level"`
+}
+
+func (s *adminServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(adminSessionsPath, s.listSessions)
+	mux.HandleFunc(adminSessionsPath+"/", s.terminateSession)
+	mux.HandleFunc(adminUsersPath, s.listUsers)
+	mux.Handle(adminMetricsPath, newMetricsHandler(s.app))
+	mux.HandleFunc(adminGCPath, s.deleteDuplicates)
+	if s.reload != nil {
+		mux.HandleFunc(adminReloadPath, s.reloadConfig)
+	}
+	if s.level != nil {
+		mux.HandleFunc(adminLogLevelPath, s.logLevel)
+	}
+	return s.authenticate(mux)
+}
+
+func (s *adminServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// listSessions handles `GET /sessions`.
+func (s *adminServer) listSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.app.Sessions()); err != nil {
+		s.log.Error("failed to write admin response", zap.Error(err))
+	}
+}
+
+// listUsers handles `GET /users`.
+func (s *adminServer) listUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.app.UserStats()); err != nil {
+		s.log.Error("failed to write admin response", zap.Error(err))
+	}
+}
+
+// terminateSession handles `DELETE /sessions/<id>`.
+func (s *adminServer) terminateSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, adminSessionsPath+"/")
+	err := s.app.TerminateSession(id)
+	switch {
+	case errors.Is(err, handlers.ErrSessionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		s.log.Info("session terminated by admin", zap.String("session", id))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// deleteDuplicates handles `POST /gc/<container ID>`.
+func (s *adminServer) deleteDuplicates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var cnrID cid.ID
+	if err := cnrID.DecodeString(strings.TrimPrefix(r.URL.Path, adminGCPath)); err != nil {
+		http.Error(w, "invalid container ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := s.app.DeleteDuplicates(r.Context(), cnrID)
+	if err != nil {
+		s.log.Error("failed to delete duplicates", zap.Stringer("container", cnrID), zap.Int("deleted", deleted), zap.Error(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(struct {
+		Deleted int `json:"deleted"`
+	}{deleted}); err != nil {
+		s.log.Error("failed to write admin response", zap.Error(err))
+	}
+}
+
+// reloadConfig handles `POST /reload`.
+func (s *adminServer) reloadConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := s.reload(); err != nil {
+		s.log.Error("failed to reload configuration, keep the current one", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// logLevel handles `GET /log/level` and `PUT /log/level` with the level in JSON body. The level
+// is kept until the configuration is reloaded.
+func (s *adminServer) logLevel(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req logLevel
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		level, err := zapcore.ParseLevel(req.Level)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.level.SetLevel(level)
+		s.log.Info("logger level changed by admin", zap.Stringer("level", level))
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(logLevel{Level: s.level.Level().String()}); err != nil {
+		s.log.Error("failed to write admin response", zap.Error(err))
+	}
+}
+
+// runAdminServer serves admin API until ctx is done.
+func runAdminServer(ctx context.Context, l *zap.Logger, app *handlers.App, reload func() error, level *zap.AtomicLevel, conf adminConfig) {
+	srv := &http.Server{
+		Addr:              conf.Address,
+		Handler:           (&adminServer{log: l, app: app, token: conf.Token, reload: reload, level: level}).handler(),
+		ReadHeaderTimeout: adminShutdownTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), adminShutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			l.Error("failed to shutdown admin server", zap.Error(err))
+		}
+	}()
+
+	l.Info("admin API is listening", zap.String("address", conf.Address))
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		l.Error("admin server failed", zap.Error(err))
+	}
+}
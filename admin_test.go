@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/nspcc-dev/neofs-sftp-gw/handlers"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAdminServer(t *testing.T) {
+	app := handlers.NewApp(nil, nil, new(user.ID), zap.NewNop(), &handlers.SftpServerConfig{}, "")
+
+	var terminated bool
+	sess := app.StartSession("alice", "192.0.2.1:50000", func() error {
+		terminated = true
+		return nil
+	})
+	defer sess.EndSession()
+
+	srv := httptest.NewServer((&adminServer{log: zap.NewNop(), app: app, token: "secret"}).handler())
+	defer srv.Close()
+
+	do := func(method, path, token string) *http.Response {
+		req, err := http.NewRequest(method, srv.URL+path, nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = resp.Body.Close() })
+		return resp
+	}
+
+	require.Equal(t, http.StatusUnauthorized, do(http.MethodGet, adminSessionsPath, "").StatusCode)
+	require.Equal(t, http.StatusUnauthorized, do(http.MethodGet, adminSessionsPath, "wrong").StatusCode)
+
+	resp := do(http.MethodGet, adminSessionsPath, "secret")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var list []handlers.SessionInfo
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+	require.Len(t, list, 1)
+	require.Equal(t, "alice", list[0].User)
+	require.Equal(t, "192.0.2.1:50000", list[0].Remote)
+
+	resp = do(http.MethodGet, adminUsersPath, "secret")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var users []handlers.UserStats
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&users))
+	require.Len(t, users, 1)
+	require.Equal(t, "alice", users[0].User)
+	require.Equal(t, int64(1), users[0].Sessions)
+
+	require.Equal(t, http.StatusNotFound, do(http.MethodDelete, adminSessionsPath+"/unknown", "secret").StatusCode)
+	require.Equal(t, http.StatusNoContent, do(http.MethodDelete, adminSessionsPath+"/"+list[0].ID, "secret").StatusCode)
+	require.True(t, terminated)
+
+	require.Equal(t, http.StatusUnauthorized, do(http.MethodGet, adminMetricsPath, "").StatusCode)
+	require.Equal(t, http.StatusOK, do(http.MethodGet, adminMetricsPath, "secret").StatusCode)
+
+	require.Equal(t, http.StatusMethodNotAllowed, do(http.MethodGet, adminGCPath+"unknown", "secret").StatusCode)
+	require.Equal(t, http.StatusBadRequest, do(http.MethodPost, adminGCPath+"unknown", "secret").StatusCode)
+}
+
+func TestAdminLogLevel(t *testing.T) {
+	level := zap.NewAtomicLevelAt(zap.InfoLevel)
+	srv := httptest.NewServer((&adminServer{log: zap.NewNop(), token: "secret", level: &level}).handler())
+	defer srv.Close()
+
+	do := func(method, body string) (int, string) {
+		req, err := http.NewRequest(method, srv.URL+adminLogLevelPath, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var res logLevel
+		if resp.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		}
+		return resp.StatusCode, res.Level
+	}
+
+	code, lvl := do(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "info", lvl)
+
+	code, lvl = do(http.MethodPut, `{"level":"debug"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "debug", lvl)
+	require.Equal(t, zap.DebugLevel, level.Level())
+
+	code, _ = do(http.MethodPut, `{"level":"verbose"}`)
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = do(http.MethodPost, `{"level":"info"}`)
+	require.Equal(t, http.StatusMethodNotAllowed, code)
+	require.Equal(t, zap.DebugLevel, level.Level())
+}
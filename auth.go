@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/totp"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	defaultDevUser     = "test"
+	defaultDevPassword = "test"
+
+	authPassword            = "password"
+	authKeyboardInteractive = "keyboard-interactive"
+	authPublicKey           = "publickey"
+
+	// totpSkew is the number of adjacent TOTP steps accepted to tolerate clock drift.
+	totpSkew = 1
+)
+
+// authMethods are all supported authentication methods.
+var authMethods = []string{authPassword, authKeyboardInteractive, authPublicKey}
+
+func isAuthMethod(method string) bool {
+	for _, m := range authMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticator checks credentials of the built-in ssh server clients.
+type authenticator struct {
+	log   *zap.Logger
+	creds atomic.Pointer[credentials]
+}
+
+// credentials are the accepted credentials of all users, replaced as a whole on reload.
+type credentials struct {
+	// passwords maps user name to its password, users without password can't use password auth.
+	passwords map[string]string
+	// authorizedKeys maps user name to SHA256 fingerprints of keys accepted for the user.
+	authorizedKeys map[string]map[string]struct{}
+	// totpSecrets maps user name to the decoded TOTP shared secret.
+	totpSecrets map[string][]byte
+	// revoked is checked on every public key authentication, nil if not configured.
+	revoked *revocationList
+	// minRSAKeySize is the minimum size of RSA client keys, 0 means no limit.
+	minRSAKeySize int
+}
+
+func newAuthenticator(l *zap.Logger, devConf devConfig) (*authenticator, error) {
+	a := &authenticator{log: l}
+	if err := a.update(devConf); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// update replaces accepted credentials, already established connections are not affected.
+func (a *authenticator) update(devConf devConfig) error {
+	creds, err := newCredentials(a.log, devConf)
+	if err != nil {
+		return err
+	}
+	a.creds.Store(creds)
+	return nil
+}
+
+func newCredentials(l *zap.Logger, devConf devConfig) (*credentials, error) {
+	c := &credentials{
+		passwords:      make(map[string]string),
+		authorizedKeys: make(map[string]map[string]struct{}),
+		totpSecrets:    devConf.TOTPSecrets,
+		minRSAKeySize:  devConf.Crypto.MinRSAKeySize,
+	}
+
+	users := devConf.Users
+	if len(users) == 0 {
+		users = []devUserConfig{{Name: defaultDevUser, Password: defaultDevPassword}}
+	}
+
+	for _, u := range users {
+		if u.Password != "" {
+			c.passwords[u.Name] = u.Password
+		}
+		for _, line := range u.PublicKeys {
+			if err := c.addAuthorizedKeys(u.Name, []byte(line)); err != nil {
+				return nil, fmt.Errorf("public keys of user %q: %w", u.Name, err)
+			}
+		}
+	}
+
+	if devConf.AuthorizedKeysPath != "" {
+		data, err := os.ReadFile(devConf.AuthorizedKeysPath)
+		if err != nil {
+			return nil, fmt.Errorf("read authorized keys: %w", err)
+		}
+		if err = c.addAuthorizedKeys(users[0].Name, data); err != nil {
+			return nil, fmt.Errorf("authorized keys: %w", err)
+		}
+	}
+
+	if devConf.RevocationListPath != "" {
+		var err error
+		if c.revoked, err = newRevocationList(l, devConf.RevocationListPath); err != nil {
+			return nil, fmt.Errorf("load revocation list: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+// addAuthorizedKeys adds keys in authorized_keys format to the ones accepted for the user.
+func (c *credentials) addAuthorizedKeys(name string, data []byte) error {
+	if c.authorizedKeys[name] == nil {
+		c.authorizedKeys[name] = make(map[string]struct{})
+	}
+
+	for len(bytes.TrimSpace(data)) > 0 {
+		key, _, _, rest, err := ssh.ParseAuthorizedKey(data)
+		if err != nil {
+			return fmt.Errorf("parse key: %w", err)
+		}
+		c.authorizedKeys[name][ssh.FingerprintSHA256(key)] = struct{}{}
+		data = rest
+	}
+	return nil
+}
+
+// serverConfig returns ssh server config allowing the given authentication methods, all if empty.
+func (a *authenticator) serverConfig(methods []string) *ssh.ServerConfig {
+	if len(methods) == 0 {
+		methods = authMethods
+	}
+
+	config := new(ssh.ServerConfig)
+	for _, method := range methods {
+		switch method {
+		case authPassword:
+			config.PasswordCallback = a.passwordCallback
+		case authKeyboardInteractive:
+			config.KeyboardInteractiveCallback = a.keyboardInteractiveCallback
+		case authPublicKey:
+			config.PublicKeyCallback = a.publicKeyCallback
+		}
+	}
+	return config
+}
+
+func (c *credentials) checkPassword(name string, pass []byte) error {
+	expected, ok := c.passwords[name]
+	if ok && subtle.ConstantTimeCompare([]byte(expected), pass) == 1 {
+		return nil
+	}
+	return fmt.Errorf("password rejected for %q", name)
+}
+
+// passwordCallback accepts plain password authentication only for users without second factor,
+// others are expected to use keyboard-interactive method.
+func (a *authenticator) passwordCallback(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+	a.log.Debug("Login", zap.String("user", c.User()), zap.String("method", "password"))
+	creds := a.creds.Load()
+	if _, ok := creds.totpSecrets[strings.ToLower(c.User())]; ok {
+		return nil, fmt.Errorf("second factor is required for %q", c.User())
+	}
+	if err := creds.checkPassword(c.User(), pass); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (a *authenticator) publicKeyCallback(c ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+	fingerprint := ssh.FingerprintSHA256(key)
+	a.log.Debug("Login", zap.String("user", c.User()), zap.String("method", "publickey"),
+		zap.String("fingerprint", fingerprint))
+
+	creds := a.creds.Load()
+	if creds.revoked != nil && creds.revoked.isRevoked(key) {
+		a.log.Warn("revoked key rejected", zap.String("user", c.User()), zap.String("fingerprint", fingerprint))
+		return nil, fmt.Errorf("key %s is revoked", fingerprint)
+	}
+
+	if err := checkRSAKeySize(key, creds.minRSAKeySize); err != nil {
+		a.log.Warn("weak key rejected", zap.String("user", c.User()), zap.String("fingerprint", fingerprint), zap.Error(err))
+		return nil, err
+	}
+
+	if _, ok := creds.authorizedKeys[c.User()][fingerprint]; !ok {
+		return nil, fmt.Errorf("public key rejected for %q", c.User())
+	}
+
+	return &ssh.Permissions{Extensions: map[string]string{"pubkey-fp": fingerprint}}, nil
+}
+
+func (a *authenticator) keyboardInteractiveCallback(c ssh.ConnMetadata, challenge ssh.KeyboardInteractiveChallenge) (*ssh.Permissions, error) {
+	a.log.Debug("Login", zap.String("user", c.User()), zap.String("method", "keyboard-interactive"))
+
+	questions := []string{"Password: "}
+	echos := []bool{false}
+
+	creds := a.creds.Load()
+	secret, withTOTP := creds.totpSecrets[strings.ToLower(c.User())]
+	if withTOTP {
+		questions = append(questions, "Verification code: ")
+		echos = append(echos, true)
+	}
+
+	answers, err := challenge(c.User(), "", questions, echos)
+	if err != nil {
+		return nil, err
+	}
+	if len(answers) != len(questions) {
+		return nil, fmt.Errorf("unexpected number of answers for %q", c.User())
+	}
+
+	if err = creds.checkPassword(c.User(), []byte(answers[0])); err != nil {
+		return nil, err
+	}
+	if withTOTP && !totp.Validate(secret, answers[1], time.Now(), totpSkew) {
+		return nil, fmt.Errorf("verification code rejected for %q", c.User())
+	}
+
+	return nil, nil
+}
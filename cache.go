@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisConfig struct {
+	Address  string
+	Password string
+	DB       int
+}
+
+// redisCache is the cache shared by the gateway instances kept in Redis.
+type redisCache struct {
+	client *redis.Client
+}
+
+func newRedisCache(ctx context.Context, conf redisConfig) (*redisCache, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     conf.Address,
+		Password: conf.Password,
+		DB:       conf.DB,
+	})
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("ping %s: %w", conf.Address, err)
+	}
+	return &redisCache{client: client}, nil
+}
+
+// Get implements handlers.SharedCache.
+func (c *redisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set implements handlers.SharedCache.
+func (c *redisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Delete implements handlers.SharedCache.
+func (c *redisCache) Delete(ctx context.Context, keys ...string) error {
+	return c.client.Del(ctx, keys...).Err()
+}
+
+func (c *redisCache) Close() error {
+	return c.client.Close()
+}
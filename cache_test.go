@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis serves the RESP2 commands used by redisCache, entries don't expire.
+type fakeRedis struct {
+	mu       sync.Mutex
+	values   map[string]string
+	ttls     map[string]string
+	commands []string
+}
+
+func startFakeRedis(t *testing.T) (*fakeRedis, string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	srv := &fakeRedis{values: make(map[string]string), ttls: make(map[string]string)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn)
+		}
+	}()
+	return srv, ln.Addr().String()
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readRESPArray(r)
+		if err != nil {
+			return
+		}
+		if _, err = io.WriteString(conn, s.handle(args)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeRedis) handle(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cmd := strings.ToUpper(args[0])
+	s.commands = append(s.commands, strings.Join(append([]string{cmd}, args[1:]...), " "))
+	switch cmd {
+	case "HELLO":
+		return "-ERR unknown command 'HELLO'\r\n"
+	case "PING":
+		return "+PONG\r\n"
+	case "GET":
+		value, ok := s.values[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "SET":
+		s.values[args[1]] = args[2]
+		if len(args) > 4 {
+			s.ttls[args[1]] = strings.ToLower(args[3]) + " " + args[4]
+		}
+		return "+OK\r\n"
+	case "DEL":
+		var n int
+		for _, key := range args[1:] {
+			if _, ok := s.values[key]; ok {
+				delete(s.values, key)
+				n++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", n)
+	default:
+		return "+OK\r\n"
+	}
+}
+
+func readRESPArray(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		if line, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err = io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func TestRedisCache(t *testing.T) {
+	srv, addr := startFakeRedis(t)
+	ctx := context.Background()
+
+	cache, err := newRedisCache(ctx, redisConfig{Address: addr, Password: "secret", DB: 2})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cache.Close() })
+
+	_, ok, err := cache.Get(ctx, "container")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, cache.Set(ctx, "container", []byte{0, 1, 2}, 30*time.Second))
+	require.NoError(t, cache.Set(ctx, "eacl", nil, 30*time.Second))
+
+	value, ok, err := cache.Get(ctx, "container")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte{0, 1, 2}, value)
+
+	value, ok, err = cache.Get(ctx, "eacl")
+	require.NoError(t, err)
+	require.True(t, ok, "empty values are kept")
+	require.Empty(t, value)
+
+	require.NoError(t, cache.Delete(ctx, "container", "eacl"))
+	_, ok, err = cache.Get(ctx, "container")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	require.Contains(t, srv.commands, "AUTH secret")
+	require.Contains(t, srv.commands, "SELECT 2")
+	require.Equal(t, "ex 30", srv.ttls["container"])
+}
+
+func TestRedisCacheUnavailable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	_, err = newRedisCache(context.Background(), redisConfig{Address: addr})
+	require.Error(t, err)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/nspcc-dev/neofs-sftp-gw/handlers"
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+)
+
+// runCheck checks the connectivity with the configured settings: every peer is dialed separately
+// to tell unreachable nodes from the gateway misconfiguration, then the network info, the balance
+// and the containers of the owner are requested via all the peers. It fails if any peer or request
+// fails, an empty balance is reported only.
+func runCheck(args []string, out io.Writer) error {
+	flags := pflag.NewFlagSet("check", pflag.ContinueOnError)
+	timeout := flags.Duration("timeout", time.Minute, "timeout of the whole check")
+
+	v, err := commandSettings(flags, args)
+	if err != nil {
+		return err
+	}
+	userV, err := userSettings(v, nil)
+	if err != nil {
+		return fmt.Errorf("read user configuration: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	key, err := loadKey(userV, cfgWalletSection)
+	if err != nil {
+		return fmt.Errorf("load NeoFS private key: %w", err)
+	}
+	signer := user.NewAutoIDSignerRFC6979(key.PrivateKey)
+	owner := signer.UserID()
+	fmt.Fprintf(out, "Owner: %s\n", owner)
+
+	peers := fetchPeers(zap.NewNop(), userV)
+	if len(peers) == 0 {
+		return errors.New("no peers are configured")
+	}
+
+	var failed int
+	fmt.Fprintln(out, "Peers:")
+	for _, peer := range peers {
+		start := time.Now()
+		conns, ni, err := tryConnectNeoFS(ctx, zap.NewNop(), userV, signer, []peerConfig{peer}, nil)
+		if err != nil {
+			failed++
+			fmt.Fprintf(out, "  %s: FAIL: %v\n", peer.Address, err)
+			continue
+		}
+		conns.Close()
+		fmt.Fprintf(out, "  %s: ok, epoch %d, %s\n", peer.Address, ni.CurrentEpoch(), time.Since(start).Round(time.Millisecond))
+	}
+
+	conns, err := newPool(ctx, zap.NewNop(), userV, signer, peers, nil)
+	if err != nil {
+		return fmt.Errorf("%d of %d peers failed, pool: %w", failed, len(peers), err)
+	}
+	defer conns.Close()
+
+	if err = checkAccount(ctx, out, conns, owner); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d peers failed", failed, len(peers))
+	}
+	return nil
+}
+
+// checkAccount prints the network info, the balance and the containers of the owner.
+func checkAccount(ctx context.Context, out io.Writer, conns *pool.Pool, owner user.ID) error {
+	ni, err := conns.NetworkInfo(ctx, client.PrmNetworkInfo{})
+	if err != nil {
+		return fmt.Errorf("get network info: %w", err)
+	}
+	fmt.Fprintf(out, "Network: epoch %d, max object size %d, container fee %d\n",
+		ni.CurrentEpoch(), ni.MaxObjectSize(), ni.ContainerFee())
+
+	var balancePrm client.PrmBalanceGet
+	balancePrm.SetAccount(owner)
+	d, err := conns.BalanceGet(ctx, balancePrm)
+	if err != nil {
+		return fmt.Errorf("get balance: %w", err)
+	}
+	balance := handlers.Balance{Value: d.Value(), Precision: d.Precision()}
+	if balance.Value <= 0 {
+		fmt.Fprintf(out, "Balance: %s, WARN: containers can't be created with empty balance\n", balance)
+	} else {
+		fmt.Fprintf(out, "Balance: %s\n", balance)
+	}
+
+	ids, err := conns.ContainerList(ctx, owner, client.PrmContainerList{})
+	if err != nil {
+		return fmt.Errorf("list containers: %w", err)
+	}
+	fmt.Fprintf(out, "Containers: %d\n", len(ids))
+	for _, id := range ids {
+		cnr, err := conns.ContainerGet(ctx, id, client.PrmContainerGet{})
+		if err != nil {
+			fmt.Fprintf(out, "  %s: FAIL: %v\n", id, err)
+			continue
+		}
+		fmt.Fprintf(out, "  %s %s\n", id, cnr.Name())
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// defaultConfig is the commented configuration with the default values.
+//
+//go:embed config.default.yml
+var defaultConfig string
+
+// command is the subcommand of the gateway run instead of serving SFTP.
+type command struct {
+	description string
+	run         func(args []string, out io.Writer) error
+}
+
+var commands = map[string]command{
+	"genconfig": {
+		description: "print the configuration with the default values",
+		run: func(_ []string, out io.Writer) error {
+			_, err := io.WriteString(out, defaultConfig)
+			return err
+		},
+	},
+	"genkey": {
+		description: "generate the host key of the built-in SSH server",
+		run:         runGenKey,
+	},
+	"wallet": {
+		description: "create the wallet with a new account: wallet new",
+		run:         runWallet,
+	},
+	"check": {
+		description: "check connectivity with NeoFS and print the diagnostic report",
+		run:         runCheck,
+	},
+}
+
+// commandSettings parses the flags of the subcommand along with the configuration flags and
+// reads the configuration, it's optional for subcommands.
+func commandSettings(flags *pflag.FlagSet, args []string) (*viper.Viper, error) {
+	flags.StringArray(cfgConfigPath, nil, "config file or directory, repeat for several ones merged in order")
+	flags.String(cfgConfigType, "", "config format: yaml, json or toml, detected by the file extension if not set")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	v := newViper()
+	setMainDefaults(v)
+	setDefaults(v)
+	if err := v.BindPFlag(cfgConfigPath, flags.Lookup(cfgConfigPath)); err != nil {
+		return nil, err
+	}
+	if err := v.BindPFlag(cfgConfigType, flags.Lookup(cfgConfigType)); err != nil {
+		return nil, err
+	}
+	if err := readConfig(v); err != nil {
+		return nil, fmt.Errorf("read configuration: %w", err)
+	}
+	return v, nil
+}
+
+// printCommands prints the subcommands with their descriptions.
+func printCommands(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-12s %s\n", name, commands[name].description)
+	}
+}
+
+// runCommand runs the subcommand named by the first argument, it returns false if there is none.
+// The process exits with non-zero code if the subcommand fails.
+func runCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return false
+	}
+
+	if err := cmd.run(args[1:], os.Stdout); err != nil && !errors.Is(err, pflag.ErrHelp) {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", args[0], err)
+		os.Exit(1)
+	}
+	return true
+}
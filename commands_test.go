@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/wallet"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestGenConfig(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, commands["genconfig"].run(nil, &out))
+
+	generated := viper.New()
+	generated.SetConfigType(configType)
+	require.NoError(t, generated.ReadConfig(&out))
+
+	defaults := viper.New()
+	setMainDefaults(defaults)
+	setDefaults(defaults)
+
+	// The generated config must be in sync with the defaults of the code.
+	for _, key := range defaults.AllKeys() {
+		require.True(t, generated.IsSet(key), key)
+		switch defaults.Get(key).(type) {
+		case time.Duration:
+			require.Equal(t, defaults.GetDuration(key), generated.GetDuration(key), key)
+		default:
+			require.Equal(t, defaults.GetString(key), generated.GetString(key), key)
+		}
+	}
+	require.True(t, strings.HasPrefix(defaultConfig, "#"))
+}
+
+func TestGenKey(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keys", "ssh_host_key")
+	passFile := filepath.Join(dir, "pass")
+	require.NoError(t, os.WriteFile(passFile, []byte("secret\n"), 0o600))
+
+	var out bytes.Buffer
+	require.NoError(t, runGenKey([]string{"--out", path, "--passphrase-file", passFile}, &out))
+	require.Contains(t, out.String(), "ssh-ed25519 ")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	_, err = ssh.ParsePrivateKey(data)
+	require.Error(t, err)
+	signer, err := ssh.ParsePrivateKeyWithPassphrase(data, []byte("secret"))
+	require.NoError(t, err)
+	require.Equal(t, ssh.KeyAlgoED25519, signer.PublicKey().Type())
+
+	require.Error(t, runGenKey([]string{"--out", path}, &out), "existing key is kept")
+
+	t.Setenv("SFTP_GW_DEV_SSHKEY", path)
+	require.NoError(t, runGenKey([]string{"--type", "ecdsa", "--bits", "384", "--force"}, &out))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	signer, err = ssh.ParsePrivateKey(data)
+	require.NoError(t, err)
+	require.Equal(t, ssh.KeyAlgoECDSA384, signer.PublicKey().Type())
+
+	require.Error(t, runGenKey([]string{"--out", path, "--force", "--type", "rsa"}, &out))
+}
+
+func TestWalletNew(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "wallet.json")
+	passFile := filepath.Join(dir, "pass")
+	require.NoError(t, os.WriteFile(passFile, []byte("secret\n"), 0o600))
+
+	var out bytes.Buffer
+	require.NoError(t, runWallet([]string{"new", "--out", path, "--passphrase-file", passFile}, &out))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	pwd := "secret"
+	key, err := wallet.GetKeyFromPath(path, "", &pwd)
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "Owner: "+user.NewAutoIDSignerRFC6979(key.PrivateKey).UserID().EncodeToString())
+
+	require.Error(t, runWallet([]string{"new", "--out", path, "--passphrase-file", passFile}, &out), "existing wallet is kept")
+	require.Error(t, runWallet([]string{"create"}, &out))
+}
+
+func TestCheck(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	t.Setenv("SFTP_GW_WALLET_KEY", hex.EncodeToString(key.Bytes()))
+
+	var out bytes.Buffer
+	require.ErrorContains(t, runCheck(nil, &out), "no peers")
+	require.Contains(t, out.String(), "Owner: "+user.NewAutoIDSignerRFC6979(key.PrivateKey).UserID().EncodeToString())
+
+	out.Reset()
+	t.Setenv("SFTP_GW_PEERS_0_ADDRESS", "grpc://127.0.0.1:1")
+	t.Setenv("SFTP_GW_CONNECTION_CONNECT_TIMEOUT", "1s")
+	require.Error(t, runCheck([]string{"--timeout", "10s"}, &out))
+	require.Contains(t, out.String(), "grpc://127.0.0.1:1: FAIL")
+}
@@ -2,44 +2,137 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
 	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/nspcc-dev/neofs-sdk-go/waiter"
 	"github.com/nspcc-dev/neofs-sftp-gw/handlers"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/secrets"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/totp"
 	"github.com/nspcc-dev/neofs-sftp-gw/internal/version"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+type peerConfig struct {
+	Address  string
+	Priority int
+	Weight   float64
+}
+
+type devUserConfig struct {
+	Name     string
+	Password string
+	// PublicKeys are accepted keys in authorized_keys format.
+	PublicKeys []string
+}
+
+type listenerConfig struct {
+	Address string
+	// AuthMethods are authentication methods allowed on the listener, all if empty.
+	AuthMethods []string
+	// ProxyProtocol requires connections to start with PROXY protocol v1/v2 header.
+	ProxyProtocol bool
+}
+
+type hookConfig struct {
+	// Events are types of events the hook is run for, all if empty.
+	Events []string
+	// Webhook is the URL the event is posted to as JSON.
+	Webhook string
+	// Command is the program with arguments getting the event as JSON on stdin.
+	Command []string
+	Timeout time.Duration
+}
+
 type devConfig struct {
 	Enabled    bool
 	SSHKeyPath string
 	Passphrase string
 	Address    string
+	// TOTPSecrets maps user name to the TOTP secret required as the second factor.
+	TOTPSecrets        map[string][]byte
+	AuthorizedKeysPath string
+	RevocationListPath string
+	Users              []devUserConfig
+	Listeners          []listenerConfig
+	// MaxSessions and MaxSessionsPerIP limit simultaneous sessions, 0 means no limit.
+	MaxSessions      int
+	MaxSessionsPerIP int
+	// ShutdownTimeout limits waiting for active uploads on shutdown.
+	ShutdownTimeout time.Duration
+	Crypto          sshCryptoConfig
 }
 
 const (
 	defaultRebalanceTimer = 15 * time.Second
 	defaultRequestTimeout = 15 * time.Second
 	defaultConnectTimeout = 30 * time.Second
+	defaultStreamTimeout  = 10 * time.Second
+
+	defaultShutdownTimeout = 30 * time.Second
+	defaultHookTimeout     = 10 * time.Second
+
+	defaultWeightsInterval = time.Minute
+
+	defaultNNSZone = "container"
+
+	// defaultFileMode is shown for files and directories, objects have no permissions in NeoFS.
+	defaultFileMode = "0777"
+
+	startupInitialBackoff = time.Second
+	startupMaxBackoff     = 30 * time.Second
 )
 
 const (
 	// Wallet.
-	cfgWallet           = "wallet.path"
-	cfgAddress          = "wallet.address"
-	cfgWalletPassphrase = "wallet.passphrase"
+	cfgWalletSection  = "wallet"
+	cfgWallet         = "wallet.path"
+	cfgAddress        = "wallet.address"
+	cfgWalletPassFile = "wallet.passphrase_file"
+
+	// Labelled wallets referenced by users and mounts.
+	cfgWallets = "wallets"
 
 	// Timeouts.
 	cfgConnectTimeout = "connection.connect_timeout"
 	cfgRequestTimeout = "connection.request_timeout"
+	cfgStreamTimeout  = "connection.stream_timeout"
+	cfgPutTimeout     = "connection.put_timeout"
+	cfgGetTimeout     = "connection.get_timeout"
 	cfgRebalanceTimer = "connection.rebalance_timer"
+	cfgErrorThreshold = "connection.error_threshold"
+
+	// Health checks of peers, they override rebalance_timer and request_timeout if set.
+	cfgHealthcheckInterval = "connection.healthcheck.interval"
+	cfgHealthcheckTimeout  = "connection.healthcheck.timeout"
+
+	// Startup.
+	cfgStartupRetry   = "connection.startup.retry"
+	cfgStartupMaxWait = "connection.startup.max_wait"
+
+	// Node weights auto-tuning.
+	cfgWeightsAuto     = "connection.weights.auto"
+	cfgWeightsInterval = "connection.weights.interval"
 
 	// Peers.
 	cfgPeers = "peers"
@@ -48,11 +141,100 @@ const (
 	cfgUserEnabled = "user.enabled"
 	cfgUserPath    = "user.path"
 
+	// Per SSH user settings.
+	cfgUsers = "users"
+
 	// Dev variables.
-	cfgDevEnabled       = "dev.enabled"
-	cfgDevListenAddress = "dev.address"
-	cfgDevSSHKey        = "dev.sshkey"
-	cfgDevSSHPassphrase = "dev.passphrase"
+	cfgDevEnabled        = "dev.enabled"
+	cfgDevListenAddress  = "dev.address"
+	cfgDevSSHKey         = "dev.sshkey"
+	cfgDevSSHPassphrase  = "dev.passphrase"
+	cfgDevAuthorizedKeys = "dev.authorized_keys"
+	cfgDevRevokedKeys    = "dev.revoked_keys"
+	cfgDevUsers          = "dev.users"
+	cfgDevListeners      = "dev.listeners"
+	cfgDevMaxSessions    = "dev.max_sessions"
+	cfgDevMaxSessionsIP  = "dev.max_sessions_per_ip"
+
+	// SSH algorithms of the built-in server.
+	cfgDevKeyExchanges = "dev.crypto.key_exchanges"
+	cfgDevCiphers      = "dev.crypto.ciphers"
+	cfgDevMACs         = "dev.crypto.macs"
+	cfgDevMinRSASize   = "dev.crypto.min_rsa_key_size"
+
+	// Logger.
+	cfgLoggerLevel          = "logger.level"
+	cfgLoggerFilePath       = "logger.file.path"
+	cfgLoggerFileMaxSize    = "logger.file.max_size"
+	cfgLoggerFileMaxAge     = "logger.file.max_age"
+	cfgLoggerFileMaxBackups = "logger.file.max_backups"
+	cfgLoggerFileCompress   = "logger.file.compress"
+	cfgLoggerEncoding       = "logger.encoding"
+	cfgLoggerTimestamp      = "logger.timestamp"
+	cfgLoggerCaller         = "logger.caller"
+	cfgLoggerSlowThreshold  = "logger.slow_threshold"
+	cfgLoggerSampling       = "logger.sampling.enabled"
+	cfgLoggerSamplingFirst  = "logger.sampling.initial"
+	cfgLoggerSamplingNext   = "logger.sampling.thereafter"
+
+	// Bandwidth limits.
+	cfgLimitsSessionUpload   = "limits.session.upload_rate"
+	cfgLimitsSessionDownload = "limits.session.download_rate"
+	cfgLimitsGlobalUpload    = "limits.global.upload_rate"
+	cfgLimitsGlobalDownload  = "limits.global.download_rate"
+
+	// Admin API.
+	cfgAdminEnabled = "admin.enabled"
+	cfgAdminAddress = "admin.address"
+	cfgAdminToken   = "admin.token"
+
+	// Shutdown.
+	cfgShutdownTimeout = "shutdown_timeout"
+
+	// Patterns of read-only paths.
+	cfgReadOnlyPaths = "read_only_paths"
+
+	// Displayed file modes.
+	cfgFilesMode          = "files.mode"
+	cfgFilesDirectoryMode = "files.directory_mode"
+	cfgFilesUID           = "files.uid"
+	cfgFilesGID           = "files.gid"
+
+	// Configuration reloading.
+	cfgReloadWatch = "reload.watch"
+	cfgReloadDelay = "reload.delay"
+
+	// Container usage.
+	cfgUsageRefreshInterval = "usage.refresh_interval"
+
+	// Account balances.
+	cfgBalanceRefreshInterval = "balance.refresh_interval"
+	cfgBalanceForeignOwners   = "balance.foreign_owners"
+
+	// Cache shared by the gateway instances.
+	cfgCacheRedisAddress  = "cache.redis.address"
+	cfgCacheRedisPassword = "cache.redis.password"
+	cfgCacheRedisDB       = "cache.redis.db"
+
+	// Tracing.
+	cfgTracingEnabled  = "tracing.enabled"
+	cfgTracingEndpoint = "tracing.endpoint"
+	cfgTracingInsecure = "tracing.insecure"
+
+	// Error reporting.
+	cfgReportingEnabled = "reporting.enabled"
+	cfgReportingWebhook = "reporting.webhook"
+	cfgReportingLevel   = "reporting.level"
+	cfgReportingTimeout = "reporting.timeout"
+
+	// Event hooks.
+	cfgHooks = "hooks"
+
+	// Audit.
+	cfgAuditEnabled       = "audit.enabled"
+	cfgAuditContainer     = "audit.container"
+	cfgAuditBatchSize     = "audit.batch_size"
+	cfgAuditFlushInterval = "audit.flush_interval"
 
 	// Command line args.
 	cfgConfigPath = "config"
@@ -60,13 +242,66 @@ const (
 	// envPrefix is environment variables prefix used for configuration.
 	envPrefix = "SFTP_GW"
 
-	configType = "yaml"
+	// configType is the default format of configuration files, JSON and TOML ones
+	// are detected by the extension or set with cfgConfigType.
+	configType    = "yaml"
+	cfgConfigType = "config_type"
+
+	// Unknown configuration keys are errors instead of warnings.
+	cfgStrictKeys = "strict_keys"
+
+	cfgNeoFSContainerPolicy   = "neofs.container.policy"
+	cfgNeoFSContainerBasicACL = "neofs.container.basic_acl"
+	cfgNeoFSContainerEACL     = "neofs.container.eacl"
+	cfgNeoFSContainerRules    = "neofs.container.policy_rules"
+	cfgNeoFSContainerAttrs    = "neofs.container.attributes"
+	cfgNeoFSContainerNNS      = "neofs.container.nns.register"
+	cfgNeoFSContainerNNSZone  = "neofs.container.nns.zone"
+	cfgNeoFSContainerRmdir    = "neofs.container.rmdir"
+	cfgNeoFSContainerQuotas   = "neofs.container.quotas"
+	cfgNeoFSContainerInline   = "neofs.container.inline_policy"
+	cfgNeoFSContainerHashing  = "neofs.container.homomorphic_hashing"
+	cfgNeoFSContainerWaitPoll = "neofs.container.waiter.poll_interval"
+	cfgNeoFSContainerWaitTime = "neofs.container.waiter.timeout"
+	cfgNeoFSContainerAsync    = "neofs.container.waiter.async"
+	cfgNeoFSTombstoneLifetime = "neofs.tombstone.lifetime"
+	cfgNeoFSNNSEndpoint       = "neofs.nns.rpc_endpoint"
+	cfgNeoFSSessionLifetime   = "neofs.session.lifetime"
+
+	// Placement policy aliases.
+	cfgPolicyAliases = "policy_aliases"
+
+	// eACL templates.
+	cfgEACLTemplates = "eacl_templates"
+
+	// Mounts.
+	cfgMounts = "mounts"
+
+	// Uploads.
+	cfgUploadsExpiration  = "uploads.expiration"
+	cfgUploadsAttributes  = "uploads.attributes"
+	cfgUploadsDirectories = "uploads.directories"
+	cfgUploadsMirrors     = "uploads.mirrors"
+	cfgUploadsSweep       = "uploads.expiration_sweep"
+
+	// Sync jobs.
+	cfgSyncJobs       = "sync.jobs"
+	cfgSyncInterval   = "sync.interval"
+	cfgSyncDelay      = "sync.delay"
+	cfgSyncStandalone = "sync.standalone"
 
-	cfgNeoFSContainerPolicy = "neofs.container.policy"
+	// Export jobs.
+	cfgExportJobs       = "export.jobs"
+	cfgExportInterval   = "export.interval"
+	cfgExportStandalone = "export.standalone"
+
+	// Trash.
+	cfgTrashEnabled   = "trash.enabled"
+	cfgTrashRetention = "trash.retention"
 )
 
-func fetchPeers(l *zap.Logger, v *viper.Viper) []pool.NodeParam {
-	var peers []pool.NodeParam
+func fetchPeers(l *zap.Logger, v *viper.Viper) []peerConfig {
+	var peers []peerConfig
 
 	for i := 0; ; i++ {
 		key := cfgPeers + "." + strconv.Itoa(i) + "."
@@ -90,7 +325,7 @@ func fetchPeers(l *zap.Logger, v *viper.Viper) []pool.NodeParam {
 				zap.String("address", address))
 			priority = 1
 		}
-		peers = append(peers, pool.NewNodeParam(priority, address, weight))
+		peers = append(peers, peerConfig{Address: address, Priority: priority, Weight: weight})
 
 		l.Info("added connection peer",
 			zap.String("address", address),
@@ -100,7 +335,803 @@ func fetchPeers(l *zap.Logger, v *viper.Viper) []pool.NodeParam {
 	return peers
 }
 
-func newSettings() (*viper.Viper, *handlers.SftpServerConfig, devConfig) {
+func nodeParams(peers []peerConfig) []pool.NodeParam {
+	params := make([]pool.NodeParam, 0, len(peers))
+	for _, peer := range peers {
+		params = append(params, pool.NewNodeParam(peer.Priority, peer.Address, peer.Weight))
+	}
+	return params
+}
+
+// fillServerConfig sets server params from the main and the user configuration.
+func fillServerConfig(l *zap.Logger, v, userV *viper.Viper, wallets map[string]user.Signer, cfg *handlers.SftpServerConfig) {
+	cfg.SessionUploadRate = int64(v.GetSizeInBytes(cfgLimitsSessionUpload))
+	cfg.SessionDownloadRate = int64(v.GetSizeInBytes(cfgLimitsSessionDownload))
+	cfg.GlobalUploadRate = int64(v.GetSizeInBytes(cfgLimitsGlobalUpload))
+	cfg.GlobalDownloadRate = int64(v.GetSizeInBytes(cfgLimitsGlobalDownload))
+	cfg.ReadOnlyPaths = fetchReadOnlyPaths(l, v)
+	cfg.FileMode = fetchFileMode(l, v, cfgFilesMode)
+	cfg.DirectoryMode = fetchFileMode(l, v, cfgFilesDirectoryMode)
+	cfg.FileOwner, cfg.UserFileOwners = fetchFileOwners(v, userV)
+	cfg.UserSigners = fetchUserSigners(l, userV, wallets)
+	cfg.ForeignOwners = fetchForeignOwners(l, userV)
+	cfg.BasicACL, cfg.UserBasicACL = fetchBasicACL(l, userV)
+	cfg.EACL, cfg.UserEACL = fetchEACL(l, userV)
+	cfg.PolicyAliases = fetchPolicyAliases(l, userV)
+	cfg.PolicyRules = fetchPolicyRules(l, userV, cfg.PolicyAliases)
+	cfg.QuotaRules = fetchQuotaRules(l, userV)
+	cfg.InlinePolicy = userV.GetBool(cfgNeoFSContainerInline)
+	cfg.ForeignBalances = userV.GetBool(cfgBalanceForeignOwners)
+	cfg.PutTimeout = userV.GetDuration(cfgPutTimeout)
+	cfg.GetTimeout = userV.GetDuration(cfgGetTimeout)
+	cfg.SlowThreshold = v.GetDuration(cfgLoggerSlowThreshold)
+	cfg.Peers = nil
+	for _, peer := range fetchPeers(zap.NewNop(), userV) {
+		cfg.Peers = append(cfg.Peers, peer.Address)
+	}
+	cfg.ContainerAttributes = fetchAttributes(l, userV, cfgNeoFSContainerAttrs)
+	cfg.NNSZone = ""
+	if userV.GetBool(cfgNeoFSContainerNNS) {
+		cfg.NNSZone = userV.GetString(cfgNeoFSContainerNNSZone)
+	}
+	cfg.Mounts = fetchMounts(l, userV, wallets)
+	cfg.RmdirMode = fetchRmdirMode(l, userV)
+	if cfg.HomomorphicHashing = fetchHomomorphicHashing(l, userV.GetString(cfgNeoFSContainerHashing)); cfg.HomomorphicHashing == "" {
+		cfg.HomomorphicHashing = handlers.HomomorphicHashingAuto
+	}
+	cfg.ContainerWaiter = handlers.ContainerWaiter{
+		PollInterval: userV.GetDuration(cfgNeoFSContainerWaitPoll),
+		Timeout:      userV.GetDuration(cfgNeoFSContainerWaitTime),
+		Async:        userV.GetBool(cfgNeoFSContainerAsync),
+	}
+	cfg.TombstoneLifetime = userV.GetUint64(cfgNeoFSTombstoneLifetime)
+	cfg.Trash = handlers.Trash{
+		Enabled:   v.GetBool(cfgTrashEnabled),
+		Retention: v.GetDuration(cfgTrashRetention),
+	}
+	cfg.ExpirationRules = fetchExpirationRules(l, v)
+	cfg.AttributeRules = fetchAttributeRules(l, v)
+	cfg.DirectoryDefaults = fetchDirectoryDefaults(l, v)
+	cfg.MirrorRules = fetchMirrorRules(l, v)
+	cfg.UserContainerAttributes = make(map[string][]handlers.Attribute)
+	for name := range userV.GetStringMap(cfgUsers) {
+		if attrs := fetchAttributes(l, userV, cfgUsers+"."+name+".container_attributes"); len(attrs) > 0 {
+			cfg.UserContainerAttributes[name] = attrs
+		}
+	}
+}
+
+// fetchRmdirMode returns the mode of container removal, the default one is used if it's invalid.
+func fetchRmdirMode(l *zap.Logger, v *viper.Viper) handlers.RmdirMode {
+	switch mode := handlers.RmdirMode(v.GetString(cfgNeoFSContainerRmdir)); mode {
+	case handlers.RmdirDelete, handlers.RmdirPurge, handlers.RmdirStrict:
+		return mode
+	default:
+		l.Warn("invalid rmdir mode, using the default one",
+			zap.String("mode", string(mode)),
+			zap.String("default", string(handlers.RmdirDelete)))
+		return handlers.RmdirDelete
+	}
+}
+
+// fetchHomomorphicHashing returns the homomorphic hashing setting, empty if it's not set or invalid.
+func fetchHomomorphicHashing(l *zap.Logger, s string) handlers.HomomorphicHashing {
+	switch setting := handlers.HomomorphicHashing(s); setting {
+	case "", handlers.HomomorphicHashingAuto, handlers.HomomorphicHashingEnabled, handlers.HomomorphicHashingDisabled:
+		return setting
+	default:
+		l.Warn("invalid homomorphic hashing setting, ignored", zap.String("setting", s))
+		return ""
+	}
+}
+
+// fetchExpirationRules returns lifetime rules of uploaded objects, invalid ones are skipped.
+func fetchExpirationRules(l *zap.Logger, v *viper.Viper) []handlers.ExpirationRule {
+	var rules []handlers.ExpirationRule
+
+	for i := 0; ; i++ {
+		key := cfgUploadsExpiration + "." + strconv.Itoa(i) + "."
+		pattern := v.GetString(key + "pattern")
+		if pattern == "" {
+			break
+		}
+
+		rule := handlers.ExpirationRule{
+			Pattern:  pattern,
+			Lifetime: v.GetUint64(key + "lifetime"),
+			TTL:      v.GetDuration(key + "ttl"),
+		}
+		if err := handlers.CheckPathPattern(pattern); err != nil {
+			l.Warn("skip, invalid expiration rule pattern", zap.String("pattern", pattern), zap.Error(err))
+			continue
+		}
+		if (rule.Lifetime > 0) == (rule.TTL > 0) {
+			l.Warn("skip, expiration rule must have either lifetime or ttl", zap.String("pattern", pattern))
+			continue
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+// fetchAttributeRules returns rules adding attributes to uploaded objects, invalid ones are skipped.
+func fetchAttributeRules(l *zap.Logger, v *viper.Viper) []handlers.AttributeRule {
+	var rules []handlers.AttributeRule
+
+	for i := 0; ; i++ {
+		key := cfgUploadsAttributes + "." + strconv.Itoa(i) + "."
+		pattern := v.GetString(key + "pattern")
+		if pattern == "" {
+			break
+		}
+
+		if err := handlers.CheckPathPattern(pattern); err != nil {
+			l.Warn("skip, invalid attribute rule pattern", zap.String("pattern", pattern), zap.Error(err))
+			continue
+		}
+		rule := handlers.AttributeRule{
+			Pattern:    pattern,
+			Attributes: fetchObjectAttributes(l, v, key+"attributes"),
+		}
+		if len(rule.Attributes) == 0 {
+			l.Warn("skip, attribute rule has no attributes", zap.String("pattern", pattern))
+			continue
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+// fetchReadOnlyPaths returns the patterns of read-only paths, invalid ones are skipped.
+func fetchReadOnlyPaths(l *zap.Logger, v *viper.Viper) []string {
+	var patterns []string
+	for _, pattern := range v.GetStringSlice(cfgReadOnlyPaths) {
+		if err := handlers.CheckPathPattern(pattern); err != nil {
+			l.Warn("skip, invalid read-only path pattern", zap.String("pattern", pattern), zap.Error(err))
+			continue
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns
+}
+
+// fetchFileMode returns the octal permissions under the key, invalid ones are replaced with 0777.
+func fetchFileMode(l *zap.Logger, v *viper.Viper, key string) fs.FileMode {
+	s := v.GetString(key)
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > uint64(fs.ModePerm) {
+		l.Warn("invalid file mode, "+defaultFileMode+" is used", zap.String("key", key), zap.String("mode", s))
+		return fs.ModePerm
+	}
+	return fs.FileMode(mode)
+}
+
+// fetchFileOwners returns the owner of files shown to clients and the ones of SSH users, users
+// with only uid or gid set get the other one of the global owner.
+func fetchFileOwners(v, userV *viper.Viper) (handlers.FileOwner, map[string]handlers.FileOwner) {
+	owner := handlers.FileOwner{
+		UID: v.GetUint32(cfgFilesUID),
+		GID: v.GetUint32(cfgFilesGID),
+	}
+
+	users := make(map[string]handlers.FileOwner)
+	for name := range userV.GetStringMap(cfgUsers) {
+		key := cfgUsers + "." + name + "."
+		if !userV.IsSet(key+"uid") && !userV.IsSet(key+"gid") {
+			continue
+		}
+		userOwner := owner
+		if userV.IsSet(key + "uid") {
+			userOwner.UID = userV.GetUint32(key + "uid")
+		}
+		if userV.IsSet(key + "gid") {
+			userOwner.GID = userV.GetUint32(key + "gid")
+		}
+		users[name] = userOwner
+	}
+
+	return owner, users
+}
+
+// fetchDirectoryDefaults returns the default settings of uploads to directories, invalid ones are skipped.
+func fetchDirectoryDefaults(l *zap.Logger, v *viper.Viper) []handlers.DirectoryDefaults {
+	var defaults []handlers.DirectoryDefaults
+
+	for i := 0; ; i++ {
+		key := cfgUploadsDirectories + "." + strconv.Itoa(i) + "."
+		pattern := v.GetString(key + "pattern")
+		if pattern == "" {
+			break
+		}
+
+		if err := handlers.CheckPathPattern(pattern); err != nil {
+			l.Warn("skip, invalid directory defaults pattern", zap.String("pattern", pattern), zap.Error(err))
+			continue
+		}
+		dir := handlers.DirectoryDefaults{
+			Pattern:     pattern,
+			Attributes:  fetchObjectAttributes(l, v, key+"attributes"),
+			Lifetime:    v.GetUint64(key + "lifetime"),
+			TTL:         v.GetDuration(key + "ttl"),
+			ContentType: handlers.ContentTypePolicy(v.GetString(key + "content_type")),
+		}
+		if dir.Lifetime > 0 && dir.TTL > 0 {
+			l.Warn("skip, directory defaults must have either lifetime or ttl", zap.String("pattern", pattern))
+			continue
+		}
+		if err := handlers.CheckContentTypePolicy(dir.ContentType); err != nil {
+			l.Warn("skip, invalid directory defaults", zap.String("pattern", pattern), zap.Error(err))
+			continue
+		}
+		defaults = append(defaults, dir)
+	}
+
+	return defaults
+}
+
+// fetchMirrorRules returns rules copying uploads into other containers, invalid ones are skipped.
+func fetchMirrorRules(l *zap.Logger, v *viper.Viper) []handlers.MirrorRule {
+	var rules []handlers.MirrorRule
+
+	for i := 0; ; i++ {
+		key := cfgUploadsMirrors + "." + strconv.Itoa(i) + "."
+		pattern := v.GetString(key + "pattern")
+		if pattern == "" {
+			break
+		}
+
+		if _, err := path.Match(pattern, ""); err != nil {
+			l.Warn("skip, invalid mirror rule pattern", zap.String("pattern", pattern), zap.Error(err))
+			continue
+		}
+		target := v.GetString(key + "target")
+		if target == "" {
+			l.Warn("skip, mirror rule must have target container", zap.String("pattern", pattern))
+			continue
+		}
+		rules = append(rules, handlers.MirrorRule{Pattern: pattern, Target: target})
+	}
+
+	return rules
+}
+
+// fetchMounts returns containers mounted in the root directory, invalid mounts are skipped.
+func fetchMounts(l *zap.Logger, v *viper.Viper, wallets map[string]user.Signer) []handlers.Mount {
+	var mounts []handlers.Mount
+
+	for i := 0; ; i++ {
+		key := cfgMounts + "." + strconv.Itoa(i) + "."
+		name := v.GetString(key + "name")
+		if name == "" {
+			break
+		}
+
+		mount := handlers.Mount{Name: name}
+		if strings.Contains(name, "/") {
+			l.Warn("skip, mount name contains slash", zap.String("mount", name))
+			continue
+		}
+		if err := mount.Container.DecodeString(v.GetString(key + "container")); err != nil {
+			l.Warn("skip, invalid mount container", zap.String("mount", name), zap.Error(err))
+			continue
+		}
+		if path := v.GetString(key + "bearer_token"); path != "" {
+			token, err := readBearerToken(path)
+			if err != nil {
+				l.Warn("skip, invalid mount bearer token", zap.String("mount", name), zap.Error(err))
+				continue
+			}
+			mount.BearerToken = token
+		}
+		if label := v.GetString(key + "wallet"); label != "" {
+			signer, ok := wallets[strings.ToLower(label)]
+			if !ok {
+				l.Warn("skip, unknown mount wallet", zap.String("mount", name), zap.String("wallet", label))
+				continue
+			}
+			mount.Signer = signer
+		}
+
+		mounts = append(mounts, mount)
+	}
+
+	return mounts
+}
+
+// fetchUserSigners returns the signers of SSH users referencing labelled wallets,
+// users with unknown wallets are skipped.
+func fetchUserSigners(l *zap.Logger, v *viper.Viper, wallets map[string]user.Signer) map[string]user.Signer {
+	signers := make(map[string]user.Signer)
+	for name := range v.GetStringMap(cfgUsers) {
+		label := v.GetString(cfgUsers + "." + name + ".wallet")
+		if label == "" {
+			continue
+		}
+		signer, ok := wallets[strings.ToLower(label)]
+		if !ok {
+			l.Warn("skip, unknown user wallet", zap.String("user", name), zap.String("wallet", label))
+			continue
+		}
+		signers[name] = signer
+	}
+	return signers
+}
+
+// readBearerToken reads binary or JSON encoded bearer token from the file. The path may refer
+// to the secret store keeping JSON or base64 encoded binary token.
+func readBearerToken(path string) (*bearer.Token, error) {
+	if secrets.IsReference(path) {
+		return readSecretBearerToken(path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var token bearer.Token
+	if err = token.Unmarshal(data); err != nil {
+		if errJSON := token.UnmarshalJSON(data); errJSON != nil {
+			return nil, fmt.Errorf("decode token: %w", err)
+		}
+	}
+	return &token, nil
+}
+
+func readSecretBearerToken(ref string) (*bearer.Token, error) {
+	s, err := secrets.Resolve(context.Background(), ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var token bearer.Token
+	if err = token.UnmarshalJSON([]byte(s)); err == nil {
+		return &token, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, errors.New("decode token: neither JSON nor base64")
+	}
+	if err = token.Unmarshal(data); err != nil {
+		return nil, fmt.Errorf("decode token: %w", err)
+	}
+	return &token, nil
+}
+
+// fetchAttributes reads the indexed list of attributes under the key. The list is used
+// instead of a map since viper makes map keys lower-case.
+func fetchAttributes(l *zap.Logger, v *viper.Viper, key string) []handlers.Attribute {
+	var attrs []handlers.Attribute
+
+	for i := 0; ; i++ {
+		attrKey := key + "." + strconv.Itoa(i) + "."
+		attr := handlers.Attribute{
+			Key:   v.GetString(attrKey + "key"),
+			Value: v.GetString(attrKey + "value"),
+		}
+		if attr.Key == "" {
+			break
+		}
+		if attr.Value == "" {
+			l.Warn("skip, empty attribute value", zap.String("section", key), zap.String("attribute", attr.Key))
+			continue
+		}
+		attrs = append(attrs, attr)
+	}
+
+	return attrs
+}
+
+// fetchObjectAttributes returns the attributes of uploaded objects under the key, the ones set by
+// the gateway itself (e.g. FileName) are skipped.
+func fetchObjectAttributes(l *zap.Logger, v *viper.Viper, key string) []handlers.Attribute {
+	var attrs []handlers.Attribute
+	for _, attr := range fetchAttributes(l, v, key) {
+		if handlers.IsReservedAttribute(attr.Key) {
+			l.Warn("skip, reserved attribute", zap.String("section", key), zap.String("attribute", attr.Key))
+			continue
+		}
+		attrs = append(attrs, attr)
+	}
+	return attrs
+}
+
+// validateContainerSettings checks the settings of new containers: placement policies,
+// their aliases, basic ACLs and eACL templates. On reload invalid values are skipped
+// with warnings, but the gateway must not start with them since mkdir fails then.
+func validateContainerSettings(v *viper.Viper) error {
+	aliases := v.GetStringMapString(cfgPolicyAliases)
+	for name, value := range aliases {
+		if _, err := handlers.DecodePolicy(value); err != nil {
+			return fmt.Errorf("policy alias %s: %w", name, err)
+		}
+	}
+	checkPolicy := func(s string) error {
+		if alias, ok := aliases[strings.ToLower(s)]; ok {
+			s = alias
+		}
+		_, err := handlers.DecodePolicy(s)
+		return err
+	}
+
+	if s := v.GetString(cfgNeoFSContainerPolicy); s != "" {
+		if err := checkPolicy(s); err != nil {
+			return fmt.Errorf("%s: %w", cfgNeoFSContainerPolicy, err)
+		}
+	}
+	for i := 0; ; i++ {
+		key := cfgNeoFSContainerRules + "." + strconv.Itoa(i) + "."
+		if v.GetString(key+"pattern") == "" {
+			break
+		}
+		if err := checkPolicy(v.GetString(key + "policy")); err != nil {
+			return fmt.Errorf("%spolicy: %w", key, err)
+		}
+	}
+
+	aclKeys := []string{cfgNeoFSContainerBasicACL}
+	eaclKeys := []string{cfgNeoFSContainerEACL}
+	for name := range v.GetStringMap(cfgUsers) {
+		aclKeys = append(aclKeys, cfgUsers+"."+name+".basic_acl")
+		eaclKeys = append(eaclKeys, cfgUsers+"."+name+".eacl")
+	}
+	for _, key := range aclKeys {
+		if s := v.GetString(key); s != "" {
+			var basicACL acl.Basic
+			if err := basicACL.DecodeString(s); err != nil {
+				return fmt.Errorf("%s: invalid basic ACL %q: %w", key, s, err)
+			}
+		}
+	}
+
+	for name := range v.GetStringMap(cfgEACLTemplates) {
+		if _, err := parseEACLTemplate(v, cfgEACLTemplates+"."+name); err != nil {
+			return fmt.Errorf("eACL template %s: %w", name, err)
+		}
+	}
+	for _, key := range eaclKeys {
+		if name := v.GetString(key); name != "" && !v.IsSet(cfgEACLTemplates+"."+strings.ToLower(name)) {
+			return fmt.Errorf("%s: unknown eACL template %q", key, name)
+		}
+	}
+
+	return nil
+}
+
+// fetchPolicyAliases returns names of placement policies, invalid ones are skipped.
+func fetchPolicyAliases(l *zap.Logger, v *viper.Viper) map[string]string {
+	aliases := make(map[string]string)
+
+	for name, value := range v.GetStringMapString(cfgPolicyAliases) {
+		if _, err := handlers.DecodePolicy(value); err != nil {
+			l.Warn("skip, invalid placement policy of the alias",
+				zap.String("alias", name),
+				zap.String("policy", value),
+				zap.Error(err))
+			continue
+		}
+		aliases[name] = value
+	}
+
+	return aliases
+}
+
+// fetchPolicyRules returns placement policy rules of new containers, invalid ones are skipped.
+// Rules may refer to the policy aliases.
+func fetchPolicyRules(l *zap.Logger, v *viper.Viper, aliases map[string]string) []handlers.PolicyRule {
+	var rules []handlers.PolicyRule
+
+	for i := 0; ; i++ {
+		key := cfgNeoFSContainerRules + "." + strconv.Itoa(i) + "."
+		pattern := v.GetString(key + "pattern")
+		if pattern == "" {
+			break
+		}
+
+		rule := handlers.PolicyRule{
+			Pattern:            pattern,
+			Policy:             v.GetString(key + "policy"),
+			HomomorphicHashing: fetchHomomorphicHashing(l, v.GetString(key+"homomorphic_hashing")),
+		}
+		if _, err := path.Match(rule.Pattern, ""); err != nil {
+			l.Warn("skip, invalid policy rule pattern", zap.String("pattern", pattern), zap.Error(err))
+			continue
+		}
+		policyStr, ok := aliases[strings.ToLower(rule.Policy)]
+		if !ok {
+			policyStr = rule.Policy
+		}
+		if _, err := handlers.DecodePolicy(policyStr); err != nil {
+			l.Warn("skip, invalid policy rule placement policy",
+				zap.String("pattern", pattern),
+				zap.String("policy", rule.Policy),
+				zap.Error(err))
+			continue
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+// fetchQuotaRules returns size limits of containers, invalid rules are skipped.
+func fetchQuotaRules(l *zap.Logger, v *viper.Viper) []handlers.QuotaRule {
+	var rules []handlers.QuotaRule
+
+	for i := 0; ; i++ {
+		key := cfgNeoFSContainerQuotas + "." + strconv.Itoa(i) + "."
+		pattern := v.GetString(key + "pattern")
+		if pattern == "" {
+			break
+		}
+
+		if _, err := path.Match(pattern, ""); err != nil {
+			l.Warn("skip, invalid quota rule pattern", zap.String("pattern", pattern), zap.Error(err))
+			continue
+		}
+		size := v.GetSizeInBytes(key + "size")
+		if size == 0 {
+			l.Warn("skip, quota rule must have size", zap.String("pattern", pattern))
+			continue
+		}
+		rules = append(rules, handlers.QuotaRule{Pattern: pattern, Size: uint64(size)})
+	}
+
+	return rules
+}
+
+// fetchBasicACL returns the basic ACL of new containers and its overrides for SSH users.
+// Invalid values are skipped, containers are private by default.
+func fetchBasicACL(l *zap.Logger, v *viper.Viper) (acl.Basic, map[string]acl.Basic) {
+	basicACL := acl.Private
+	if s := v.GetString(cfgNeoFSContainerBasicACL); s != "" {
+		if err := basicACL.DecodeString(s); err != nil {
+			l.Warn("invalid basic ACL, containers are private", zap.String("basic_acl", s), zap.Error(err))
+			basicACL = acl.Private
+		}
+	}
+
+	users := make(map[string]acl.Basic)
+	for name := range v.GetStringMap(cfgUsers) {
+		s := v.GetString(cfgUsers + "." + name + ".basic_acl")
+		if s == "" {
+			continue
+		}
+
+		var userACL acl.Basic
+		if err := userACL.DecodeString(s); err != nil {
+			l.Warn("skip, invalid basic ACL",
+				zap.String("user", name),
+				zap.String("basic_acl", s),
+				zap.Error(err))
+			continue
+		}
+		users[name] = userACL
+	}
+
+	return basicACL, users
+}
+
+func fetchForeignOwners(l *zap.Logger, v *viper.Viper) map[string][]user.ID {
+	owners := make(map[string][]user.ID)
+
+	for name := range v.GetStringMap(cfgUsers) {
+		for _, addr := range v.GetStringSlice(cfgUsers + "." + name + ".owners") {
+			var id user.ID
+			if err := id.DecodeString(addr); err != nil {
+				l.Warn("skip, invalid owner id",
+					zap.String("user", name),
+					zap.String("owner", addr),
+					zap.Error(err))
+				continue
+			}
+			owners[name] = append(owners[name], id)
+
+			l.Info("added foreign owner",
+				zap.String("user", name),
+				zap.Stringer("owner", id))
+		}
+	}
+
+	return owners
+}
+
+// fetchEACL returns the eACL template of new containers and its overrides for SSH users.
+// Invalid templates are skipped.
+func fetchEACL(l *zap.Logger, v *viper.Viper) (*eacl.Table, map[string]*eacl.Table) {
+	templates := make(map[string]*eacl.Table)
+	for name := range v.GetStringMap(cfgEACLTemplates) {
+		table, err := parseEACLTemplate(v, cfgEACLTemplates+"."+name)
+		if err != nil {
+			l.Warn("skip, invalid eACL template", zap.String("template", name), zap.Error(err))
+			continue
+		}
+		templates[name] = table
+	}
+
+	// Viper keys are case-insensitive, so are the template names.
+	lookup := func(key string) (*eacl.Table, bool) {
+		name := strings.ToLower(v.GetString(key))
+		if name == "" {
+			return nil, false
+		}
+		table, ok := templates[name]
+		if !ok {
+			l.Warn("skip, unknown eACL template", zap.String("key", key), zap.String("template", name))
+		}
+		return table, ok
+	}
+
+	table, _ := lookup(cfgNeoFSContainerEACL)
+
+	users := make(map[string]*eacl.Table)
+	for name := range v.GetStringMap(cfgUsers) {
+		if userTable, ok := lookup(cfgUsers + "." + name + ".eacl"); ok {
+			users[name] = userTable
+		}
+	}
+
+	return table, users
+}
+
+var (
+	eaclActions = map[string]eacl.Action{
+		"allow": eacl.ActionAllow,
+		"deny":  eacl.ActionDeny,
+	}
+	eaclOperations = map[string]eacl.Operation{
+		"get":       eacl.OperationGet,
+		"head":      eacl.OperationHead,
+		"put":       eacl.OperationPut,
+		"delete":    eacl.OperationDelete,
+		"search":    eacl.OperationSearch,
+		"range":     eacl.OperationRange,
+		"rangehash": eacl.OperationRangeHash,
+	}
+	eaclRoles = map[string]eacl.Role{
+		"user":   eacl.RoleUser,
+		"system": eacl.RoleSystem,
+		"others": eacl.RoleOthers,
+	}
+)
+
+// parseEACLTemplate reads the indexed list of eACL records under the key. Every record
+// is added for each of its operations.
+func parseEACLTemplate(v *viper.Viper, key string) (*eacl.Table, error) {
+	table := eacl.NewTable()
+
+	for i := 0; ; i++ {
+		recordKey := key + "." + strconv.Itoa(i) + "."
+		actionStr := v.GetString(recordKey + "action")
+		if actionStr == "" {
+			break
+		}
+
+		action, ok := eaclActions[strings.ToLower(actionStr)]
+		if !ok {
+			return nil, fmt.Errorf("record %d: unknown action %q", i, actionStr)
+		}
+
+		target := eacl.NewTarget()
+		roleStr := v.GetString(recordKey + "role")
+		pubs := v.GetStringSlice(recordKey + "keys")
+		switch {
+		case roleStr != "" && len(pubs) > 0:
+			return nil, fmt.Errorf("record %d: either role or keys must be set", i)
+		case roleStr != "":
+			role, ok := eaclRoles[strings.ToLower(roleStr)]
+			if !ok {
+				return nil, fmt.Errorf("record %d: unknown role %q", i, roleStr)
+			}
+			target.SetRole(role)
+		case len(pubs) > 0:
+			binKeys := make([][]byte, 0, len(pubs))
+			for _, pub := range pubs {
+				key, err := keys.NewPublicKeyFromString(pub)
+				if err != nil {
+					return nil, fmt.Errorf("record %d: invalid key %q: %w", i, pub, err)
+				}
+				binKeys = append(binKeys, key.Bytes())
+			}
+			target.SetBinaryKeys(binKeys)
+		default:
+			return nil, fmt.Errorf("record %d: role or keys must be set", i)
+		}
+
+		operations := v.GetStringSlice(recordKey + "operations")
+		if len(operations) == 0 {
+			return nil, fmt.Errorf("record %d: no operations", i)
+		}
+		for _, opStr := range operations {
+			op, ok := eaclOperations[strings.ToLower(opStr)]
+			if !ok {
+				return nil, fmt.Errorf("record %d: unknown operation %q", i, opStr)
+			}
+			record := eacl.CreateRecord(action, op)
+			eacl.AddRecordTarget(record, target)
+			table.AddRecord(record)
+		}
+	}
+
+	if len(table.Records()) == 0 {
+		return nil, errors.New("no records")
+	}
+	return table, nil
+}
+
+func fetchDevUsers(v *viper.Viper) []devUserConfig {
+	var users []devUserConfig
+
+	for i := 0; ; i++ {
+		key := cfgDevUsers + "." + strconv.Itoa(i) + "."
+		name := v.GetString(key + "name")
+		if name == "" {
+			break
+		}
+
+		users = append(users, devUserConfig{
+			Name:       name,
+			Password:   v.GetString(key + "password"),
+			PublicKeys: v.GetStringSlice(key + "public_keys"),
+		})
+	}
+
+	return users
+}
+
+func fetchListeners(v *viper.Viper) ([]listenerConfig, error) {
+	var listeners []listenerConfig
+
+	for i := 0; ; i++ {
+		key := cfgDevListeners + "." + strconv.Itoa(i) + "."
+		address := v.GetString(key + "address")
+		if address == "" {
+			break
+		}
+
+		methods := v.GetStringSlice(key + "auth")
+		for _, method := range methods {
+			if !isAuthMethod(method) {
+				return nil, fmt.Errorf("unknown auth method %q of listener %s", method, address)
+			}
+		}
+
+		listeners = append(listeners, listenerConfig{
+			Address:       address,
+			AuthMethods:   methods,
+			ProxyProtocol: v.GetBool(key + "proxy_protocol"),
+		})
+	}
+
+	if len(listeners) == 0 {
+		listeners = append(listeners, listenerConfig{Address: v.GetString(cfgDevListenAddress)})
+	}
+
+	return listeners, nil
+}
+
+func fetchTOTPSecrets(v *viper.Viper) (map[string][]byte, error) {
+	secrets := make(map[string][]byte)
+
+	for name := range v.GetStringMap(cfgUsers) {
+		encoded := v.GetString(cfgUsers + "." + name + ".totp_secret")
+		if encoded == "" {
+			continue
+		}
+		secret, err := totp.DecodeSecret(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid totp secret of user %q: %w", name, err)
+		}
+		secrets[name] = secret
+	}
+
+	return secrets, nil
+}
+
+// newViper returns the settings reading SFTP_GW_* environment variables, e.g. SFTP_GW_WALLET_PATH
+// for wallet.path or SFTP_GW_PEERS_0_ADDRESS for the address of the first peer.
+func newViper() *viper.Viper {
 	v := viper.New()
 
 	v.AutomaticEnv()
@@ -108,11 +1139,28 @@ func newSettings() (*viper.Viper, *handlers.SftpServerConfig, devConfig) {
 	v.SetConfigType(configType)
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AllowEmptyEnv(true)
+	return v
+}
+
+// newSettings reads the configuration from the command line arguments, the configuration files
+// and the environment. pflag.ErrHelp is returned if the usage is requested.
+// cmdlineSettings are the configuration keys set by the command line flags and their values, they
+// override the settings of both the main and the user configuration.
+type cmdlineSettings map[string]any
+
+func newSettings(args []string) (*viper.Viper, cmdlineSettings, *handlers.SftpServerConfig, error) {
+	v := newViper()
 
 	// flags setup:
-	flags := pflag.NewFlagSet("commandline", pflag.ExitOnError)
+	flags := pflag.NewFlagSet("commandline", pflag.ContinueOnError)
 	flags.SetOutput(os.Stderr)
 	flags.SortFlags = false
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [command] [flags]\n\nCommands:\n", os.Args[0])
+		printCommands(os.Stderr)
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
 
 	sftpConfig := &handlers.SftpServerConfig{}
 	flags.BoolVarP(&sftpConfig.ReadOnly, "read-only", "R", false, "read-only server")
@@ -120,23 +1168,43 @@ func newSettings() (*viper.Viper, *handlers.SftpServerConfig, devConfig) {
 	flags.StringVarP(&sftpConfig.DebugLevel, "debug-level", "l", "ERROR", "debug level")
 	versionFlag := flags.BoolP("version", "v", false, "show version")
 
-	config := flags.String(cfgConfigPath, "", "config path")
-
-	// dev section
-	v.SetDefault(cfgDevListenAddress, "0.0.0.0:2022")
-	v.SetDefault(cfgDevEnabled, false)
+	flags.StringArray(cfgConfigPath, nil, "config file or directory, repeat for several ones merged in order")
+	flags.String(cfgConfigType, "", "config format: yaml, json or toml, detected by the file extension if not set")
+	flags.Bool(cfgStrictKeys, false, "fail on unknown configuration keys instead of warning")
 
-	// user section
-	v.SetDefault(cfgUserEnabled, false)
+	// Flags named after the configuration keys override the values of the config file.
+	flags.String(cfgWallet, "", "path to the wallet")
+	flags.String(cfgAddress, "", "account address of the wallet")
+	flags.String(cfgWalletPassFile, "", "path to the file with the wallet passphrase")
+	peers := flags.StringArray("peer", nil, "address of the NeoFS node, repeat for several peers (replaces configured peers)")
+	flags.Duration(cfgConnectTimeout, 0, "timeout of connecting to peers")
+	flags.Duration(cfgRequestTimeout, 0, "timeout of NeoFS requests")
+	flags.Duration(cfgStreamTimeout, 0, "timeout of every message of NeoFS streams")
+	flags.Duration(cfgPutTimeout, 0, "timeout of storing an uploaded file, 0 means no limit")
+	flags.Duration(cfgGetTimeout, 0, "timeout of every read of a downloaded file, 0 means no limit")
+	flags.Duration(cfgRebalanceTimer, 0, "interval of peers health checks")
+	flags.String(cfgLoggerLevel, "", "logger level")
+	flags.Duration(cfgLoggerSlowThreshold, 0, "duration of SFTP requests and NeoFS calls logged as slow, 0 disables it")
+	flags.Bool(cfgDevEnabled, false, "serve SSH connections by the built-in server")
+	flags.String(cfgDevListenAddress, "", "address of the built-in SSH server")
+	flags.String(cfgDevSSHKey, "", "path to the host key of the built-in SSH server")
+	flags.Bool(cfgAdminEnabled, false, "enable the admin API")
+	flags.String(cfgAdminAddress, "", "address of the admin API")
+	flags.Duration(cfgShutdownTimeout, 0, "time to wait for active uploads on shutdown")
+	flags.String(cfgNeoFSContainerPolicy, "", "placement policy of created containers")
+	sets := flags.StringArray("set", nil, "set any configuration key, e.g. --set neofs.container.basic_acl=public-read")
 
-	// main section
+	setMainDefaults(v)
 	setDefaults(v)
 
 	if err := v.BindPFlags(flags); err != nil {
-		panic(err)
+		return nil, nil, nil, newStartupError(exitFailure, "bind flags", err)
 	}
-	if err := flags.Parse(os.Args); err != nil {
-		panic(err)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return nil, nil, nil, err
+		}
+		return nil, nil, nil, newStartupError(exitUsage, "invalid command line", err)
 	}
 
 	if versionFlag != nil && *versionFlag {
@@ -144,54 +1212,495 @@ func newSettings() (*viper.Viper, *handlers.SftpServerConfig, devConfig) {
 		os.Exit(0)
 	}
 
-	if !v.IsSet(cfgConfigPath) {
-		panic("no config provided")
+	cmdline := make(cmdlineSettings)
+	flags.Visit(func(f *pflag.Flag) {
+		if isKnownKey(strings.Split(f.Name, "."), false) {
+			cmdline.Set(f.Name, v.Get(f.Name))
+		}
+	})
+	if err := setFlagValues(cmdline, *peers, *sets); err != nil {
+		return nil, nil, nil, newStartupError(exitUsage, "invalid command line", err)
+	}
+	cmdline.apply(v)
+
+	if err := readConfig(v); err != nil {
+		return nil, nil, nil, newStartupError(exitConfig, "read configuration", err)
+	}
+
+	return v, cmdline, sftpConfig, nil
+}
+
+// Set implements the setter of setFlagValues.
+func (s cmdlineSettings) Set(key string, value any) {
+	s[key] = value
+}
+
+// apply overrides the configuration with the settings, the overrides are kept on reload.
+func (s cmdlineSettings) apply(v *viper.Viper) {
+	for key, value := range s {
+		v.Set(key, value)
+	}
+}
+
+// setFlagValues sets the peers and `key=value` settings of the flags with v, e.g. *viper.Viper.
+func setFlagValues(v interface{ Set(string, any) }, peers, sets []string) error {
+	// Peers from flags replace the ones from the config as a whole and are equal. Viper merges
+	// nested keys of overrides with the config, so every key is set and the list is terminated
+	// with an empty address.
+	for i, address := range peers {
+		key := cfgPeers + "." + strconv.Itoa(i) + "."
+		v.Set(key+"address", address)
+		v.Set(key+"weight", 1)
+		v.Set(key+"priority", 1)
+	}
+	if len(peers) > 0 {
+		v.Set(cfgPeers+"."+strconv.Itoa(len(peers))+".address", "")
+	}
+
+	for _, set := range sets {
+		key, value, ok := strings.Cut(set, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("invalid setting %q, key=value expected", set)
+		}
+		v.Set(strings.TrimSpace(key), value)
+	}
+	return nil
+}
+
+// readConfig reads the main configuration files with environment variables expanded, the later
+// files override the earlier ones key by key. It's also used to re-read configuration
+// on reload. The gateway may be configured with flags and environment variables only, the files
+// are optional then.
+func readConfig(v *viper.Viper) error {
+	files, err := expandConfigPaths(configPaths(v))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	settings := make(map[string]any)
+	for _, file := range files {
+		fileV, err := readConfigFile(file, v.GetString(cfgConfigType))
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		settings = mergeSettings(settings, fileV.AllSettings())
+	}
+
+	// ReadConfig drops the settings of the files read before, e.g. on reload.
+	v.SetConfigType(configType)
+	if err = v.ReadConfig(strings.NewReader("")); err != nil {
+		return err
 	}
+	return v.MergeConfigMap(settings)
+}
 
-	cfgBuff := bytes.NewBuffer(nil)
-	file, err := os.ReadFile(*config)
+// readConfigFile reads the configuration file expanding environment variables in it.
+func readConfigFile(path, explicitFormat string) (*viper.Viper, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	format, err := configFormat(path, explicitFormat)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+
+	fileV := viper.New()
+	fileV.SetConfigType(format)
+	if err = fileV.ReadConfig(bytes.NewBufferString(os.ExpandEnv(string(file)))); err != nil {
+		return nil, err
+	}
+	return fileV, nil
+}
+
+// mergeSettings returns the base settings overridden by the other ones. Sections are merged
+// recursively, indexed lists are replaced.
+func mergeSettings(base, override map[string]any) map[string]any {
+	res := make(map[string]any, len(base)+len(override))
+	for key, value := range base {
+		res[key] = value
+	}
+	for key, value := range override {
+		baseSection, ok1 := res[key].(map[string]any)
+		section, ok2 := value.(map[string]any)
+		if ok1 && ok2 && !isIndexedList(section) {
+			res[key] = mergeSettings(baseSection, section)
+			continue
+		}
+		res[key] = value
+	}
+	return res
+}
+
+// isIndexedList checks whether the section is a list with `0`, `1`, ... keys.
+func isIndexedList(section map[string]any) bool {
+	if len(section) == 0 {
+		return false
+	}
+	for key := range section {
+		if _, err := strconv.Atoi(key); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// configPaths returns the configuration files and directories in the order they're merged.
+func configPaths(v *viper.Viper) []string {
+	switch paths := v.Get(cfgConfigPath).(type) {
+	case nil:
+		return nil
+	case string:
+		// A single path is set by the environment variable, it may contain spaces.
+		if paths == "" {
+			return nil
+		}
+		return []string{paths}
+	default:
+		return v.GetStringSlice(cfgConfigPath)
+	}
+}
+
+// expandConfigPaths replaces the directories of the paths with the configuration files they contain
+// sorted by name, the later files override the earlier ones.
+func expandConfigPaths(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			switch strings.ToLower(filepath.Ext(e.Name())) {
+			case ".yml", ".yaml", ".json", ".toml":
+				files = append(files, filepath.Join(path, e.Name()))
+			}
+		}
 	}
+	return files, nil
+}
 
-	expanded := os.ExpandEnv(string(file))
-	cfgBuff.WriteString(expanded)
+// configFormat returns the format of the configuration file: the explicit one if it's set,
+// otherwise JSON and TOML are detected by the extension and YAML is used for the rest.
+func configFormat(path, explicit string) (string, error) {
+	if explicit != "" {
+		switch format := strings.ToLower(explicit); format {
+		case "yaml", "yml":
+			return configType, nil
+		case "json", "toml":
+			return format, nil
+		default:
+			return "", fmt.Errorf("unsupported config format %q", explicit)
+		}
+	}
 
-	if err := v.ReadConfig(cfgBuff); err != nil {
-		panic(err)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "json", nil
+	case ".toml":
+		return "toml", nil
+	default:
+		return configType, nil
 	}
+}
+
+func fetchHooks(v *viper.Viper) ([]hookConfig, error) {
+	var hooks []hookConfig
+
+	for i := 0; ; i++ {
+		key := cfgHooks + "." + strconv.Itoa(i) + "."
+		hook := hookConfig{
+			Events:  v.GetStringSlice(key + "events"),
+			Webhook: v.GetString(key + "webhook"),
+			Command: v.GetStringSlice(key + "command"),
+			Timeout: v.GetDuration(key + "timeout"),
+		}
+		if hook.Webhook == "" && len(hook.Command) == 0 {
+			break
+		}
+		if hook.Webhook != "" && len(hook.Command) != 0 {
+			return nil, fmt.Errorf("hook %d: either webhook or command must be set", i)
+		}
+		for _, e := range hook.Events {
+			if e != handlers.EventUpload && e != handlers.EventDelete {
+				return nil, fmt.Errorf("hook %d: unknown event %q", i, e)
+			}
+		}
+		if hook.Timeout <= 0 {
+			hook.Timeout = defaultHookTimeout
+		}
 
+		hooks = append(hooks, hook)
+	}
+
+	return hooks, nil
+}
+
+func newDevConfig(v *viper.Viper) (devConfig, error) {
 	devConf := devConfig{
 		Enabled:    v.GetBool(cfgDevEnabled),
 		SSHKeyPath: v.GetString(cfgDevSSHKey),
 		Passphrase: v.GetString(cfgDevSSHPassphrase),
 		Address:    v.GetString(cfgDevListenAddress),
+
+		AuthorizedKeysPath: v.GetString(cfgDevAuthorizedKeys),
+		RevocationListPath: v.GetString(cfgDevRevokedKeys),
+		Users:              fetchDevUsers(v),
+		ShutdownTimeout:    v.GetDuration(cfgShutdownTimeout),
+		MaxSessions:        v.GetInt(cfgDevMaxSessions),
+		MaxSessionsPerIP:   v.GetInt(cfgDevMaxSessionsIP),
+		Crypto: sshCryptoConfig{
+			KeyExchanges:  v.GetStringSlice(cfgDevKeyExchanges),
+			Ciphers:       v.GetStringSlice(cfgDevCiphers),
+			MACs:          v.GetStringSlice(cfgDevMACs),
+			MinRSAKeySize: v.GetInt(cfgDevMinRSASize),
+		},
+	}
+	if devConf.ShutdownTimeout <= 0 {
+		devConf.ShutdownTimeout = defaultShutdownTimeout
+	}
+
+	if err := devConf.Crypto.validate(); err != nil {
+		return devConf, err
+	}
+
+	var err error
+	if devConf.Listeners, err = fetchListeners(v); err != nil {
+		return devConf, err
+	}
+	devConf.TOTPSecrets, err = fetchTOTPSecrets(v)
+
+	return devConf, err
+}
+
+func newAdminConfig(v *viper.Viper) (adminConfig, error) {
+	conf := adminConfig{
+		Enabled: v.GetBool(cfgAdminEnabled),
+		Address: v.GetString(cfgAdminAddress),
+		Token:   v.GetString(cfgAdminToken),
+	}
+	if conf.Enabled && conf.Token == "" {
+		return conf, fmt.Errorf("%s must be set to enable admin API", cfgAdminToken)
+	}
+	return conf, nil
+}
+
+func newSyncConfig(v *viper.Viper) (syncConfig, error) {
+	conf := syncConfig{
+		Interval:   v.GetDuration(cfgSyncInterval),
+		Delay:      v.GetDuration(cfgSyncDelay),
+		Standalone: v.GetBool(cfgSyncStandalone),
+	}
+	if conf.Interval <= 0 {
+		conf.Interval = defaultSyncInterval
+	}
+	if conf.Delay <= 0 {
+		conf.Delay = defaultSyncDelay
+	}
+
+	for i := 0; ; i++ {
+		key := cfgSyncJobs + "." + strconv.Itoa(i) + "."
+		job := syncJobConfig{
+			Source: v.GetString(key + "source"),
+			Target: v.GetString(key + "target"),
+			Delete: v.GetBool(key + "delete"),
+		}
+		if job.Source == "" {
+			break
+		}
+		if info, err := os.Stat(job.Source); err != nil {
+			return conf, fmt.Errorf("sync job %d: %w", i, err)
+		} else if !info.IsDir() {
+			return conf, fmt.Errorf("sync job %d: source %s is not a directory", i, job.Source)
+		}
+		if cnrName, _ := splitSyncTarget(job.Target); cnrName == "" {
+			return conf, fmt.Errorf("sync job %d: target container must be set", i)
+		}
+
+		conf.Jobs = append(conf.Jobs, job)
+	}
+
+	if conf.Standalone && len(conf.Jobs) == 0 {
+		return conf, errors.New("sync jobs must be set to run standalone")
+	}
+	return conf, nil
+}
+
+func newExportConfig(v *viper.Viper) (exportConfig, error) {
+	conf := exportConfig{
+		Interval:   v.GetDuration(cfgExportInterval),
+		Standalone: v.GetBool(cfgExportStandalone),
+	}
+
+	for i := 0; ; i++ {
+		key := cfgExportJobs + "." + strconv.Itoa(i) + "."
+		job := exportJobConfig{
+			Source: v.GetString(key + "source"),
+			Target: v.GetString(key + "target"),
+		}
+		if job.Source == "" {
+			break
+		}
+		if cnrName, _ := splitSyncTarget(job.Source); cnrName == "" {
+			return conf, fmt.Errorf("export job %d: source container must be set", i)
+		}
+		if job.Target == "" {
+			return conf, fmt.Errorf("export job %d: target must be set", i)
+		}
+		if err := checkExportTarget(job.Target); err != nil {
+			return conf, fmt.Errorf("export job %d: %w", i, err)
+		}
+
+		conf.Jobs = append(conf.Jobs, job)
+	}
+
+	if conf.Standalone && len(conf.Jobs) == 0 {
+		return conf, errors.New("export jobs must be set to run standalone")
+	}
+	return conf, nil
+}
+
+func newRedisConfig(v *viper.Viper) redisConfig {
+	return redisConfig{
+		Address:  v.GetString(cfgCacheRedisAddress),
+		Password: v.GetString(cfgCacheRedisPassword),
+		DB:       v.GetInt(cfgCacheRedisDB),
+	}
+}
+
+func newTracingConfig(v *viper.Viper) tracingConfig {
+	return tracingConfig{
+		Enabled:  v.GetBool(cfgTracingEnabled),
+		Endpoint: v.GetString(cfgTracingEndpoint),
+		Insecure: v.GetBool(cfgTracingInsecure),
+	}
+}
+
+func newAuditConfig(v *viper.Viper) (handlers.AuditConfig, error) {
+	conf := handlers.AuditConfig{
+		BatchSize:     v.GetInt(cfgAuditBatchSize),
+		FlushInterval: v.GetDuration(cfgAuditFlushInterval),
 	}
+	if err := conf.Container.DecodeString(v.GetString(cfgAuditContainer)); err != nil {
+		return conf, fmt.Errorf("invalid %s: %w", cfgAuditContainer, err)
+	}
+	return conf, nil
+}
+
+// userSettings returns settings from the user configuration file if it's enabled, v otherwise.
+// The command line settings override the ones of the file as well.
+func userSettings(v *viper.Viper, cmdline cmdlineSettings) (*viper.Viper, error) {
+	if !v.GetBool(cfgUserEnabled) || !v.IsSet(cfgUserPath) {
+		return v, nil
+	}
+
 	userV := viper.New()
-	userV.SetConfigType(configType)
 	setDefaults(userV)
-	if !v.GetBool(cfgUserEnabled) || !v.IsSet(cfgUserPath) {
-		userV = v
-	} else {
-		userConfigPath := v.GetString(cfgUserPath)
-		if cfgFile, err := os.Open(userConfigPath); err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			userV = v
-		} else if err := userV.ReadConfig(cfgFile); err != nil {
-			panic(err)
-		}
+
+	userConfigPath := v.GetString(cfgUserPath)
+	// The user config format is detected by its own extension.
+	format, err := configFormat(userConfigPath, "")
+	if err != nil {
+		return nil, err
+	}
+	userV.SetConfigType(format)
+	cfgFile, err := os.Open(userConfigPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return v, nil
+	}
+	defer cfgFile.Close()
+
+	if err = userV.ReadConfig(cfgFile); err != nil {
+		return nil, err
 	}
+	cmdline.apply(userV)
+
+	return userV, nil
+}
+
+// setMainDefaults sets the defaults of the settings read from the main config only.
+func setMainDefaults(v *viper.Viper) {
+	// dev section
+	v.SetDefault(cfgDevListenAddress, "0.0.0.0:2022")
+	v.SetDefault(cfgDevEnabled, false)
+
+	// user section
+	v.SetDefault(cfgUserEnabled, false)
+
+	// admin section
+	v.SetDefault(cfgAdminAddress, "localhost:8090")
+
+	// usage section
+	v.SetDefault(cfgUsageRefreshInterval, handlers.DefaultUsageRefreshInterval)
+	v.SetDefault(cfgBalanceRefreshInterval, handlers.DefaultBalanceRefreshInterval)
+	v.SetDefault(cfgCacheRedisAddress, "")
+	v.SetDefault(cfgCacheRedisPassword, "")
+	v.SetDefault(cfgCacheRedisDB, 0)
+
+	v.SetDefault(cfgStrictKeys, false)
+
+	// files section
+	v.SetDefault(cfgFilesMode, defaultFileMode)
+	v.SetDefault(cfgFilesDirectoryMode, defaultFileMode)
+	v.SetDefault(cfgFilesUID, 0)
+	v.SetDefault(cfgFilesGID, 0)
+
+	// reporting section
+	v.SetDefault(cfgReportingLevel, "error")
+	v.SetDefault(cfgReportingTimeout, 5*time.Second)
+
+	// logger section
+	v.SetDefault(cfgLoggerEncoding, "json")
+	v.SetDefault(cfgLoggerTimestamp, "epoch")
+	v.SetDefault(cfgLoggerCaller, true)
+	v.SetDefault(cfgLoggerSlowThreshold, time.Duration(0))
+	v.SetDefault(cfgLoggerSampling, true)
+	v.SetDefault(cfgLoggerSamplingFirst, 100)
+	v.SetDefault(cfgLoggerSamplingNext, 100)
+
+	// tracing section
+	v.SetDefault(cfgTracingEndpoint, "localhost:4317")
 
-	return userV, sftpConfig, devConf
+	// reload section
+	v.SetDefault(cfgReloadDelay, defaultReloadDelay)
 }
 
+// setDefaults sets the defaults of the settings the user config may override.
 func setDefaults(v *viper.Viper) {
 	v.SetDefault(cfgRequestTimeout, defaultRequestTimeout)
+	v.SetDefault(cfgStreamTimeout, defaultStreamTimeout)
+	v.SetDefault(cfgPutTimeout, time.Duration(0))
+	v.SetDefault(cfgGetTimeout, time.Duration(0))
 	v.SetDefault(cfgConnectTimeout, defaultConnectTimeout)
 	v.SetDefault(cfgRebalanceTimer, defaultRebalanceTimer)
+	v.SetDefault(cfgHealthcheckInterval, time.Duration(0))
+	v.SetDefault(cfgHealthcheckTimeout, time.Duration(0))
+	v.SetDefault(cfgNeoFSContainerNNSZone, defaultNNSZone)
+	v.SetDefault(cfgNeoFSContainerRmdir, string(handlers.RmdirDelete))
+	v.SetDefault(cfgNeoFSContainerInline, false)
+	v.SetDefault(cfgNeoFSContainerHashing, string(handlers.HomomorphicHashingAuto))
+	v.SetDefault(cfgNeoFSContainerWaitPoll, waiter.DefaultPollInterval)
+	v.SetDefault(cfgNeoFSContainerWaitTime, time.Minute)
+	v.SetDefault(cfgTrashRetention, 7*24*time.Hour)
 }
 
-func newLogger(_ *viper.Viper, sftpConfig *handlers.SftpServerConfig) *zap.Logger {
+func newLogger(v *viper.Viper, sftpConfig *handlers.SftpServerConfig) (*zap.Logger, zap.AtomicLevel, error) {
 	config := zap.NewProductionConfig()
 
 	debugStream := "/dev/null"
@@ -202,14 +1711,87 @@ func newLogger(_ *viper.Viper, sftpConfig *handlers.SftpServerConfig) *zap.Logge
 	config.OutputPaths = []string{debugStream}
 	config.ErrorOutputPaths = []string{debugStream}
 
-	if err := config.Level.UnmarshalText([]byte(sftpConfig.DebugLevel)); err != nil {
+	if err := config.Level.UnmarshalText([]byte(loggerLevel(v, sftpConfig))); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 	}
 
-	l, err := config.Build()
+	switch encoding := v.GetString(cfgLoggerEncoding); encoding {
+	case "json", "console":
+		config.Encoding = encoding
+	default:
+		fmt.Fprintf(os.Stderr, "unknown logger encoding %q, json is used\n", encoding)
+	}
+	if err := config.EncoderConfig.EncodeTime.UnmarshalText([]byte(v.GetString(cfgLoggerTimestamp))); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	config.DisableCaller = !v.GetBool(cfgLoggerCaller)
+
+	// Sampling is applied to all outputs, so it's set up after the file one is added.
+	config.Sampling = nil
+
+	opts := []zap.Option{zap.WithFatalHook(fatalHook{})}
+	if path := v.GetString(cfgLoggerFilePath); path != "" {
+		file := zapcore.NewCore(newLogEncoder(config), zapcore.AddSync(newLogFile(v, path)), config.Level)
+		opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(core, file)
+		}))
+	}
+	if v.GetBool(cfgReportingEnabled) {
+		reporter := newErrorReporter(newReportConfig(v))
+		opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(core, reporter)
+		}))
+	}
+	if v.GetBool(cfgLoggerSampling) {
+		first, next := v.GetInt(cfgLoggerSamplingFirst), v.GetInt(cfgLoggerSamplingNext)
+		opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewSamplerWithOptions(core, time.Second, first, next)
+		}))
+	}
+
+	l, err := config.Build(opts...)
 	if err != nil {
-		panic(err)
+		return nil, config.Level, err
+	}
+
+	return l, config.Level, nil
+}
+
+func newLogEncoder(config zap.Config) zapcore.Encoder {
+	if config.Encoding == "console" {
+		return zapcore.NewConsoleEncoder(config.EncoderConfig)
 	}
+	return zapcore.NewJSONEncoder(config.EncoderConfig)
+}
 
-	return l
+func newReportConfig(v *viper.Viper) reportConfig {
+	conf := reportConfig{
+		Enabled: v.GetBool(cfgReportingEnabled),
+		URL:     v.GetString(cfgReportingWebhook),
+		Timeout: v.GetDuration(cfgReportingTimeout),
+	}
+	if err := conf.Level.UnmarshalText([]byte(v.GetString(cfgReportingLevel))); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		conf.Level = zapcore.ErrorLevel
+	}
+	return conf
+}
+
+// newLogFile returns the log file rotated by size and age.
+func newLogFile(v *viper.Viper, path string) *lumberjack.Logger {
+	return &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    v.GetInt(cfgLoggerFileMaxSize),
+		MaxAge:     v.GetInt(cfgLoggerFileMaxAge),
+		MaxBackups: v.GetInt(cfgLoggerFileMaxBackups),
+		Compress:   v.GetBool(cfgLoggerFileCompress),
+	}
+}
+
+// loggerLevel returns the level from configuration file if it's set, the command line one otherwise.
+func loggerLevel(v *viper.Viper, sftpConfig *handlers.SftpServerConfig) string {
+	if v.IsSet(cfgLoggerLevel) {
+		return v.GetString(cfgLoggerLevel)
+	}
+	return sftpConfig.DebugLevel
 }
@@ -0,0 +1,527 @@
+package main
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/nspcc-dev/neofs-sftp-gw/handlers"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestFetchEACL(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	pub := hex.EncodeToString(key.PublicKey().Bytes())
+
+	v := viper.New()
+	v.SetConfigType(configType)
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+neofs:
+  container:
+    eacl: Share
+users:
+  alice:
+    eacl: invalid
+  bob:
+    eacl: partner
+eacl_templates:
+  share:
+    0:
+      action: allow
+      operations: [ get, head ]
+      role: others
+  partner:
+    0:
+      action: allow
+      operations: [ put ]
+      keys: [ `+pub+` ]
+    1:
+      action: deny
+      operations: [ put ]
+      role: others
+  invalid:
+    0:
+      action: allow
+      operations: [ get ]
+`)))
+
+	table, users := fetchEACL(zap.NewNop(), v)
+	require.NotNil(t, table)
+	require.Len(t, table.Records(), 2)
+	require.Equal(t, eacl.OperationHead, table.Records()[1].Operation())
+	require.Equal(t, eacl.RoleOthers, table.Records()[1].Targets()[0].Role())
+
+	require.NotContains(t, users, "alice")
+	require.Contains(t, users, "bob")
+	records := users["bob"].Records()
+	require.Len(t, records, 2)
+	require.Equal(t, [][]byte{key.PublicKey().Bytes()}, records[0].Targets()[0].BinaryKeys())
+	require.Equal(t, eacl.ActionDeny, records[1].Action())
+}
+
+func TestSetFlagValues(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType(configType)
+	require.NoError(t, setFlagValues(v, []string{"grpc://s01:8080", "grpc://s02:8080"},
+		[]string{"neofs.container.basic_acl=public-read", "uploads.expiration.0.ttl=24h"}))
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+peers:
+  0:
+    address: grpc://localhost:8080
+    weight: 5
+    priority: 2
+  1:
+    address: grpc://localhost:8081
+  2:
+    address: grpc://localhost:8082
+neofs:
+  container:
+    basic_acl: private
+    policy: REP 2
+`)))
+
+	peers := fetchPeers(zap.NewNop(), v)
+	require.Len(t, peers, 2)
+	// Peers of the config are replaced as a whole.
+	require.Equal(t, peerConfig{Address: "grpc://s01:8080", Priority: 1, Weight: 1}, peers[0])
+	require.Equal(t, peerConfig{Address: "grpc://s02:8080", Priority: 1, Weight: 1}, peers[1])
+
+	require.Equal(t, "public-read", v.GetString(cfgNeoFSContainerBasicACL))
+	require.Equal(t, "REP 2", v.GetString(cfgNeoFSContainerPolicy))
+	require.Equal(t, "24h", v.GetString(cfgUploadsExpiration+".0.ttl"))
+
+	require.Error(t, setFlagValues(v, nil, []string{"no-value"}))
+}
+
+func TestReadConfigFormats(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"config.json": `{"peers": {"0": {"address": "grpc://s01:8080"}}, "shutdown_timeout": "1m"}`,
+		"config.toml": "shutdown_timeout = \"1m\"\n[peers.0]\naddress = \"grpc://s01:8080\"\n",
+		"config.yml":  "shutdown_timeout: 1m\npeers:\n  0:\n    address: grpc://s01:8080\n",
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		v := viper.New()
+		v.Set(cfgConfigPath, path)
+		require.NoError(t, readConfig(v), name)
+		require.Equal(t, "grpc://s01:8080", v.GetString(cfgPeers+".0.address"), name)
+		require.Equal(t, time.Minute, v.GetDuration(cfgShutdownTimeout), name)
+	}
+
+	format, err := configFormat("config.conf", "TOML")
+	require.NoError(t, err)
+	require.Equal(t, "toml", format)
+	_, err = configFormat("config.yml", "ini")
+	require.Error(t, err)
+}
+
+func TestEnvOnlySettings(t *testing.T) {
+	t.Setenv("SFTP_GW_WALLET_PATH", "/etc/wallet.json")
+	t.Setenv("SFTP_GW_PEERS_0_ADDRESS", "grpc://s01:8080")
+	t.Setenv("SFTP_GW_PEERS_1_ADDRESS", "grpc://s02:8080")
+
+	v := newViper()
+	require.NoError(t, readConfig(v))
+	require.Equal(t, "/etc/wallet.json", v.GetString(cfgWallet))
+
+	peers := fetchPeers(zap.NewNop(), v)
+	require.Len(t, peers, 2)
+	require.Equal(t, "grpc://s02:8080", peers[1].Address)
+}
+
+func TestUserSettings(t *testing.T) {
+	userPath := filepath.Join(t.TempDir(), "user.yml")
+	require.NoError(t, os.WriteFile(userPath, []byte(`
+wallet:
+  path: /home/alice/wallet.json
+peers:
+  0:
+    address: grpc://alice:8080
+neofs:
+  container:
+    basic_acl: public-read
+`), 0o600))
+
+	v := newViper()
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+user:
+  enabled: true
+  path: `+userPath+`
+wallet:
+  path: /etc/wallet.json
+  address: NbUgTSFvPmsRxmGeWpuuGeJUoRoi6PErcM
+peers:
+  0:
+    address: grpc://s01:8080
+  1:
+    address: grpc://s02:8080
+neofs:
+  container:
+    policy: REP 2
+    basic_acl: private
+`)))
+
+	t.Setenv("SFTP_GW_WALLET_ADDRESS", "NbUgTSFvPmsRxmGeWpuuGeJUoRoi6PErcM")
+
+	userV, err := userSettings(v, nil)
+	require.NoError(t, err)
+	require.Equal(t, "/home/alice/wallet.json", userV.GetString(cfgWallet))
+	require.Empty(t, userV.GetString(cfgAddress), "neither main config nor environment is inherited")
+	require.NotEqual(t, "REP 2", userV.GetString(cfgNeoFSContainerPolicy))
+	require.Equal(t, "public-read", userV.GetString(cfgNeoFSContainerBasicACL))
+
+	peers := fetchPeers(zap.NewNop(), userV)
+	require.Len(t, peers, 1)
+	require.Equal(t, "grpc://alice:8080", peers[0].Address)
+}
+
+func TestWallets(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	v := viper.New()
+	v.SetConfigType(configType)
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+wallets:
+  Backup:
+    key: `+hex.EncodeToString(key.Bytes())+`
+users:
+  alice:
+    wallet: backup
+  bob:
+    wallet: unknown
+mounts:
+  0:
+    name: partner
+    container: BJeErH9MWmf52VsR1mLWKkgF3pRm3FkubYxM7TZkBP4K
+    wallet: Backup
+  1:
+    name: other
+    container: BJeErH9MWmf52VsR1mLWKkgF3pRm3FkubYxM7TZkBP4K
+    wallet: unknown
+`)))
+
+	wallets := loadWallets(zap.NewNop(), v)
+	require.Len(t, wallets, 1)
+	require.Equal(t, user.NewAutoIDSignerRFC6979(key.PrivateKey).UserID(), wallets["backup"].UserID())
+
+	signers := fetchUserSigners(zap.NewNop(), v, wallets)
+	require.Len(t, signers, 1)
+	require.Equal(t, wallets["backup"].UserID(), signers["alice"].UserID())
+
+	mounts := fetchMounts(zap.NewNop(), v, wallets)
+	require.Len(t, mounts, 1)
+	require.Equal(t, "partner", mounts[0].Name)
+	require.Equal(t, wallets["backup"].UserID(), mounts[0].Signer.UserID())
+}
+
+func TestValidateContainerSettings(t *testing.T) {
+	const valid = `
+policy_aliases:
+  gold: "REP 3"
+eacl_templates:
+  share:
+    0:
+      action: allow
+      operations: [ get ]
+      role: others
+neofs:
+  container:
+    policy: Gold
+    basic_acl: public-read
+    eacl: share
+    policy_rules:
+      0:
+        pattern: "tmp-*"
+        policy: "REP 1"
+users:
+  alice:
+    basic_acl: private
+    eacl: Share
+`
+	read := func(config string) *viper.Viper {
+		v := viper.New()
+		v.SetConfigType(configType)
+		require.NoError(t, v.ReadConfig(strings.NewReader(config)))
+		return v
+	}
+	require.NoError(t, validateContainerSettings(read(valid)))
+	require.NoError(t, validateContainerSettings(read("")))
+
+	for _, tc := range []struct{ old, new string }{
+		{`gold: "REP 3"`, `gold: "REP X"`},
+		{`policy: Gold`, `policy: silver`},
+		{`policy: "REP 1"`, `policy: ""`},
+		{`basic_acl: public-read`, `basic_acl: public-everything`},
+		{`basic_acl: private`, `basic_acl: 0xZZ`},
+		{`eacl: share`, `eacl: other`},
+		{`eacl: Share`, `eacl: other`},
+		{`action: allow`, `action: permit`},
+	} {
+		require.Error(t, validateContainerSettings(read(strings.Replace(valid, tc.old, tc.new, 1))), tc.new)
+	}
+}
+
+func TestReadConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	confDir := filepath.Join(dir, "conf.d")
+	require.NoError(t, os.Mkdir(confDir, 0o700))
+	for name, content := range map[string]string{
+		"config.yml":             "shutdown_timeout: 1m\nlogger:\n  level: info\npeers:\n  0:\n    address: grpc://s01:8080\n  1:\n    address: grpc://s02:8080\n",
+		"conf.d/20-peers.json":   `{"peers": {"0": {"address": "grpc://s03:8080"}}}`,
+		"conf.d/10-secrets.toml": "[wallet]\npassphrase = \"secret\"\n[logger]\nlevel = \"debug\"\n",
+		"conf.d/30-ignored.txt":  "shutdown_timeout: 2m\n",
+		"conf.d/.40-hidden.yml":  "shutdown_timeout: 3m\n",
+		"override.yml":           "logger:\n  level: warn\n",
+		"conf.d/15-server.yaml":  "admin:\n  enabled: true\n",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	v := viper.New()
+	v.Set(cfgConfigPath, []string{filepath.Join(dir, "config.yml"), confDir, filepath.Join(dir, "override.yml")})
+	require.NoError(t, readConfig(v))
+	require.Equal(t, time.Minute, v.GetDuration(cfgShutdownTimeout))
+	require.Equal(t, "warn", v.GetString(cfgLoggerLevel))
+	require.Equal(t, "secret", v.GetString("wallet.passphrase"))
+	require.True(t, v.GetBool(cfgAdminEnabled))
+	require.Equal(t, "grpc://s03:8080", v.GetString(cfgPeers+".0.address"))
+	require.False(t, v.IsSet(cfgPeers+".1.address"), "lists are replaced")
+
+	// Settings of the removed files are dropped on reload.
+	v.Set(cfgConfigPath, filepath.Join(dir, "config.yml"))
+	require.NoError(t, readConfig(v))
+	require.False(t, v.IsSet("wallet.passphrase"))
+	require.Equal(t, "grpc://s02:8080", v.GetString(cfgPeers+".1.address"))
+
+	v.Set(cfgConfigPath, []string{filepath.Join(dir, "missing.yml")})
+	require.Error(t, readConfig(v))
+}
+
+func TestFetchFileOwners(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType(configType)
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+files:
+  uid: 1000
+  gid: 100
+users:
+  alice:
+    uid: 1001
+  bob:
+    owners: [ ]
+`)))
+
+	owner, users := fetchFileOwners(v, v)
+	require.Equal(t, handlers.FileOwner{UID: 1000, GID: 100}, owner)
+	require.Equal(t, map[string]handlers.FileOwner{"alice": {UID: 1001, GID: 100}}, users)
+}
+
+func TestReservedUploadAttributes(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType(configType)
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+uploads:
+  attributes:
+    0:
+      pattern: "/alpha/**"
+      attributes:
+        0:
+          key: "FileName"
+          value: "other"
+        1:
+          key: "X-Project"
+          value: "alpha"
+    1:
+      pattern: "/beta/**"
+      attributes:
+        0:
+          key: "Timestamp"
+          value: "0"
+  directories:
+    0:
+      pattern: "/reports/**"
+      attributes:
+        0:
+          key: "FilePath"
+          value: "/other"
+        1:
+          key: "X-Department"
+          value: "finance"
+`)))
+
+	rules := fetchAttributeRules(zap.NewNop(), v)
+	require.Equal(t, []handlers.AttributeRule{{
+		Pattern:    "/alpha/**",
+		Attributes: []handlers.Attribute{{Key: "X-Project", Value: "alpha"}},
+	}}, rules)
+
+	defaults := fetchDirectoryDefaults(zap.NewNop(), v)
+	require.Len(t, defaults, 1)
+	require.Equal(t, []handlers.Attribute{{Key: "X-Department", Value: "finance"}}, defaults[0].Attributes)
+}
+
+func TestFillServerConfigUserSettings(t *testing.T) {
+	v := newViper()
+	setMainDefaults(v)
+	setDefaults(v)
+
+	userV := viper.New()
+	setDefaults(userV)
+	userV.SetConfigType(configType)
+	require.NoError(t, userV.ReadConfig(strings.NewReader(`
+neofs:
+  container:
+    waiter:
+      poll_interval: 3s
+      timeout: 2m
+      async: true
+  tombstone:
+    lifetime: 10
+`)))
+
+	var cfg handlers.SftpServerConfig
+	fillServerConfig(zap.NewNop(), v, userV, nil, &cfg)
+	require.Equal(t, handlers.ContainerWaiter{PollInterval: 3 * time.Second, Timeout: 2 * time.Minute, Async: true},
+		cfg.ContainerWaiter)
+	require.EqualValues(t, 10, cfg.TombstoneLifetime)
+}
+
+func TestHealthcheckParams(t *testing.T) {
+	v := newViper()
+	setDefaults(v)
+
+	interval, timeout := healthcheckParams(zap.NewNop(), v)
+	require.Equal(t, defaultRebalanceTimer, interval)
+	require.Equal(t, defaultRequestTimeout, timeout)
+
+	v.Set(cfgRebalanceTimer, time.Minute)
+	v.Set(cfgRequestTimeout, 20*time.Second)
+	interval, timeout = healthcheckParams(zap.NewNop(), v)
+	require.Equal(t, time.Minute, interval)
+	require.Equal(t, 20*time.Second, timeout)
+
+	v.Set(cfgHealthcheckInterval, 30*time.Second)
+	v.Set(cfgHealthcheckTimeout, 5*time.Second)
+	interval, timeout = healthcheckParams(zap.NewNop(), v)
+	require.Equal(t, 30*time.Second, interval)
+	require.Equal(t, 5*time.Second, timeout)
+}
+
+func TestUnknownKeys(t *testing.T) {
+	// The sample configs document all the keys.
+	for _, file := range []string{"config.yml", "config.default.yml"} {
+		fileV, err := readConfigFile(file, "")
+		require.NoError(t, err)
+		require.Empty(t, unknownKeys(fileV.AllSettings()), file)
+	}
+
+	v := viper.New()
+	v.SetConfigType(configType)
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+conection:
+  connect_timeout: 10s
+connection:
+  request_timeout: 10s
+  requst_timeout: 10s
+peers:
+  0:
+    address: localhost:8080
+    wieght: 1
+users:
+  alice:
+    owners: [ ]
+    wallet: backup
+wallets:
+policy_aliases:
+  gold: "REP 3"
+`)))
+	require.Equal(t, []string{
+		"conection.connect_timeout",
+		"connection.requst_timeout",
+		"peers.0.wieght",
+	}, unknownKeys(v.AllSettings()))
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte("conection:\n  connect_timeout: 10s\n"), 0o600))
+	v = viper.New()
+	v.Set(cfgConfigPath, path)
+	require.NoError(t, checkConfigKeys(zap.NewNop(), v))
+	v.Set(cfgStrictKeys, true)
+	require.ErrorContains(t, checkConfigKeys(zap.NewNop(), v), "conection.connect_timeout")
+}
+
+func TestNewSettingsErrors(t *testing.T) {
+	exitCode := func(err error) int {
+		var startErr *startupError
+		require.ErrorAs(t, err, &startErr)
+		return startErr.code
+	}
+
+	_, _, _, err := newSettings([]string{"--unknown"})
+	require.Equal(t, exitUsage, exitCode(err))
+
+	_, _, _, err = newSettings([]string{"--set", "invalid"})
+	require.Equal(t, exitUsage, exitCode(err))
+
+	_, _, _, err = newSettings([]string{"--config", filepath.Join(t.TempDir(), "missing.yml")})
+	require.Equal(t, exitConfig, exitCode(err))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, _, _, err = newSettings([]string{"--help"})
+	require.ErrorIs(t, err, pflag.ErrHelp)
+
+	v, _, _, err := newSettings([]string{"--wallet.path", "wallet.json"})
+	require.NoError(t, err)
+	require.Equal(t, "wallet.json", v.GetString(cfgWallet))
+}
+
+func TestUserSettingsCommandLine(t *testing.T) {
+	dir := t.TempDir()
+	userPath := filepath.Join(dir, "user.yml")
+	require.NoError(t, os.WriteFile(userPath, []byte(`
+wallet:
+  path: /home/alice/wallet.json
+peers:
+  0:
+    address: grpc://alice:8080
+neofs:
+  container:
+    policy: REP 1
+    basic_acl: public-read
+`), 0o600))
+	mainPath := filepath.Join(dir, "config.yml")
+	require.NoError(t, os.WriteFile(mainPath, []byte(`
+user:
+  enabled: true
+  path: `+userPath+`
+`), 0o600))
+
+	v, cmdline, _, err := newSettings([]string{"--config", mainPath, "--wallet.path", "wallet.json",
+		"--peer", "grpc://s01:8080", "--neofs.container.policy", "REP 3", "--set", "connection.put_timeout=1m"})
+	require.NoError(t, err)
+
+	userV, err := userSettings(v, cmdline)
+	require.NoError(t, err)
+	require.Equal(t, "wallet.json", userV.GetString(cfgWallet))
+	require.Equal(t, "REP 3", userV.GetString(cfgNeoFSContainerPolicy))
+	require.Equal(t, "public-read", userV.GetString(cfgNeoFSContainerBasicACL))
+	require.Equal(t, time.Minute, userV.GetDuration(cfgPutTimeout))
+
+	peers := fetchPeers(zap.NewNop(), userV)
+	require.Len(t, peers, 1)
+	require.Equal(t, "grpc://s01:8080", peers[0].Address)
+}
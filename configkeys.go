@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// configKeys are the known configuration keys, `*` matches any list index or map key.
+var configKeys = []string{
+	cfgConfigPath,
+	cfgConfigType,
+	cfgStrictKeys,
+	cfgShutdownTimeout,
+	cfgReadOnlyPaths,
+
+	"user.enabled", "user.path",
+
+	"wallet.path", "wallet.address", "wallet.passphrase", "wallet.passphrase_file", "wallet.key",
+	"wallets.*.path", "wallets.*.address", "wallets.*.passphrase", "wallets.*.passphrase_file", "wallets.*.key",
+
+	"peers.*.address", "peers.*.weight", "peers.*.priority",
+
+	"connection.connect_timeout", "connection.request_timeout", "connection.stream_timeout",
+	"connection.put_timeout", "connection.get_timeout", "connection.rebalance_timer",
+	"connection.error_threshold", "connection.healthcheck.interval", "connection.healthcheck.timeout",
+	"connection.startup.retry", "connection.startup.max_wait",
+	"connection.weights.auto", "connection.weights.interval",
+
+	"dev.enabled", "dev.sshkey", "dev.passphrase", "dev.address",
+	"dev.listeners.*.address", "dev.listeners.*.auth", "dev.listeners.*.proxy_protocol",
+	"dev.max_sessions", "dev.max_sessions_per_ip",
+	"dev.crypto.key_exchanges", "dev.crypto.ciphers", "dev.crypto.macs", "dev.crypto.min_rsa_key_size",
+	"dev.users.*.name", "dev.users.*.password", "dev.users.*.public_keys",
+	"dev.authorized_keys", "dev.revoked_keys",
+
+	"users.*.owners", "users.*.wallet", "users.*.totp_secret", "users.*.basic_acl", "users.*.eacl",
+	"users.*.container_attributes.*.key", "users.*.container_attributes.*.value", "users.*.uid", "users.*.gid",
+
+	"logger.level", "logger.encoding", "logger.timestamp", "logger.caller", "logger.slow_threshold",
+	"logger.sampling.enabled", "logger.sampling.initial", "logger.sampling.thereafter",
+	"logger.file.path", "logger.file.max_size", "logger.file.max_age", "logger.file.max_backups", "logger.file.compress",
+
+	"limits.session.upload_rate", "limits.session.download_rate",
+	"limits.global.upload_rate", "limits.global.download_rate",
+
+	"admin.enabled", "admin.address", "admin.token",
+	"usage.refresh_interval",
+	"balance.refresh_interval", "balance.foreign_owners",
+	"cache.redis.address", "cache.redis.password", "cache.redis.db",
+	"files.mode", "files.directory_mode", "files.uid", "files.gid",
+	"reload.watch", "reload.delay",
+	"tracing.enabled", "tracing.endpoint", "tracing.insecure",
+	"reporting.enabled", "reporting.webhook", "reporting.level", "reporting.timeout",
+	"hooks.*.events", "hooks.*.webhook", "hooks.*.command", "hooks.*.timeout",
+	"audit.enabled", "audit.container", "audit.batch_size", "audit.flush_interval",
+
+	"eacl_templates.*.*.action", "eacl_templates.*.*.operations", "eacl_templates.*.*.role", "eacl_templates.*.*.keys",
+	"mounts.*.name", "mounts.*.container", "mounts.*.bearer_token", "mounts.*.wallet",
+
+	"uploads.expiration.*.pattern", "uploads.expiration.*.lifetime", "uploads.expiration.*.ttl",
+	"uploads.expiration_sweep",
+	"uploads.attributes.*.pattern", "uploads.attributes.*.attributes.*.key", "uploads.attributes.*.attributes.*.value",
+	"uploads.directories.*.pattern", "uploads.directories.*.attributes.*.key", "uploads.directories.*.attributes.*.value",
+	"uploads.directories.*.lifetime", "uploads.directories.*.ttl", "uploads.directories.*.content_type",
+	"uploads.mirrors.*.pattern", "uploads.mirrors.*.target",
+
+	"trash.enabled", "trash.retention",
+	"sync.standalone", "sync.interval", "sync.delay",
+	"sync.jobs.*.source", "sync.jobs.*.target", "sync.jobs.*.delete",
+	"export.standalone", "export.interval", "export.jobs.*.source", "export.jobs.*.target",
+	"policy_aliases.*",
+
+	"neofs.container.policy", "neofs.container.inline_policy", "neofs.container.basic_acl", "neofs.container.eacl",
+	"neofs.container.policy_rules.*.pattern", "neofs.container.policy_rules.*.policy",
+	"neofs.container.policy_rules.*.homomorphic_hashing",
+	"neofs.container.nns.register", "neofs.container.nns.zone",
+	"neofs.container.rmdir", "neofs.container.homomorphic_hashing",
+	"neofs.container.quotas.*.pattern", "neofs.container.quotas.*.size",
+	"neofs.container.waiter.poll_interval", "neofs.container.waiter.timeout", "neofs.container.waiter.async",
+	"neofs.container.attributes.*.key", "neofs.container.attributes.*.value",
+	"neofs.tombstone.lifetime", "neofs.nns.rpc_endpoint", "neofs.session.lifetime",
+}
+
+// unknownKeys returns the sorted keys of the settings not matching any of the known ones.
+// Empty sections are known if there are known keys in them.
+func unknownKeys(settings map[string]any) []string {
+	var unknown []string
+	var walk func(prefix []string, settings map[string]any)
+	walk = func(prefix []string, settings map[string]any) {
+		for key, value := range settings {
+			path := append(prefix[:len(prefix):len(prefix)], strings.ToLower(key))
+			section, isSection := value.(map[string]any)
+			if len(section) > 0 {
+				walk(path, section)
+				continue
+			}
+			// Empty values may be sections with all the entries commented out.
+			if !isKnownKey(path, isSection || value == nil) {
+				unknown = append(unknown, strings.Join(path, "."))
+			}
+		}
+	}
+	walk(nil, settings)
+
+	sort.Strings(unknown)
+	return unknown
+}
+
+// isKnownKey checks the key against the known ones, sections may be a prefix of them.
+func isKnownKey(key []string, section bool) bool {
+	for _, known := range configKeys {
+		segments := strings.Split(known, ".")
+		if len(segments) < len(key) || (len(segments) > len(key) && !section) {
+			continue
+		}
+		matched := true
+		for i := range key {
+			if segments[i] != "*" && segments[i] != key[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// checkConfigKeys reports unknown keys of the configuration files and of the user config:
+// they are logged as warnings or returned as an error in the strict mode.
+func checkConfigKeys(l *zap.Logger, v *viper.Viper) error {
+	files, err := expandConfigPaths(configPaths(v))
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	check := func(file, explicitFormat string) error {
+		fileV, err := readConfigFile(file, explicitFormat)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if unknown := unknownKeys(fileV.AllSettings()); len(unknown) > 0 {
+			l.Warn("unknown configuration keys are ignored", zap.String("file", file), zap.Strings("keys", unknown))
+			failed = append(failed, file+": "+strings.Join(unknown, ", "))
+		}
+		return nil
+	}
+	for _, file := range files {
+		if err = check(file, v.GetString(cfgConfigType)); err != nil {
+			return err
+		}
+	}
+	if userPath := v.GetString(cfgUserPath); v.GetBool(cfgUserEnabled) && userPath != "" {
+		// The user config format is detected by its own extension, a missing one is reported on reading.
+		if _, err = os.Stat(userPath); err == nil {
+			if err = check(userPath, ""); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(failed) > 0 && v.GetBool(cfgStrictKeys) {
+		return fmt.Errorf("unknown configuration keys: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
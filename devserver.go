@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sftp-gw/handlers"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/proxyproto"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/secrets"
+	"github.com/pkg/sftp"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+// proxyHeaderTimeout limits waiting for PROXY protocol header of a new connection.
+const proxyHeaderTimeout = 5 * time.Second
+
+// devServer is the built-in ssh server.
+type devServer struct {
+	app     *handlers.App
+	auth    *authenticator
+	limiter *sessionLimiter
+	conf    devConfig
+}
+
+func (s *devServer) run(ctx context.Context) {
+	app, devConf := s.app, s.conf
+
+	privateBytes, err := os.ReadFile(devConf.SSHKeyPath)
+	if err != nil {
+		exitOnError(app.Log, newStartupError(exitConfig, "failed to load host key", err))
+	}
+
+	passphrase, err := secrets.Resolve(ctx, devConf.Passphrase)
+	if err != nil {
+		exitOnError(app.Log, newStartupError(exitConfig, "failed to get host key passphrase", err))
+	}
+	private, err := ssh.ParsePrivateKeyWithPassphrase(privateBytes, []byte(passphrase))
+	if err != nil {
+		exitOnError(app.Log, newStartupError(exitConfig, "failed to parse host key", err))
+	}
+	if err = checkRSAKeySize(private.PublicKey(), devConf.Crypto.MinRSAKeySize); err != nil {
+		exitOnError(app.Log, newStartupError(exitConfig, "host key is too weak", err))
+	}
+
+	var wg sync.WaitGroup
+	for _, lc := range devConf.Listeners {
+		lc := lc
+		config := s.auth.serverConfig(lc.AuthMethods)
+		devConf.Crypto.apply(config)
+		config.AddHostKey(private)
+
+		listener, err := net.Listen("tcp", lc.Address)
+		if err != nil {
+			exitOnError(app.Log, newStartupError(exitFailure, "failed to listen on "+lc.Address, err))
+		}
+		app.Log.Info("Listening", zap.String("address", listener.Addr().String()),
+			zap.Strings("auth", lc.AuthMethods))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.accept(ctx, config, listener, lc.ProxyProtocol)
+		}()
+	}
+	wg.Wait()
+
+	app.Log.Info("shutting down, waiting for active uploads", zap.Duration("timeout", devConf.ShutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), devConf.ShutdownTimeout)
+	defer cancel()
+	app.Shutdown(shutdownCtx)
+}
+
+// accept serves connections of the listener until ctx is done.
+func (s *devServer) accept(ctx context.Context, config *ssh.ServerConfig, listener net.Listener, proxyProtocol bool) {
+	go func() {
+		<-ctx.Done()
+		if err := listener.Close(); err != nil {
+			s.app.Log.Error("failed to close listener", zap.Error(err))
+		}
+	}()
+
+	for {
+		nConn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.app.Log.Fatal("failed to accept incoming connection", zap.Error(err))
+		}
+
+		go s.serveConn(config, nConn, proxyProtocol)
+	}
+}
+
+func (s *devServer) serveConn(config *ssh.ServerConfig, nConn net.Conn, proxyProtocol bool) {
+	app := s.app
+	if proxyProtocol {
+		conn, err := proxyproto.ReadHeader(nConn, proxyHeaderTimeout)
+		if err != nil {
+			app.Log.Error("failed to read PROXY protocol header", zap.Stringer("remote", nConn.RemoteAddr()), zap.Error(err))
+			if err = nConn.Close(); err != nil {
+				app.Log.Debug("close connection", zap.Error(err))
+			}
+			return
+		}
+		nConn = conn
+	}
+
+	// The limits apply before the handshake, so connections over them don't cost key exchanges
+	// and authentication attempts.
+	remoteIP := hostOf(nConn.RemoteAddr())
+	if err := s.limiter.acquire(remoteIP); err != nil {
+		app.Log.Warn("connection rejected", zap.Stringer("remote", nConn.RemoteAddr()), zap.Error(err))
+		if err = nConn.Close(); err != nil {
+			app.Log.Debug("close connection", zap.Error(err))
+		}
+		return
+	}
+	defer s.limiter.release(remoteIP)
+
+	sshConn, chans, reqs, err := ssh.NewServerConn(nConn, config)
+	if err != nil {
+		app.Log.Error("failed to handshake", zap.Stringer("remote", nConn.RemoteAddr()), zap.Error(err))
+		if err = nConn.Close(); err != nil {
+			app.Log.Debug("close connection", zap.Error(err))
+		}
+		return
+	}
+	defer func() {
+		if err := sshConn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			app.Log.Debug("close connection", zap.Error(err))
+		}
+	}()
+	app = app.StartSession(sshConn.User(), sshConn.RemoteAddr().String(), sshConn.Close)
+	defer app.EndSession()
+
+	app.Log.Info("client connected", zap.String("user", sshConn.User()), zap.Stringer("remote", sshConn.RemoteAddr()))
+
+	// The incoming Request channel must be serviced.
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		app.Log.Debug("Incoming channel", zap.String("channel type", newChannel.ChannelType()))
+		if newChannel.ChannelType() != "session" {
+			if err := newChannel.Reject(ssh.UnknownChannelType, "unknown channel type"); err != nil {
+				app.Log.Error("reject error", zap.Error(err))
+			}
+			app.Log.Warn("Unknown channel type", zap.String("type", newChannel.ChannelType()))
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			app.Log.Error("could not accept channel", zap.Error(err))
+			continue
+		}
+		app.Log.Debug("Channel accepted")
+
+		go func(in <-chan *ssh.Request) {
+			for req := range in {
+				ok := false
+				switch req.Type {
+				case "subsystem":
+					if len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp" {
+						ok = true
+					}
+				}
+				if err := req.Reply(ok, nil); err != nil {
+					app.Log.Error("reply error", zap.Error(err))
+				}
+			}
+		}(requests)
+
+		go serveDevChannel(app, channel)
+	}
+}
+
+func hostOf(addr net.Addr) string {
+	if tcp, ok := addr.(*net.TCPAddr); ok {
+		return tcp.IP.String()
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+func serveDevChannel(app *handlers.App, channel ssh.Channel) {
+	server := sftp.NewRequestServer(app.ExtendConn(channel), sftp.Handlers{
+		FileGet:  app,
+		FilePut:  app,
+		FileCmd:  app,
+		FileList: app,
+	})
+
+	if err := server.Serve(); err == io.EOF {
+		app.Log.Info("sftp client exited session.")
+	} else if err != nil {
+		app.Log.Error("sftp server completed with error:", zap.Error(err))
+	}
+
+	if err := server.Close(); err != nil && !errors.Is(err, io.EOF) {
+		app.Log.Error("sftp server close error", zap.Error(err))
+	}
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/nspcc-dev/neofs-sftp-gw/handlers"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/layer/layertest"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+const e2eUser = "test"
+
+// e2eServer is the built-in ssh server working with the in-memory layer.
+type e2eServer struct {
+	address string
+	storage *layertest.Memory
+	owner   user.ID
+	// clientKey is accepted for e2eUser.
+	clientKey ed25519.PrivateKey
+	limiter   *sessionLimiter
+}
+
+func startE2EServer(t *testing.T) *e2eServer {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	signer := user.NewAutoIDSignerRFC6979(key.PrivateKey)
+
+	s := &e2eServer{storage: layertest.NewMemory(), owner: signer.UserID(), limiter: newSessionLimiter(0, 0)}
+	app := handlers.NewApp(s.storage, signer, &s.owner, zap.NewNop(), &handlers.SftpServerConfig{
+		BasicACL:        acl.PrivateExtended,
+		ContainerWaiter: handlers.ContainerWaiter{PollInterval: time.Millisecond},
+	}, "REP 1")
+
+	clientPub, clientKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	s.clientKey = clientKey
+	sshPub, err := ssh.NewPublicKey(clientPub)
+	require.NoError(t, err)
+
+	devConf := devConfig{Users: []devUserConfig{{
+		Name:       e2eUser,
+		PublicKeys: []string{string(ssh.MarshalAuthorizedKey(sshPub))},
+	}}}
+	auth, err := newAuthenticator(zap.NewNop(), devConf)
+	require.NoError(t, err)
+
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	require.NoError(t, err)
+	config := auth.serverConfig(nil)
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s.address = listener.Addr().String()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	srv := &devServer{app: app, auth: auth, limiter: s.limiter, conf: devConf}
+	go srv.accept(ctx, config, listener, false)
+
+	return s
+}
+
+func (s *e2eServer) sshDial(t *testing.T) (*ssh.Client, error) {
+	signer, err := ssh.NewSignerFromKey(s.clientKey)
+	require.NoError(t, err)
+
+	return ssh.Dial("tcp", s.address, &ssh.ClientConfig{
+		User:            e2eUser,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         5 * time.Second,
+	})
+}
+
+func (s *e2eServer) dial(t *testing.T) *sftp.Client {
+	conn, err := s.sshDial(t)
+	require.NoError(t, err)
+
+	c, err := sftp.NewClient(conn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = c.Close()
+		_ = conn.Close()
+	})
+	return c
+}
+
+// objectID returns the ID of the only object of the only container, files can be
+// downloaded by their object IDs only.
+func (s *e2eServer) objectID(t *testing.T) oid.ID {
+	containers, err := s.storage.ContainerList(context.Background(), s.owner, client.PrmContainerList{})
+	require.NoError(t, err)
+	require.Len(t, containers, 1)
+
+	objects := s.storage.Objects(containers[0])
+	require.Len(t, objects, 1)
+	id, _ := objects[0].ID()
+	return id
+}
+
+// lastObjectID returns the ID of the object put last in the only container.
+func (s *e2eServer) lastObjectID(t *testing.T) oid.ID {
+	containers, err := s.storage.ContainerList(context.Background(), s.owner, client.PrmContainerList{})
+	require.NoError(t, err)
+	require.Len(t, containers, 1)
+
+	objects := s.storage.Objects(containers[0])
+	require.NotEmpty(t, objects)
+	id, _ := objects[len(objects)-1].ID()
+	return id
+}
+
+func readDirNames(t *testing.T, c *sftp.Client, path string) []string {
+	files, err := c.ReadDir(path)
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestEndToEnd(t *testing.T) {
+	s := startE2EServer(t)
+	c := s.dial(t)
+
+	// Several MiB are uploaded with concurrent out-of-order writes.
+	content := make([]byte, 3<<20+12345)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+
+	require.NoError(t, c.Mkdir("/docs"))
+	require.Contains(t, readDirNames(t, c, "/"), "docs")
+
+	f, err := c.OpenFile("/docs/data.bin", os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	require.NoError(t, err)
+	_, err = f.ReadFrom(bytes.NewReader(content))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.Equal(t, []string{"data.bin"}, readDirNames(t, c, "/docs"))
+
+	path := "/docs/" + s.objectID(t).EncodeToString()
+	info, err := c.Stat(path)
+	require.NoError(t, err)
+	require.EqualValues(t, len(content), info.Size())
+
+	t.Run("get", func(t *testing.T) {
+		f, err := c.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+
+		var buf bytes.Buffer
+		_, err = f.WriteTo(&buf)
+		require.NoError(t, err)
+		require.Equal(t, content, buf.Bytes())
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		f, err := c.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+
+		offset := int64(len(content) - 100000)
+		_, err = f.Seek(offset, io.SeekStart)
+		require.NoError(t, err)
+		rest, err := io.ReadAll(f)
+		require.NoError(t, err)
+		require.Equal(t, content[offset:], rest)
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		// The client shows base names of the files.
+		require.NoError(t, c.Rename("/docs/data.bin", "/docs/moved/renamed.bin"))
+		require.Equal(t, []string{"renamed.bin"}, readDirNames(t, c, "/docs"))
+	})
+
+	t.Run("rm", func(t *testing.T) {
+		require.NoError(t, c.Remove("/docs/moved/renamed.bin"))
+		require.Empty(t, readDirNames(t, c, "/docs"))
+
+		require.NoError(t, c.RemoveDirectory("/docs"))
+		require.NotContains(t, readDirNames(t, c, "/"), "docs")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := c.Stat("/missing")
+		require.Error(t, err)
+		require.ErrorIs(t, c.Mkdir("/.neofs/dir"), os.ErrPermission)
+	})
+}
+
+func TestEndToEndOpenSSH(t *testing.T) {
+	sftpBin, err := exec.LookPath("sftp")
+	if err != nil {
+		t.Skip("openssh sftp client isn't installed")
+	}
+
+	s := startE2EServer(t)
+	dir := t.TempDir()
+
+	block, err := ssh.MarshalPrivateKey(s.clientKey, "")
+	require.NoError(t, err)
+	keyPath := filepath.Join(dir, "id_ed25519")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600))
+
+	content := []byte("uploaded by openssh\n")
+	local := filepath.Join(dir, "local.txt")
+	require.NoError(t, os.WriteFile(local, content, 0o600))
+
+	run := func(commands ...string) string {
+		host, port, err := net.SplitHostPort(s.address)
+		require.NoError(t, err)
+
+		cmd := exec.Command(sftpBin, "-b", "-", "-F", "/dev/null", "-i", keyPath, "-P", port,
+			"-o", "BatchMode=yes",
+			"-o", "StrictHostKeyChecking=no",
+			"-o", "UserKnownHostsFile=/dev/null",
+			e2eUser+"@"+host)
+		cmd.Stdin = strings.NewReader(strings.Join(commands, "\n") + "\n")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return string(out)
+	}
+
+	run("mkdir /docs", "put "+local+" /docs/file.txt")
+	require.Contains(t, run("ls /docs"), "file.txt")
+
+	downloaded := filepath.Join(dir, "downloaded.txt")
+	run("get /docs/" + s.objectID(t).EncodeToString() + " " + downloaded)
+	data, err := os.ReadFile(downloaded)
+	require.NoError(t, err)
+	require.Equal(t, content, data)
+
+	run("rename /docs/file.txt /docs/renamed.txt")
+	out := run("ls /docs")
+	require.Contains(t, out, "renamed.txt")
+	require.NotContains(t, out, "file.txt")
+
+	run("rm /docs/renamed.txt", "rmdir /docs")
+	require.NotContains(t, run("ls /"), "docs")
+}
+
+func TestEndToEndUploadResume(t *testing.T) {
+	s := startE2EServer(t)
+	c := s.dial(t)
+
+	content := make([]byte, 1<<20+4321)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+	require.NoError(t, c.Mkdir("/docs"))
+
+	download := func(t *testing.T) []byte {
+		f, err := c.Open("/docs/" + s.lastObjectID(t).EncodeToString())
+		require.NoError(t, err)
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		require.NoError(t, err)
+		return data
+	}
+	// Versions are ordered by timestamps in seconds, the next one is put in the next second.
+	nextSecond := func() {
+		time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	}
+
+	// The upload is interrupted after the first part.
+	f, err := c.OpenFile("/docs/data.bin", os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	require.NoError(t, err)
+	_, err = f.Write(content[:300000])
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	t.Run("offset", func(t *testing.T) {
+		nextSecond()
+		f, err := c.OpenFile("/docs/data.bin", os.O_WRONLY)
+		require.NoError(t, err)
+		_, err = f.Seek(300000, io.SeekStart)
+		require.NoError(t, err)
+		_, err = f.Write(content[300000:700000])
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		require.Equal(t, content[:700000], download(t))
+	})
+
+	t.Run("append", func(t *testing.T) {
+		nextSecond()
+		// The client writes from the start, the data goes after the file content.
+		f, err := c.OpenFile("/docs/data.bin", os.O_WRONLY|os.O_APPEND)
+		require.NoError(t, err)
+		_, err = f.Write(content[700000:])
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		require.Equal(t, content, download(t))
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		nextSecond()
+		f, err := c.OpenFile("/docs/data.bin", os.O_WRONLY|os.O_TRUNC)
+		require.NoError(t, err)
+		_, err = f.Write(content[:1000])
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		require.Equal(t, content[:1000], download(t))
+	})
+}
+
+func TestEndToEndSessionLimits(t *testing.T) {
+	s := startE2EServer(t)
+	s.limiter.setLimits(1, 0)
+
+	conn, err := s.sshDial(t)
+	require.NoError(t, err)
+
+	// The connection over the limit is closed before the handshake.
+	_, err = s.sshDial(t)
+	require.Error(t, err)
+
+	require.NoError(t, conn.Close())
+	require.Eventually(t, func() bool {
+		conn, err := s.sshDial(t)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Exit codes of the gateway. Failures after the start are logged and exit with exitFailure.
+const (
+	exitFailure = 1
+	// exitUsage is returned on invalid command line flags.
+	exitUsage = 2
+	// exitConfig is returned if the configuration can't be read or is invalid.
+	exitConfig = 3
+	// exitWallet is returned if the wallet or the private key can't be loaded.
+	exitWallet = 4
+	// exitNeoFS is returned if NeoFS can't be connected.
+	exitNeoFS = 5
+)
+
+// startupError is the error preventing the gateway from starting with the exit code and
+// the description of the failed step.
+type startupError struct {
+	code int
+	msg  string
+	err  error
+}
+
+func (e *startupError) Error() string {
+	return e.msg + ": " + e.err.Error()
+}
+
+func (e *startupError) Unwrap() error {
+	return e.err
+}
+
+// exitHooks run when the gateway exits on a fatal error, the deferred calls of main are
+// skipped then.
+var (
+	exitHooksMu sync.Mutex
+	exitHooks   []func()
+)
+
+// onExit registers f to be run on the exit on a fatal error.
+func onExit(f func()) {
+	exitHooksMu.Lock()
+	defer exitHooksMu.Unlock()
+	exitHooks = append(exitHooks, f)
+}
+
+// runExitHooks runs the registered hooks in the reverse order like deferred calls, once.
+func runExitHooks() {
+	exitHooksMu.Lock()
+	hooks := exitHooks
+	exitHooks = nil
+	exitHooksMu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
+	}
+}
+
+// fatalHook exits with exitFailure after running the exit hooks on logged fatal errors.
+type fatalHook struct{}
+
+func (fatalHook) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {
+	runExitHooks()
+	os.Exit(exitFailure)
+}
+
+func newStartupError(code int, msg string, err error) error {
+	return &startupError{code: code, msg: msg, err: err}
+}
+
+// exitOnError prints the error to stderr, logs it if the logger is set and exits with the code
+// of startupError or exitFailure. Logs may be disabled or written elsewhere, so the error is
+// always printed.
+func exitOnError(l *zap.Logger, err error, fields ...zap.Field) {
+	code, msg := exitFailure, "failed to start"
+	var startErr *startupError
+	if errors.As(err, &startErr) {
+		code, msg, err = startErr.code, startErr.msg, startErr.err
+	}
+
+	if l != nil {
+		l.Error(msg, append(fields, zap.Int("exit_code", code), zap.Error(err))...)
+		_ = l.Sync()
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s: %v\n", os.Args[0], msg, err)
+	runExitHooks()
+	os.Exit(code)
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunExitHooks(t *testing.T) {
+	var calls []int
+	onExit(func() { calls = append(calls, 1) })
+	onExit(func() { calls = append(calls, 2) })
+
+	runExitHooks()
+	require.Equal(t, []int{2, 1}, calls)
+
+	// The hooks run once.
+	runExitHooks()
+	require.Equal(t, []int{2, 1}, calls)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sftp-gw/handlers"
+	"github.com/pkg/sftp"
+	"go.uber.org/zap"
+)
+
+type exportJobConfig struct {
+	// Source is the gateway path: the container and the optional prefix, e.g. `/backups/host1`.
+	Source string
+	// Target is the local directory.
+	Target string
+}
+
+type exportConfig struct {
+	Jobs []exportJobConfig
+	// Interval of repeated exports, jobs run once on start if 0.
+	Interval time.Duration
+	// Standalone runs export jobs only, without serving SFTP.
+	Standalone bool
+}
+
+// exporter downloads the files of the container into the local directory. Files are read with
+// the SFTP handlers of the session, so they are processed as if they were downloaded by a client.
+type exporter struct {
+	log *zap.Logger
+	app *handlers.App
+	job exportJobConfig
+}
+
+// startExport runs the export jobs in background, every job is a separate session. The returned
+// channel is closed when all the jobs are finished: after the first run if there is no interval,
+// when ctx is done otherwise.
+func startExport(ctx context.Context, l *zap.Logger, app *handlers.App, conf exportConfig) <-chan struct{} {
+	var wg sync.WaitGroup
+	for _, job := range conf.Jobs {
+		ctx, cancel := context.WithCancel(ctx)
+		sess := app.StartSession("export", job.Target, func() error {
+			cancel()
+			return nil
+		})
+
+		e := &exporter{
+			log: sess.Log.With(zap.String("source", job.Source), zap.String("target", job.Target)),
+			app: sess,
+			job: job,
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer sess.EndSession()
+			defer cancel()
+			e.run(ctx, conf.Interval)
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	return done
+}
+
+func (e *exporter) run(ctx context.Context, interval time.Duration) {
+	for {
+		start := time.Now()
+		n, err := e.export(ctx)
+		if err != nil {
+			e.log.Error("export failed", zap.Int("files", n), zap.Error(err))
+		} else {
+			e.log.Info("export finished", zap.Int("files", n), zap.Duration("took", time.Since(start)))
+		}
+		if interval <= 0 {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// export downloads the files missing in the target directory or differing from the local ones
+// and returns the number of downloaded files. Local files missing in the container are kept.
+func (e *exporter) export(ctx context.Context) (int, error) {
+	remote, err := listTargetFiles(ctx, e.app, e.job.Source)
+	if err != nil {
+		return 0, fmt.Errorf("list source: %w", err)
+	}
+
+	var n int
+	for rel, obj := range remote {
+		if ctx.Err() != nil {
+			return n, ctx.Err()
+		}
+
+		local, err := exportLocalPath(e.job.Target, rel)
+		if err != nil {
+			e.log.Warn("file skipped", zap.String("file", rel), zap.Error(err))
+			continue
+		}
+		if info, err := os.Stat(local); err == nil && info.Size() == obj.Size() && info.ModTime().Equal(obj.ModTime()) {
+			continue
+		}
+
+		if err = e.download(ctx, obj, local); err != nil {
+			e.log.Error("failed to export file", zap.String("file", rel), zap.Error(err))
+			continue
+		}
+		n++
+		e.log.Debug("file exported", zap.String("file", rel), zap.Int64("size", obj.Size()))
+	}
+	return n, nil
+}
+
+// download writes the object to the temporary file replacing the local one when it's complete,
+// the local file gets the timestamp of the object.
+func (e *exporter) download(ctx context.Context, obj *handlers.ObjectInfo, local string) (err error) {
+	if err = os.MkdirAll(filepath.Dir(local), 0o755); err != nil {
+		return err
+	}
+
+	// Files are read by their object IDs as the gateway resolves them.
+	cnrName, _ := splitSyncTarget(e.job.Source)
+	r, err := e.app.Fileread(sftp.NewRequest("Get", gatewayPath(cnrName)+"/"+obj.ObjectID.EncodeToString()).WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	if closer, ok := r.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(local), "."+filepath.Base(local)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = io.Copy(tmp, io.NewSectionReader(r, 0, obj.Size())); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chtimes(tmp.Name(), obj.ModTime(), obj.ModTime()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), local)
+}
+
+// exportLocalPath returns the path of the exported file in the target directory,
+// file paths leaving the directory are refused.
+func exportLocalPath(target, rel string) (string, error) {
+	local := filepath.Join(target, filepath.FromSlash(rel))
+	inside, err := filepath.Rel(target, local)
+	if err != nil {
+		return "", err
+	}
+	if inside == "." || inside == ".." || strings.HasPrefix(inside, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %s is outside of the target directory", rel)
+	}
+	return local, nil
+}
+
+// checkExportTarget creates the target directory if it doesn't exist.
+func checkExportTarget(target string) error {
+	info, err := os.Stat(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return os.MkdirAll(target, 0o755)
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("target %s is not a directory", target)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sftp-gw/handlers"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func putFile(t *testing.T, app *handlers.App, path, content string) {
+	w, err := app.Filewrite(sftp.NewRequest("Put", path))
+	require.NoError(t, err)
+	_, err = w.WriteAt([]byte(content), 0)
+	require.NoError(t, err)
+	require.NoError(t, w.(io.Closer).Close())
+}
+
+func TestExportLocalPath(t *testing.T) {
+	local, err := exportLocalPath("/restore", "a/b.txt")
+	require.NoError(t, err)
+	require.Equal(t, "/restore/a/b.txt", local)
+
+	for _, rel := range []string{"../b.txt", "a/../../b.txt", ""} {
+		_, err = exportLocalPath("/restore", rel)
+		require.Error(t, err, rel)
+	}
+}
+
+func TestExport(t *testing.T) {
+	app := newMemoryApp(t, "backups")
+	putFile(t, app, "/backups/host1/a.txt", "alpha")
+	putFile(t, app, "/backups/host1/sub/b.txt", "beta")
+	putFile(t, app, "/backups/host2/c.txt", "gamma")
+
+	target := t.TempDir()
+	const source = "/backups/host1"
+	<-startExport(context.Background(), zap.NewNop(), app, exportConfig{
+		Jobs: []exportJobConfig{{Source: source, Target: target}},
+	})
+
+	remote, err := listTargetFiles(context.Background(), app, source)
+	require.NoError(t, err)
+	for rel, content := range map[string]string{"a.txt": "alpha", "sub/b.txt": "beta"} {
+		local := filepath.Join(target, filepath.FromSlash(rel))
+		data, err := os.ReadFile(local)
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+
+		// Files keep the timestamps of the objects.
+		info, err := os.Stat(local)
+		require.NoError(t, err)
+		require.True(t, info.ModTime().Equal(remote[rel].ModTime()), rel)
+	}
+	// Files out of the source prefix aren't exported.
+	_, err = os.Stat(filepath.Join(target, "c.txt"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+	entries, err := os.ReadDir(target)
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "no temporary files are left")
+
+	// Unchanged files aren't downloaded again, changed ones are replaced.
+	e := &exporter{log: zap.NewNop(), app: app, job: exportJobConfig{Source: source, Target: target}}
+	n, err := e.export(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	require.NoError(t, os.WriteFile(filepath.Join(target, "a.txt"), []byte("changed"), 0o600))
+	n, err = e.export(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	data, err := os.ReadFile(filepath.Join(target, "a.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "alpha", string(data))
+}
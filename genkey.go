@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/secrets"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/wallet"
+	"github.com/spf13/pflag"
+	"golang.org/x/crypto/ssh"
+)
+
+// runGenKey generates the host key of the built-in server. The key is written to dev.sshkey
+// and encrypted with dev.passphrase of the configuration unless they're set by the flags.
+func runGenKey(args []string, out io.Writer) error {
+	flags := pflag.NewFlagSet("genkey", pflag.ContinueOnError)
+	keyType := flags.String("type", "ed25519", "key type: ed25519 or ecdsa")
+	bits := flags.Int("bits", 256, "size of ECDSA key: 256, 384 or 521")
+	path := flags.StringP("out", "o", "", "path to the key, dev.sshkey of the configuration if not set")
+	passFile := flags.String("passphrase-file", "", "file with the passphrase of the key, dev.passphrase of the configuration if not set")
+	force := flags.Bool("force", false, "overwrite the existing key")
+
+	v, err := commandSettings(flags, args)
+	if err != nil {
+		return err
+	}
+	if *path == "" {
+		if *path = v.GetString(cfgDevSSHKey); *path == "" {
+			return errors.New("key path isn't set, use --out or dev.sshkey setting")
+		}
+	}
+	passphrase, err := secrets.Resolve(context.Background(), v.GetString(cfgDevSSHPassphrase))
+	if err != nil {
+		return err
+	}
+	if *passFile != "" {
+		if passphrase, err = wallet.ReadPassphraseFile(*passFile); err != nil {
+			return err
+		}
+	}
+
+	key, err := generateHostKey(*keyType, *bits)
+	if err != nil {
+		return err
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		return err
+	}
+
+	var block *pem.Block
+	if passphrase != "" {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(key, "", []byte(passphrase))
+	} else {
+		block, err = ssh.MarshalPrivateKey(key, "")
+	}
+	if err != nil {
+		return fmt.Errorf("encode key: %w", err)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(*path), 0o755); err != nil {
+		return err
+	}
+	flag := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if *force {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(*path, flag, 0o600)
+	if err != nil {
+		return err
+	}
+	if err = pem.Encode(f, block); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	pub := ssh.MarshalAuthorizedKey(signer.PublicKey())
+	if err = os.WriteFile(*path+".pub", pub, 0o644); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Host key is written to %s\nFingerprint: %s\n%s", *path,
+		ssh.FingerprintSHA256(signer.PublicKey()), pub)
+	return nil
+}
+
+func generateHostKey(keyType string, bits int) (crypto.Signer, error) {
+	switch keyType {
+	case "ed25519":
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	case "ecdsa":
+		var curve elliptic.Curve
+		switch bits {
+		case 256:
+			curve = elliptic.P256()
+		case 384:
+			curve = elliptic.P384()
+		case 521:
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported ECDSA key size %d", bits)
+		}
+		return ecdsa.GenerateKey(curve, rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", keyType)
+	}
+}
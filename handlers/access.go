@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"github.com/nspcc-dev/neofs-sdk-go/container"
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	neofscrypto "github.com/nspcc-dev/neofs-sdk-go/crypto"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/pkg/sftp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// aclTTL limits the time the container and its eACL are reused to check access.
+const aclTTL = 30 * time.Second
+
+// sharedCachePrefix is the prefix of keys kept in the SharedCache.
+const sharedCachePrefix = "neofs-sftp-gw:"
+
+type (
+	// SharedCache keeps values shared by several gateway instances, e.g. in Redis.
+	SharedCache interface {
+		// Get returns the value of the key, ok is false if there is no such key or it's expired.
+		Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+		// Set stores the value of the key for ttl.
+		Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+		// Delete removes the keys, missing ones are ignored.
+		Delete(ctx context.Context, keys ...string) error
+	}
+
+	// aclCache keeps the containers and their eACLs checked for access, it's shared by all
+	// users of the App. The entries are kept in the shared cache instead of the memory if
+	// it's set, so all gateway instances see the changes made by any of them at once.
+	aclCache struct {
+		mu         sync.Mutex
+		containers map[cid.ID]cachedACL
+		// shared is nil if the entries are kept in the memory.
+		shared SharedCache
+	}
+
+	cachedACL struct {
+		cnr     container.Container
+		fetched time.Time
+		// table is nil if the container has no eACL, it's valid if tableFetched isn't zero.
+		table        *eacl.Table
+		tableFetched time.Time
+	}
+)
+
+func newACLCache() *aclCache {
+	return &aclCache{containers: make(map[cid.ID]cachedACL)}
+}
+
+// SetSharedCache makes the containers and their eACLs checked for access shared by the gateway
+// instances using the same cache. It must be set before sessions are started.
+func (a *App) SetSharedCache(cache SharedCache) {
+	a.acls.shared = cache
+}
+
+func (c *aclCache) get(ctx context.Context, cnrID cid.ID) (cachedACL, bool) {
+	if c.shared != nil {
+		return c.sharedGet(ctx, cnrID)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.containers[cnrID]
+	if !ok || time.Since(entry.fetched) > aclTTL {
+		return cachedACL{}, false
+	}
+	if time.Since(entry.tableFetched) > aclTTL {
+		entry.tableFetched = time.Time{}
+	}
+	return entry, true
+}
+
+func (c *aclCache) setContainer(ctx context.Context, cnrID cid.ID, cnr container.Container) {
+	if c.shared != nil {
+		c.sharedSet(ctx, containerCacheKey(cnrID), cnr.Marshal())
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.containers[cnrID] = cachedACL{cnr: cnr, fetched: time.Now()}
+}
+
+func (c *aclCache) setTable(ctx context.Context, cnrID cid.ID, table *eacl.Table) {
+	if c.shared != nil {
+		// The container without eACL is kept as the empty value.
+		var data []byte
+		if table != nil {
+			var err error
+			if data, err = table.Marshal(); err != nil {
+				requestLogger(ctx).Debug("failed to encode eACL", zap.Stringer("container", cnrID), zap.Error(err))
+				return
+			}
+		}
+		c.sharedSet(ctx, eaclCacheKey(cnrID), data)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.containers[cnrID]; ok {
+		entry.table, entry.tableFetched = table, time.Now()
+		c.containers[cnrID] = entry
+	}
+}
+
+// remove drops the container changed by the gateway itself.
+func (c *aclCache) remove(ctx context.Context, cnrID cid.ID) {
+	if c.shared != nil {
+		if err := c.shared.Delete(ctx, containerCacheKey(cnrID), eaclCacheKey(cnrID)); err != nil {
+			requestLogger(ctx).Warn("failed to remove container from shared cache",
+				zap.Stringer("container", cnrID), zap.Error(err))
+		}
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.containers, cnrID)
+}
+
+// sharedGet returns the container and its eACL from the shared cache. Errors are logged and
+// treated as missing entries, the container is fetched from NeoFS then.
+func (c *aclCache) sharedGet(ctx context.Context, cnrID cid.ID) (cachedACL, bool) {
+	l := requestLogger(ctx).With(zap.Stringer("container", cnrID))
+
+	data, ok, err := c.shared.Get(ctx, containerCacheKey(cnrID))
+	if err != nil {
+		l.Debug("failed to get container from shared cache", zap.Error(err))
+	}
+	if !ok {
+		return cachedACL{}, false
+	}
+	var entry cachedACL
+	if err = entry.cnr.Unmarshal(data); err != nil {
+		l.Debug("failed to decode container from shared cache", zap.Error(err))
+		return cachedACL{}, false
+	}
+	entry.fetched = time.Now()
+
+	data, ok, err = c.shared.Get(ctx, eaclCacheKey(cnrID))
+	if err != nil {
+		l.Debug("failed to get eACL from shared cache", zap.Error(err))
+	}
+	if !ok {
+		return entry, true
+	}
+	if len(data) > 0 {
+		entry.table = new(eacl.Table)
+		if err = entry.table.Unmarshal(data); err != nil {
+			l.Debug("failed to decode eACL from shared cache", zap.Error(err))
+			entry.table = nil
+			return entry, true
+		}
+	}
+	entry.tableFetched = time.Now()
+	return entry, true
+}
+
+func (c *aclCache) sharedSet(ctx context.Context, key string, value []byte) {
+	if err := c.shared.Set(ctx, key, value, aclTTL); err != nil {
+		requestLogger(ctx).Debug("failed to put into shared cache", zap.String("key", key), zap.Error(err))
+	}
+}
+
+func containerCacheKey(cnrID cid.ID) string {
+	return sharedCachePrefix + "container:" + cnrID.EncodeToString()
+}
+
+func eaclCacheKey(cnrID cid.ID) string {
+	return sharedCachePrefix + "eacl:" + cnrID.EncodeToString()
+}
+
+var eaclOperations = map[acl.Op]eacl.Operation{
+	acl.OpObjectGet:    eacl.OperationGet,
+	acl.OpObjectHead:   eacl.OperationHead,
+	acl.OpObjectPut:    eacl.OperationPut,
+	acl.OpObjectDelete: eacl.OperationDelete,
+	acl.OpObjectSearch: eacl.OperationSearch,
+	acl.OpObjectRange:  eacl.OperationRange,
+	acl.OpObjectHash:   eacl.OperationRangeHash,
+}
+
+// checkAccess returns sftp.ErrSSHFxPermissionDenied if the container ACL denies the operation
+// to the gateway, so that clients don't find it out in the middle of the transfer. Operations
+// the ACL can't decide on without the object (eACL records with filters, bearer token rules)
+// are allowed, as well as all of them if the ACL can't be fetched: the network checks them anyway.
+// The container and its eACL are cached for aclTTL, so changes made by others apply with a delay.
+// Changes made by the gateway itself apply at once, on all instances if the cache is shared.
+func (a *App) checkAccess(ctx context.Context, cnrID cid.ID, op acl.Op) (err error) {
+	ctx, span := startSpan(ctx, "neofs.container.acl",
+		attribute.Stringer("neofs.container", cnrID),
+		attribute.Stringer("neofs.operation", op))
+	defer func() { endSpan(span, err) }()
+
+	l := requestLogger(ctx).With(zap.Stringer("container", cnrID))
+
+	cached, ok := a.acls.get(ctx, cnrID)
+	if !ok {
+		cached.cnr, err = a.layer().ContainerGet(ctx, cnrID, client.PrmContainerGet{})
+		if err != nil {
+			l.Debug("skip access check, failed to get container", zap.Error(err))
+			return nil
+		}
+		a.acls.setContainer(ctx, cnrID, cached.cnr)
+	}
+	cnr := cached.cnr
+
+	role, eaclRole := acl.RoleOthers, eacl.RoleOthers
+	if owner := cnr.Owner(); owner.Equals(*a.ownerFor(cnrID)) {
+		role, eaclRole = acl.RoleOwner, eacl.RoleUser
+	}
+
+	basic := cnr.BasicACL()
+	if a.bearerToken(cnrID) != nil && basic.AllowedBearerRules(op) {
+		return nil
+	}
+	if !basic.IsOpAllowed(op, role) {
+		return sftp.ErrSSHFxPermissionDenied
+	}
+	if !basic.Extendable() {
+		return nil
+	}
+
+	table := cached.table
+	if cached.tableFetched.IsZero() {
+		var fetched eacl.Table
+		fetched, err = a.layer().ContainerEACL(ctx, cnrID, client.PrmContainerEACL{})
+		switch {
+		case err == nil:
+			table = &fetched
+		case errors.Is(err, apistatus.ErrEACLNotFound):
+			// The container without eACL is cached as well.
+		default:
+			l.Debug("skip access check, failed to get eACL", zap.Error(err))
+			return nil
+		}
+		a.acls.setTable(ctx, cnrID, table)
+	}
+	if table == nil {
+		return nil
+	}
+
+	if eaclAction(*table, eaclOperations[op], eaclRole, neofscrypto.PublicKeyBytes(a.signerFor(cnrID).Public())) == eacl.ActionDeny {
+		return sftp.ErrSSHFxPermissionDenied
+	}
+	return nil
+}
+
+// eaclAction returns the action of the first eACL record applied to the operation of the sender,
+// eacl.ActionUnknown if there is no such record or it depends on the object.
+func eaclAction(table eacl.Table, op eacl.Operation, role eacl.Role, key []byte) eacl.Action {
+	for _, record := range table.Records() {
+		if record.Operation() != op || !eaclTargetMatches(record.Targets(), role, key) {
+			continue
+		}
+		if len(record.Filters()) > 0 {
+			return eacl.ActionUnknown
+		}
+		return record.Action()
+	}
+	return eacl.ActionUnknown
+}
+
+func eaclTargetMatches(targets []eacl.Target, role eacl.Role, key []byte) bool {
+	for _, target := range targets {
+		if target.Role() == role {
+			return true
+		}
+		for _, targetKey := range target.BinaryKeys() {
+			if bytes.Equal(targetKey, key) {
+				return true
+			}
+		}
+	}
+	return false
+}
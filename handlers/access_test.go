@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestEACLAction(t *testing.T) {
+	key := []byte{2, 1, 2, 3}
+	table := eacl.NewTable()
+
+	addRecord := func(action eacl.Action, op eacl.Operation, role eacl.Role, keys [][]byte, filtered bool) {
+		target := eacl.NewTarget()
+		target.SetRole(role)
+		target.SetBinaryKeys(keys)
+
+		record := eacl.CreateRecord(action, op)
+		eacl.AddRecordTarget(record, target)
+		if filtered {
+			record.AddObjectAttributeFilter(eacl.MatchStringEqual, "Type", "secret")
+		}
+		table.AddRecord(record)
+	}
+	addRecord(eacl.ActionDeny, eacl.OperationPut, eacl.RoleOthers, nil, false)
+	addRecord(eacl.ActionAllow, eacl.OperationPut, eacl.RoleUnknown, [][]byte{key}, false)
+	addRecord(eacl.ActionDeny, eacl.OperationGet, eacl.RoleOthers, nil, true)
+	addRecord(eacl.ActionDeny, eacl.OperationGet, eacl.RoleOthers, nil, false)
+
+	require.Equal(t, eacl.ActionDeny, eaclAction(*table, eacl.OperationPut, eacl.RoleOthers, nil))
+	require.Equal(t, eacl.ActionDeny, eaclAction(*table, eacl.OperationPut, eacl.RoleOthers, key), "first record is applied")
+	require.Equal(t, eacl.ActionAllow, eaclAction(*table, eacl.OperationPut, eacl.RoleUser, key))
+	require.Equal(t, eacl.ActionUnknown, eaclAction(*table, eacl.OperationPut, eacl.RoleUser, nil))
+	require.Equal(t, eacl.ActionUnknown, eaclAction(*table, eacl.OperationGet, eacl.RoleOthers, nil), "filtered record depends on object")
+	require.Equal(t, eacl.ActionUnknown, eaclAction(*table, eacl.OperationDelete, eacl.RoleOthers, nil))
+}
+
+func TestCheckAccessCache(t *testing.T) {
+	record := eacl.NewRecord()
+	record.SetOperation(eacl.OperationPut)
+	record.SetAction(eacl.ActionDeny)
+	eacl.AddFormedTarget(record, eacl.RoleUser)
+	table := eacl.NewTable()
+	table.AddRecord(record)
+
+	app, storage := newMemoryApp(t, &SftpServerConfig{EACL: table})
+	require.NoError(t, app.Filecmd(sftp.NewRequest("Mkdir", "/docs")))
+	cnr, err := app.getContainerByName(context.Background(), "docs")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.ErrorIs(t, app.checkAccess(ctx, cnr.CID, acl.OpObjectPut), sftp.ErrSSHFxPermissionDenied)
+	require.NoError(t, app.checkAccess(ctx, cnr.CID, acl.OpObjectGet))
+
+	// The container and its eACL are served from the cache.
+	storage.SetError("ContainerGet", errors.New("node is down"))
+	storage.SetError("ContainerEACL", errors.New("node is down"))
+	require.ErrorIs(t, app.checkAccess(ctx, cnr.CID, acl.OpObjectPut), sftp.ErrSSHFxPermissionDenied)
+
+	// Access isn't checked if the ACL can't be fetched.
+	app.acls.remove(ctx, cnr.CID)
+	require.NoError(t, app.checkAccess(ctx, cnr.CID, acl.OpObjectPut))
+}
+
+// memorySharedCache is the SharedCache of the test gateway instances, entries don't expire.
+type memorySharedCache struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func (c *memorySharedCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.values[key]
+	return value, ok, nil
+}
+
+func (c *memorySharedCache) Set(_ context.Context, key string, value []byte, _ time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] = value
+	return nil
+}
+
+func (c *memorySharedCache) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.values, key)
+	}
+	return nil
+}
+
+func TestCheckAccessSharedCache(t *testing.T) {
+	record := eacl.NewRecord()
+	record.SetOperation(eacl.OperationPut)
+	record.SetAction(eacl.ActionDeny)
+	eacl.AddFormedTarget(record, eacl.RoleUser)
+	table := eacl.NewTable()
+	table.AddRecord(record)
+
+	shared := &memorySharedCache{values: make(map[string][]byte)}
+	app, storage := newMemoryApp(t, &SftpServerConfig{EACL: table})
+	app.SetSharedCache(shared)
+	require.NoError(t, app.Filecmd(sftp.NewRequest("Mkdir", "/docs")))
+	cnr, err := app.getContainerByName(context.Background(), "docs")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.ErrorIs(t, app.checkAccess(ctx, cnr.CID, acl.OpObjectPut), sftp.ErrSSHFxPermissionDenied)
+	require.Len(t, shared.values, 2)
+
+	// Another instance gets the container and its eACL from the shared cache.
+	other := NewApp(storage, app.signer, app.owner, zap.NewNop(), app.config(), "REP 1")
+	other.SetSharedCache(shared)
+	storage.SetError("ContainerGet", errors.New("node is down"))
+	storage.SetError("ContainerEACL", errors.New("node is down"))
+	require.ErrorIs(t, other.checkAccess(ctx, cnr.CID, acl.OpObjectPut), sftp.ErrSSHFxPermissionDenied)
+	require.NoError(t, other.checkAccess(ctx, cnr.CID, acl.OpObjectGet))
+
+	// Changes made by one instance apply to the others at once.
+	app.acls.remove(ctx, cnr.CID)
+	require.Empty(t, shared.values)
+	require.NoError(t, other.checkAccess(ctx, cnr.CID, acl.OpObjectPut))
+}
@@ -1,27 +1,36 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
 	"github.com/nspcc-dev/neofs-sdk-go/client"
 	"github.com/nspcc-dev/neofs-sdk-go/container"
 	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
 	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
-	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
 	"github.com/nspcc-dev/neofs-sdk-go/object"
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
-	"github.com/nspcc-dev/neofs-sdk-go/pool"
 	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"github.com/nspcc-dev/neofs-sdk-go/waiter"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/layer"
 	"github.com/pkg/sftp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -34,12 +43,47 @@ type (
 	App struct {
 		Log *zap.Logger
 
-		pool                *pool.Pool
+		storage             *atomic.Pointer[storageRef]
 		owner               *user.ID
 		signer              user.Signer
-		sftConfig           *SftpServerConfig
-		maxObjectSize       uint64
+		sftConfig           *atomic.Pointer[SftpServerConfig]
 		defaultBucketPolicy string
+
+		// userName is the name of the SSH user served.
+		userName string
+		// session is nil for the App not bound to a session.
+		session *session
+		// sessions is shared by all users of the App.
+		sessions *sessions
+		// users are the transfer counters shared by all users of the App.
+		users *usersStats
+		// latencies are the NeoFS call histograms shared by all users of the App.
+		latencies *latencies
+		// uploadLimiter and downloadLimiter throttle the session, nil if not limited.
+		uploadLimiter   *rate.Limiter
+		downloadLimiter *rate.Limiter
+		// globalUpload and globalDownload are shared by all sessions.
+		globalUpload   *rate.Limiter
+		globalDownload *rate.Limiter
+		// transfers is shared by all users of the App.
+		transfers *transfers
+		// audit is nil if audit records aren't uploaded.
+		audit *auditLog
+		// onEvent is nil if events aren't handled.
+		onEvent func(Event)
+		// nns is nil if NNS domains aren't resolved.
+		nns NNSResolver
+		// usage is shared by all users of the App.
+		usage *usageCache
+		// netInfo is shared by all users of the App.
+		netInfo *netInfoCache
+		// acls are shared by all users of the App.
+		acls *aclCache
+		// balances are shared by all users of the App.
+		balances *balanceCache
+		// background is the work started by all users of the App and going on after
+		// their requests, e.g. mirror copies.
+		background *sync.WaitGroup
 	}
 
 	// SftpServerConfig is openssh sftp subsystem params.
@@ -47,66 +91,397 @@ type (
 		ReadOnly    bool
 		DebugStderr bool
 		DebugLevel  string
+
+		// ReadOnlyPaths are the patterns of paths (see matchPath) which can't be changed, e.g.
+		// "/datasets/**" makes the container and its files read-only.
+		ReadOnlyPaths []string
+
+		// FileMode and DirectoryMode are the permissions of files and directories shown
+		// to clients, 0777 is shown if they are 0.
+		FileMode      fs.FileMode
+		DirectoryMode fs.FileMode
+		// FileOwner is the uid and gid of files shown to clients, UserFileOwners overrides it
+		// for SSH users. Ownership isn't reported if it's zero.
+		FileOwner      FileOwner
+		UserFileOwners map[string]FileOwner
+
+		// SessionUploadRate and SessionDownloadRate limit bandwidth of every session in bytes
+		// per second, 0 means no limit.
+		SessionUploadRate   int64
+		SessionDownloadRate int64
+		// GlobalUploadRate and GlobalDownloadRate limit bandwidth of all sessions together.
+		GlobalUploadRate   int64
+		GlobalDownloadRate int64
+
+		// UserSigners maps SSH user name to the signer used instead of the gateway one,
+		// the user sees and creates containers of its account then.
+		UserSigners map[string]user.Signer
+
+		// ForeignOwners maps SSH user name to additional NeoFS owners whose containers
+		// are shown in the root listing along with the gateway owner ones.
+		ForeignOwners map[string][]user.ID
+
+		// BasicACL is set on containers created with Mkdir, UserBasicACL overrides it for SSH users.
+		BasicACL     acl.Basic
+		UserBasicACL map[string]acl.Basic
+		// EACL is the template of eACL set on containers created with Mkdir, nil if not needed.
+		// UserEACL overrides it for SSH users.
+		EACL     *eacl.Table
+		UserEACL map[string]*eacl.Table
+
+		// PolicyRules are checked in order to choose the placement policy of containers
+		// created with Mkdir, the default policy is used if none matches.
+		PolicyRules []PolicyRule
+		// PolicyAliases maps lower-case names usable instead of placement policies to the policies.
+		PolicyAliases map[string]string
+
+		// ContainerAttributes are set on containers created with Mkdir. UserContainerAttributes
+		// are added for SSH users, they override the common ones with the same keys.
+		ContainerAttributes     []Attribute
+		UserContainerAttributes map[string][]Attribute
+
+		// NNSZone is the zone of NNS domains registered for containers created with Mkdir,
+		// domains aren't registered if it's empty.
+		NNSZone string
+
+		// Mounts are shown in the root directory in addition to the owners' containers.
+		Mounts []Mount
+
+		// ExpirationRules are checked in order to set the expiration epoch of uploaded objects,
+		// objects don't expire if none matches.
+		ExpirationRules []ExpirationRule
+		// AttributeRules add attributes to uploaded objects, attributes of all the matching rules
+		// are added, the later ones override the earlier ones with the same keys.
+		AttributeRules []AttributeRule
+		// DirectoryDefaults are checked in order to set the default attributes, expiration and
+		// Content-Type of uploaded objects, the first matching ones are applied.
+		DirectoryDefaults []DirectoryDefaults
+
+		// RmdirMode defines how Rmdir of a container treats its objects.
+		RmdirMode RmdirMode
+
+		// HomomorphicHashing of containers created with Mkdir, PolicyRules may override it.
+		HomomorphicHashing HomomorphicHashing
+
+		// QuotaRules are checked in order to limit the size of containers, the usage is
+		// cached, so containers filled by other clients may exceed the quota.
+		QuotaRules []QuotaRule
+
+		// MirrorRules are checked in order to copy uploaded files into another container.
+		MirrorRules []MirrorRule
+
+		// InlinePolicy allows setting the placement policy in the name of the created directory.
+		InlinePolicy bool
+
+		// Trash defines whether removed files are kept in the trash directory.
+		Trash Trash
+
+		// ContainerWaiter defines how Mkdir waits for containers to be created.
+		ContainerWaiter ContainerWaiter
+
+		// Peers are the endpoints of the connected nodes shown in the network info directory.
+		Peers []string
+
+		// ForeignBalances adds the balances of ForeignOwners to the ones of the gateway owner.
+		ForeignBalances bool
+
+		// PutTimeout limits storing of uploaded files in NeoFS after they are closed, GetTimeout
+		// limits every read of downloaded files. There is no limit if they are 0.
+		PutTimeout time.Duration
+		GetTimeout time.Duration
+
+		// SlowThreshold is the duration above which SFTP requests and NeoFS calls are logged
+		// as slow, they aren't logged if it's 0.
+		SlowThreshold time.Duration
+
+		// TombstoneLifetime is the number of epochs tombstones of deleted objects are kept,
+		// the network default is used if 0.
+		TombstoneLifetime uint64
+	}
+
+	// AttributeRule adds the attributes to objects uploaded to the paths matching the pattern
+	// (see matchPath).
+	AttributeRule struct {
+		Pattern    string
+		Attributes []Attribute
 	}
 
+	// containerParams are settings of the container created with Mkdir.
+	containerParams struct {
+		policy     string
+		basicACL   acl.Basic
+		eacl       *eacl.Table
+		attributes []Attribute
+		// nnsZone is the zone of the NNS domain registered for the container, empty if not needed.
+		nnsZone            string
+		homomorphicHashing HomomorphicHashing
+	}
+
+	// Attribute is a key-value pair of NeoFS attribute.
+	Attribute struct {
+		Key   string
+		Value string
+	}
+
+	// PolicyRule maps container names matching the pattern (see path.Match) to the placement policy.
+	PolicyRule struct {
+		Pattern string
+		Policy  string
+		// HomomorphicHashing overrides the common setting if it's not empty.
+		HomomorphicHashing HomomorphicHashing
+	}
+
+	// HomomorphicHashing defines whether containers created with Mkdir have homomorphic hashing.
+	HomomorphicHashing string
+
 	// ListerAt is analogue io.ReaderAt for file info list.
 	ListerAt []os.FileInfo
 
 	objReader struct {
-		ctx    context.Context
-		file   *ObjectInfo
-		pool   *pool.Pool
-		signer user.Signer
+		ctx      context.Context
+		file     *ObjectInfo
+		layer    layer.Layer
+		signer   user.Signer
+		limiters []*rate.Limiter
+		session  *session
+		// bearer is attached to the requests, nil if not needed.
+		bearer *bearer.Token
+		// timeout limits every read, there is no limit if it's 0.
+		timeout time.Duration
+		// span is the request span ended on Close, nil if not traced.
+		span trace.Span
+		// release unregisters the file opened with the layer, nil if it isn't tracked.
+		release func()
 	}
 
 	objWriter struct {
-		ctx           context.Context
-		file          *ObjectInfo
-		pool          *pool.Pool
-		owner         *user.ID
-		signer        user.Signer
-		buffer        *os.File
-		maxObjectSize uint64
+		ctx       context.Context
+		cancel    context.CancelFunc
+		file      *ObjectInfo
+		layer     layer.Layer
+		owner     *user.ID
+		signer    user.Signer
+		buffer    *os.File
+		transfers *transfers
+		limiters  []*rate.Limiter
+		session   *session
+		// bearer is attached to the requests, nil if not needed.
+		bearer *bearer.Token
+		// expirationEpoch is the last epoch of the object, 0 if it doesn't expire.
+		expirationEpoch uint64
+		// attributes override the ones set by the gateway.
+		attributes []Attribute
+		// contentTypePolicy defines the Content-Type attribute.
+		contentTypePolicy ContentTypePolicy
+		// quota is the quota of the container, 0 if it's unlimited.
+		quota uint64
+		// usage keeps the space reserved by the upload in the container with the quota.
+		usage *usageCache
+		// reserved is the space reserved by the upload, it grows as the file is written
+		// by concurrent WriteAt calls.
+		reservedMu sync.Mutex
+		reserved   uint64
+		// timeout limits storing the object, there is no limit if it's 0.
+		timeout time.Duration
+		// span is the request span ended on Close, nil if not traced.
+		span trace.Span
+		// finish reports the upload result on Close, nil if not needed.
+		finish func(err error)
+		// transferErr is set if the connection is lost with the file still open.
+		transferErr error
+		// release unregisters the file opened with the layer, nil if it isn't tracked.
+		release func()
+		// appendOffset is added to the offsets of the writes to the file opened with the append
+		// flag, it's the size of the file content then. Clients write from the start, so the
+		// data goes after the content even if the writes are concurrent.
+		appendOffset int64
 	}
 )
 
-// NewApp creates handlers (implements sftp.FileReader, sftp.FileWriter, sftp.FileCmder, sftp.FileLister).
-func NewApp(conns *pool.Pool, signer user.Signer, owner *user.ID, l *zap.Logger, sftpConfig *SftpServerConfig,
-	maxObjectSize uint64, defaultBucketPolicy string) *App {
+// NewApp creates handlers (implements sftp.FileReader, sftp.FileWriter, sftp.FileCmder, sftp.FileLister)
+// working with the storage layer.
+func NewApp(storage layer.Layer, signer user.Signer, owner *user.ID, l *zap.Logger, sftpConfig *SftpServerConfig,
+	defaultBucketPolicy string) *App {
+	cfg := new(atomic.Pointer[SftpServerConfig])
+	cfg.Store(sftpConfig)
+
+	latencies := newLatencies()
+	latencies.slowThreshold.Store(int64(sftpConfig.SlowThreshold))
+
+	storagePtr := new(atomic.Pointer[storageRef])
+	storagePtr.Store(newStorageRef(storage, latencies))
+
+	globalUpload, globalDownload := rate.NewLimiter(rate.Inf, 0), rate.NewLimiter(rate.Inf, 0)
+	setRate(globalUpload, sftpConfig.GlobalUploadRate)
+	setRate(globalDownload, sftpConfig.GlobalDownloadRate)
+
 	return &App{
-		pool:                conns,
+		storage:             storagePtr,
 		signer:              signer,
 		owner:               owner,
 		Log:                 l,
-		sftConfig:           sftpConfig,
-		maxObjectSize:       maxObjectSize,
+		sftConfig:           cfg,
 		defaultBucketPolicy: defaultBucketPolicy,
+		transfers:           newTransfers(),
+		usage:               newUsageCache(),
+		netInfo:             new(netInfoCache),
+		acls:                newACLCache(),
+		balances:            newBalanceCache(),
+		background:          new(sync.WaitGroup),
+		sessions:            newSessions(),
+		users:               newUsersStats(),
+		latencies:           latencies,
+		globalUpload:        globalUpload,
+		globalDownload:      globalDownload,
+	}
+}
+
+// Shutdown stops accepting new uploads and waits for the active ones to be finished.
+// Uploads still running when ctx is done are aborted and their buffers are removed.
+// The background work of the uploads (e.g. mirror copies) is awaited until ctx is done.
+// Pending audit records are uploaded afterwards.
+func (a *App) Shutdown(ctx context.Context) {
+	defer a.stopAudit()
+
+	if aborted := a.transfers.drain(ctx); aborted > 0 {
+		a.Log.Warn("aborted unfinished uploads", zap.Int("count", aborted))
+		return
+	}
+	a.Log.Info("all uploads are finished")
+
+	done := make(chan struct{})
+	go func() {
+		a.background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		a.Log.Warn("background work of uploads is unfinished")
+	}
+}
+
+// goBackground runs the work started by the request and going on after it, EndSession
+// and Shutdown wait for it.
+func (a *App) goBackground(f func()) {
+	a.background.Add(1)
+	if a.session != nil {
+		a.session.background.Add(1)
 	}
+	go func() {
+		defer a.background.Done()
+		if a.session != nil {
+			defer a.session.background.Done()
+		}
+		f()
+	}()
+}
+
+// StartSession returns a copy of the App serving a new session of the SSH user connected from
+// the remote address. Terminate closes the session on demand, it may be nil if not supported.
+// The session is active until EndSession is called.
+func (a *App) StartSession(userName, remote string, terminate func() error) *App {
+	cfg := a.config()
+
+	userApp := *a
+	userApp.userName = strings.ToLower(userName)
+	userApp.uploadLimiter = newRateLimiter(cfg.SessionUploadRate)
+	userApp.downloadLimiter = newRateLimiter(cfg.SessionDownloadRate)
+	if signer, ok := cfg.UserSigners[userApp.userName]; ok {
+		owner := signer.UserID()
+		userApp.signer = signer
+		userApp.owner = &owner
+	}
+	userApp.session = &session{
+		id:        newID(),
+		user:      userName,
+		remote:    remote,
+		started:   time.Now(),
+		terminate: terminate,
+		stats:     a.users.get(userApp.userName),
+	}
+	userApp.session.stats.sessions.Add(1)
+	userApp.Log = a.Log.With(zap.String("session", userApp.session.id))
+	a.sessions.add(userApp.session)
+
+	return &userApp
+}
+
+// EndSession waits for the background work of the session requests and unregisters
+// the session of the App.
+func (a *App) EndSession() {
+	if a.session != nil {
+		a.session.background.Wait()
+		a.sessions.remove(a.session.id)
+	}
+}
+
+// Sessions returns active sessions sorted by start time.
+func (a *App) Sessions() []SessionInfo {
+	return a.sessions.list()
+}
+
+// TerminateSession closes the active session with the given ID.
+func (a *App) TerminateSession(id string) error {
+	sess, ok := a.sessions.get(id)
+	if !ok {
+		return ErrSessionNotFound
+	}
+	if sess.terminate == nil {
+		return errors.New("session can't be terminated")
+	}
+	return sess.terminate()
+}
+
+// UpdateConfig replaces server params, active sessions get them for subsequent requests.
+func (a *App) UpdateConfig(sftpConfig *SftpServerConfig) {
+	a.sftConfig.Store(sftpConfig)
+	a.latencies.slowThreshold.Store(int64(sftpConfig.SlowThreshold))
+	setRate(a.globalUpload, sftpConfig.GlobalUploadRate)
+	setRate(a.globalDownload, sftpConfig.GlobalDownloadRate)
+}
+
+func (a *App) config() *SftpServerConfig {
+	return a.sftConfig.Load()
+}
+
+// ReplaceLayer switches subsequent requests of all sessions to the storage layer and
+// returns the previous one. Files opened before keep using the previous layer, the returned
+// channel is closed once they're all closed.
+func (a *App) ReplaceLayer(storage layer.Layer) (layer.Layer, <-chan struct{}) {
+	old := a.storage.Swap(newStorageRef(storage, a.latencies))
+	return old.measuredLayer.Layer, old.retire()
+}
+
+func (a *App) layer() layer.Layer {
+	return a.storage.Load().measuredLayer
 }
 
-func newReader(ctx context.Context, obj *ObjectInfo, conn *pool.Pool, signer user.Signer) *objReader {
+func newReader(ctx context.Context, obj *ObjectInfo, storage layer.Layer, signer user.Signer) *objReader {
 	return &objReader{
 		ctx:    ctx,
 		file:   obj,
-		pool:   conn,
+		layer:  storage,
 		signer: signer,
 	}
 }
 
-func newWriter(ctx context.Context, obj *ObjectInfo, conn *pool.Pool, ownerID *user.ID, signer user.Signer, maxObjectSize uint64) (*objWriter, error) {
+func newWriter(ctx context.Context, obj *ObjectInfo, storage layer.Layer, ownerID *user.ID, signer user.Signer) (*objWriter, error) {
 	file, err := os.CreateTemp("", "sftpwriter")
 	if err != nil {
 		return nil, fmt.Errorf("CreateTemp: %w", err)
 	}
 
+	ctx, cancel := context.WithCancel(ctx)
+
 	return &objWriter{
-		ctx:           ctx,
-		file:          obj,
-		pool:          conn,
-		owner:         ownerID,
-		buffer:        file,
-		signer:        signer,
-		maxObjectSize: maxObjectSize,
+		ctx:    ctx,
+		cancel: cancel,
+		file:   obj,
+		layer:  storage,
+		owner:  ownerID,
+		buffer: file,
+		signer: signer,
 	}, nil
 }
 
@@ -123,48 +498,148 @@ func (f ListerAt) ListAt(ls []os.FileInfo, offset int64) (int, error) {
 	return n, nil
 }
 
-func (a *App) listObjects(ctx context.Context, cnrID cid.ID) ([]os.FileInfo, error) {
-	var result []os.FileInfo
+func (a *App) listObjects(ctx context.Context, cnr *ContainerInfo) ([]os.FileInfo, error) {
+	all, err := a.searchAllObjects(ctx, cnr.CID, "")
+	if err != nil {
+		return nil, err
+	}
+	objects, trashed := splitTrashed(all)
+
+	var (
+		result      []os.FileInfo
+		hasVersions bool
+	)
+	for _, versions := range groupVersions(objects) {
+		// The newest object is the current version of the file.
+		result = append(result, versions[0])
+		hasVersions = hasVersions || len(versions) > 1
+	}
+	if hasVersions {
+		result = append(result, versionsDirInfo(cnr))
+	}
+	if len(trashed) > 0 {
+		result = append(result, trashDirInfo(cnr))
+	}
+	// Listing is the whole container scan, so the usage is refreshed for free. The info file
+	// isn't listed to keep listings equal to the container content, it's served on direct access.
+	a.updateUsage(cnr.CID, all)
 
-	filters := object.NewSearchFilters()
-	filters.AddRootFilter()
+	return result, nil
+}
 
-	var prm client.PrmObjectSearch
-	prm.SetFilters(filters)
+// searchObjects returns all the root objects of the container except the trashed ones,
+// or only the ones with the file name if it's set.
+func (a *App) searchObjects(ctx context.Context, cnrID cid.ID, name string) ([]*ObjectInfo, error) {
+	objects, err := a.searchAllObjects(ctx, cnrID, name)
+	if err != nil {
+		return nil, err
+	}
+	files, _ := splitTrashed(objects)
+	return files, nil
+}
 
-	res, err := a.pool.ObjectSearchInit(ctx, cnrID, a.signer, prm)
+// searchAllObjects returns all the root objects of the container including the trashed ones,
+// or only the ones with the file name if it's set.
+func (a *App) searchAllObjects(ctx context.Context, cnrID cid.ID, name string) ([]*ObjectInfo, error) {
+	var (
+		ids []oid.ID
+		err error
+	)
+	if name == "" {
+		filters := object.NewSearchFilters()
+		filters.AddRootFilter()
+		ids, err = a.searchIDs(ctx, cnrID, filters)
+	} else {
+		ids, err = a.searchIDsByName(ctx, cnrID, name)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("init searching: %w", err)
+		return nil, err
 	}
-	defer res.Close()
 
-	existedFiles := make(map[string]struct{})
+	result := make([]*ObjectInfo, 0, len(ids))
+	for _, id := range ids {
+		obj, err := a.getObjectFile(ctx, newAddress(cnrID, id))
+		if err != nil {
+			return nil, err
+		}
+		if name == "" || obj.Name() == name {
+			result = append(result, obj)
+		}
+	}
 
-	var inErr error
-	var obj *ObjectInfo
+	return a.withoutExpired(ctx, result), nil
+}
 
-	err = res.Iterate(func(id oid.ID) bool {
-		obj, inErr = a.getObjectFile(ctx, newAddress(cnrID, id))
-		if err != nil {
-			return true
+// withoutExpired filters out the objects expired but not removed by the network yet,
+// they can't be read anymore.
+func (a *App) withoutExpired(ctx context.Context, objects []*ObjectInfo) []*ObjectInfo {
+	var expiring bool
+	for _, obj := range objects {
+		expiring = expiring || obj.ExpirationEpoch > 0
+	}
+	if !expiring {
+		return objects
+	}
+
+	ni, _, err := a.cachedNetworkInfo(ctx)
+	if err != nil {
+		requestLogger(ctx).Debug("failed to get current epoch, expired objects aren't filtered", zap.Error(err))
+		return objects
+	}
+
+	res := objects[:0]
+	for _, obj := range objects {
+		// Objects are available during their expiration epoch.
+		if obj.ExpirationEpoch == 0 || obj.ExpirationEpoch >= ni.CurrentEpoch() {
+			res = append(res, obj)
 		}
-		if _, ok := existedFiles[obj.Name()]; ok {
-			return false
+	}
+	return res
+}
+
+// searchIDsByName returns the IDs of the root objects with the file name. Other gateways name
+// files by FilePath, FileName is the last path element then, so objects are searched by FileName
+// only if there are none with the FilePath.
+func (a *App) searchIDsByName(ctx context.Context, cnrID cid.ID, name string) ([]oid.ID, error) {
+	for _, key := range []string{filePathAttribute, object.AttributeFileName} {
+		filters := object.NewSearchFilters()
+		filters.AddRootFilter()
+		filters.AddFilter(key, name, object.MatchStringEqual)
+
+		ids, err := a.searchIDs(ctx, cnrID, filters)
+		if err != nil || len(ids) > 0 {
+			return ids, err
 		}
-		existedFiles[obj.Name()] = struct{}{}
-		result = append(result, obj)
-		return false
-	})
-	if err == nil {
-		err = inErr
 	}
+	return nil, nil
+}
+
+// searchIDs returns the IDs of the container objects matching the filters.
+func (a *App) searchIDs(ctx context.Context, cnrID cid.ID, filters object.SearchFilters) (ids []oid.ID, err error) {
+	var prm client.PrmObjectSearch
+	if token := a.bearerToken(cnrID); token != nil {
+		prm.WithBearerToken(*token)
+	}
+
+	ctx, span := startSpan(ctx, "neofs.search", attribute.Stringer("neofs.container", cnrID))
+	defer func() { endSpan(span, err) }()
 
-	return result, err
+	err = a.layer().ObjectSearch(ctx, cnrID, a.signerFor(cnrID), prm, filters, func(id oid.ID) bool {
+		ids = append(ids, id)
+		return false
+	})
+	return ids, err
 }
 
 func (a *App) getObjectFile(ctx context.Context, address oid.Address) (*ObjectInfo, error) {
+	ctx, span := startSpan(ctx, "neofs.head", attribute.Stringer("neofs.address", address))
+
 	var prm client.PrmObjectHead
-	objMeta, err := a.pool.ObjectHead(ctx, address.Container(), address.Object(), a.signer, prm)
+	if token := a.bearerToken(address.Container()); token != nil {
+		prm.WithBearerToken(*token)
+	}
+	objMeta, err := a.layer().ObjectHead(ctx, address.Container(), address.Object(), a.signerFor(address.Container()), prm)
+	endSpan(span, err)
 	if err != nil {
 		return nil, err
 	}
@@ -176,7 +651,6 @@ func (a *App) getObjectFile(ctx context.Context, address oid.Address) (*ObjectIn
 		},
 		ObjectID:    address.Object(),
 		PayloadSize: int64(objMeta.PayloadSize()),
-		Created:     time.Now(),
 	}
 
 	for _, attr := range objMeta.Attributes() {
@@ -189,47 +663,73 @@ func (a *App) getObjectFile(ctx context.Context, address oid.Address) (*ObjectIn
 		if attr.Key() == object.AttributeFileName {
 			file.FileName = attr.Value()
 		}
+		if attr.Key() == object.AttributeExpirationEpoch {
+			file.ExpirationEpoch, _ = strconv.ParseUint(attr.Value(), 10, 64)
+		}
 		if attr.Key() == filePathAttribute {
-			file.FilePath = attr.Value()
+			file.FilePath = strings.TrimPrefix(attr.Value(), delimiter)
+		}
+		if attr.Key() == trashedAttribute {
+			if unix, err := strconv.ParseInt(attr.Value(), 10, 64); err == nil {
+				file.Trashed = time.Unix(unix, 0)
+			}
+		}
+	}
+	if file.FilePath != "" {
+		// The path relative to the container is the name of the file, as neofs-http-gw
+		// and neofs-s3-gw treat it.
+		file.FileName = file.FilePath
+	}
+
+	if file.Created.IsZero() {
+		// Objects uploaded without the timestamp get the time of their creation epoch,
+		// so they don't look modified on every listing.
+		if file.Created, err = a.epochTime(ctx, objMeta.CreationEpoch()); err != nil {
+			requestLogger(ctx).Debug("failed to get object creation time", zap.Stringer("address", address), zap.Error(err))
+			file.Created = time.Now()
 		}
 	}
 
 	return file, nil
 }
 
+// getObjectFileByName returns the current version of the file, the newest object with the name.
 func (a *App) getObjectFileByName(ctx context.Context, cnrID cid.ID, name string) (*ObjectInfo, error) {
-	filters := object.NewSearchFilters()
-	filters.AddRootFilter()
-	filters.AddFilter(object.AttributeFileName, name, object.MatchStringEqual)
-
-	var prm client.PrmObjectSearch
-	prm.SetFilters(filters)
-
-	res, err := a.pool.ObjectSearchInit(ctx, cnrID, a.signer, prm)
-	if err != nil {
-		return nil, fmt.Errorf("init searching: %w", err)
-	}
-	defer res.Close()
-
-	var objID *oid.ID
-	err = res.Iterate(func(id oid.ID) bool {
-		objID = &id
-		return true
-	})
+	objects, err := a.searchObjects(ctx, cnrID, name)
 	if err != nil {
 		return nil, err
 	}
 
-	if objID == nil {
+	if len(objects) == 0 {
 		return nil, fmt.Errorf("not found")
 	}
 
-	return a.getObjectFile(ctx, newAddress(cnrID, *objID))
+	return groupVersions(objects)[0][0], nil
+}
+
+// searchFirst returns the first object found or nil if there are no matching objects.
+func (a *App) searchFirst(ctx context.Context, cnrID cid.ID, filters object.SearchFilters) (objID *oid.ID, err error) {
+	ctx, span := startSpan(ctx, "neofs.search", attribute.Stringer("neofs.container", cnrID))
+	defer func() { endSpan(span, err) }()
+
+	var prm client.PrmObjectSearch
+	if token := a.bearerToken(cnrID); token != nil {
+		prm.WithBearerToken(*token)
+	}
+
+	err = a.layer().ObjectSearch(ctx, cnrID, a.signerFor(cnrID), prm, filters, func(id oid.ID) bool {
+		objID = &id
+		return true
+	})
+	return objID, err
 }
 
 func (a *App) getContainer(ctx context.Context, cnrID cid.ID) (*ContainerInfo, error) {
+	ctx, span := startSpan(ctx, "neofs.container.get", attribute.Stringer("neofs.container", cnrID))
+
 	var prm client.PrmContainerGet
-	cnr, err := a.pool.ContainerGet(ctx, cnrID, prm)
+	cnr, err := a.layer().ContainerGet(ctx, cnrID, prm)
+	endSpan(span, err)
 	if err != nil {
 		return nil, err
 	}
@@ -238,13 +738,15 @@ func (a *App) getContainer(ctx context.Context, cnrID cid.ID) (*ContainerInfo, e
 		FileName: cnrID.EncodeToString(),
 		CID:      cnrID,
 		Created:  time.Now(),
+		Foreign:  !cnr.Owner().Equals(*a.owner),
+		Policy:   formatPolicy(cnr.PlacementPolicy()),
 	}
 
 	if cnrName := cnr.Name(); len(cnrName) != 0 {
 		file.FileName = cnrName
 	}
 
-	if createdTime := cnr.CreatedAt(); createdTime.IsZero() {
+	if createdTime := cnr.CreatedAt(); !createdTime.IsZero() {
 		file.Created = createdTime
 	}
 
@@ -252,26 +754,13 @@ func (a *App) getContainer(ctx context.Context, cnrID cid.ID) (*ContainerInfo, e
 }
 
 func (a *App) listContainers(ctx context.Context) ([]os.FileInfo, error) {
-	var result []os.FileInfo
-
-	var prm client.PrmContainerList
-	containers, err := a.pool.ContainerList(ctx, *a.owner, prm)
+	containers, err := a.getContainers(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	existedFiles := make(map[string]struct{}, len(containers))
-
-	for _, CID := range containers {
-		cnr, err := a.getContainer(ctx, CID)
-		if err != nil {
-			return nil, err
-		}
-
-		if _, ok := existedFiles[cnr.Name()]; ok {
-			continue
-		}
-		existedFiles[cnr.Name()] = struct{}{}
+	result := make([]os.FileInfo, 0, len(containers))
+	for _, cnr := range containers {
 		result = append(result, cnr)
 	}
 	return result, nil
@@ -280,30 +769,52 @@ func (a *App) listContainers(ctx context.Context) ([]os.FileInfo, error) {
 func (a *App) getContainers(ctx context.Context) ([]*ContainerInfo, error) {
 	var result []*ContainerInfo
 
-	var prm client.PrmContainerList
-	containers, err := a.pool.ContainerList(ctx, *a.owner, prm)
-	if err != nil {
-		return nil, err
+	owners := append([]user.ID{*a.owner}, a.config().ForeignOwners[a.userName]...)
+
+	// Mounts take precedence over the containers with the same names. Broken mounts (e.g. removed
+	// containers) are skipped, so they don't hide the others.
+	mounts := a.config().Mounts
+	for i := range mounts {
+		cnr, err := a.getMountedContainer(ctx, &mounts[i])
+		if err != nil {
+			requestLogger(ctx).Warn("failed to get mounted container, it's skipped",
+				zap.String("mount", mounts[i].Name), zap.Stringer("container", mounts[i].Container), zap.Error(err))
+			continue
+		}
+		result = append(result, cnr)
 	}
 
-	existedFiles := make(map[string]struct{}, len(containers))
+	for _, owner := range owners {
+		listCtx, span := startSpan(ctx, "neofs.container.list", attribute.Stringer("neofs.owner", owner))
 
-	for _, CID := range containers {
-		cnr, err := a.getContainer(ctx, CID)
+		var prm client.PrmContainerList
+		containers, err := a.layer().ContainerList(listCtx, owner, prm)
+		endSpan(span, err)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("list containers of %s: %w", owner, err)
 		}
 
-		if _, ok := existedFiles[cnr.Name()]; ok {
-			continue
+		ownerContainers := make([]*ContainerInfo, 0, len(containers))
+		for _, CID := range containers {
+			cnr, err := a.getContainer(ctx, CID)
+			if err != nil {
+				return nil, err
+			}
+			ownerContainers = append(ownerContainers, cnr)
 		}
-		existedFiles[cnr.Name()] = struct{}{}
-		result = append(result, cnr)
+		sortContainers(ownerContainers)
+		result = append(result, ownerContainers...)
 	}
+
+	disambiguateNames(result)
 	return result, nil
 }
 
 func (a *App) getContainerByName(ctx context.Context, name string) (*ContainerInfo, error) {
+	if mount := a.mountByName(name); mount != nil {
+		return a.getMountedContainer(ctx, mount)
+	}
+
 	var cnrID cid.ID
 	if err := cnrID.DecodeString(name); err == nil {
 		return a.getContainer(ctx, cnrID)
@@ -320,21 +831,75 @@ func (a *App) getContainerByName(ctx context.Context, name string) (*ContainerIn
 		}
 	}
 
+	// Friendly names take precedence to keep the paths of the gateway containers stable.
+	if cnrID, ok, err := a.resolveContainerDomain(ctx, name); err != nil {
+		return nil, err
+	} else if ok {
+		return a.getContainer(ctx, cnrID)
+	}
+
 	return nil, fmt.Errorf("not found")
 }
 
 func (a *App) listPath(ctx context.Context, path string) ([]os.FileInfo, error) {
 	path = strings.TrimPrefix(path, delimiter)
 	if path == "" {
-		return a.listContainers(ctx)
+		result, err := a.listContainers(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return append(result, neofsDirInfo()), nil
+	}
+	if path == neofsDir {
+		return a.listNeofsDir(ctx)
+	}
+
+	path = withoutQuery(path)
+	if cnrName, rest, ok := strings.Cut(path, delimiter); ok {
+		if isQuery(rest) {
+			cnr, err := a.getListedContainer(ctx, cnrName)
+			if err != nil {
+				return nil, err
+			}
+			return a.listQuery(ctx, cnr, rest)
+		}
+		if versionPath, ok := versionsPath(rest); ok {
+			cnr, err := a.getListedContainer(ctx, cnrName)
+			if err != nil {
+				return nil, err
+			}
+			return a.listVersions(ctx, cnr, versionPath)
+		}
+		if rest == trashDir {
+			cnr, err := a.getListedContainer(ctx, cnrName)
+			if err != nil {
+				return nil, err
+			}
+			return a.listTrash(ctx, cnr)
+		}
 	}
 
-	cnr, err := a.getContainerByName(ctx, path)
+	cnr, err := a.getListedContainer(ctx, path)
 	if err != nil {
 		return nil, err
 	}
 
-	return a.listObjects(ctx, cnr.CID)
+	return a.listObjects(ctx, cnr)
+}
+
+// getListedContainer returns the container to list its objects. Containers of other owners
+// are listed if their ACL allows it, public ones for instance.
+func (a *App) getListedContainer(ctx context.Context, name string) (*ContainerInfo, error) {
+	cnr, err := a.getContainerByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if cnr.Foreign {
+		if err = a.checkAccess(ctx, cnr.CID, acl.OpObjectSearch); err != nil {
+			return nil, err
+		}
+	}
+	return cnr, nil
 }
 
 func (a *App) getFileStat(ctx context.Context, path string) (os.FileInfo, error) {
@@ -342,13 +907,29 @@ func (a *App) getFileStat(ctx context.Context, path string) (os.FileInfo, error)
 	if path == "" {
 		return &ContainerInfo{FileName: delimiter, Created: time.Now()}, nil
 	}
-	split := strings.Split(path, delimiter)
+	split := strings.Split(withoutQuery(path), delimiter)
+	if split[0] == neofsDir {
+		return a.neofsStat(ctx, strings.Join(split[1:], delimiter))
+	}
 
 	cnr, err := a.getContainerByName(ctx, split[0])
 	if err != nil {
 		return nil, err
 	}
 
+	if len(split) == 2 && isQuery(split[1]) {
+		return queryDirInfo(cnr, split[1])
+	}
+	if versionPath, ok := versionsPath(strings.Join(split[1:], delimiter)); ok {
+		return a.versionStat(ctx, cnr, versionPath)
+	}
+	if name, ok := trashPath(strings.Join(split[1:], delimiter)); ok {
+		return a.trashStat(ctx, cnr, name)
+	}
+	if len(split) == 2 && split[1] == infoFile {
+		return a.containerInfoFile(ctx, cnr)
+	}
+
 	if len(split) == 2 && len(split[1]) > 0 {
 		var id oid.ID
 		if err = id.DecodeString(split[1]); err != nil {
@@ -365,140 +946,489 @@ func (a *App) getFileStat(ctx context.Context, path string) (os.FileInfo, error)
 	return cnr, nil
 }
 
-func (a *App) deleteNeofsFile(ctx context.Context, path string) error {
-	path = strings.TrimPrefix(path, delimiter)
+func (a *App) deleteNeofsFile(ctx context.Context, fullPath string) error {
+	path := withoutQuery(strings.TrimPrefix(fullPath, delimiter))
 	split := strings.Split(path, delimiter)
 
 	cntr, err := a.getContainerByName(ctx, split[0])
 	if err != nil {
 		return err
 	}
-	if len(split) == 2 && split[1] != "" {
-		obj, err := a.getObjectFileByName(ctx, cntr.CID, split[1])
+	if versionPath, ok := versionsPath(strings.Join(split[1:], delimiter)); ok {
+		return a.deleteVersion(ctx, cntr, fullPath, versionPath)
+	}
+	if name, ok := trashPath(strings.Join(split[1:], delimiter)); ok {
+		if name == "" {
+			return sftp.ErrSSHFxPermissionDenied
+		}
+		return a.deleteTrashed(ctx, cntr, fullPath, name)
+	}
+	if name := strings.Join(split[1:], delimiter); name != "" {
+		obj, err := a.getObjectFileByName(ctx, cntr.CID, name)
 		if err != nil {
 			return err
 		}
+		if err = a.checkAccess(ctx, cntr.CID, acl.OpObjectDelete); err != nil {
+			return err
+		}
+		if a.config().Trash.Enabled {
+			return a.moveToTrash(ctx, cntr, obj, fullPath)
+		}
 
-		var prm client.PrmObjectDelete
+		err = a.deleteObject(ctx, newAddress(cntr.CID, obj.ObjectID))
+		if err == nil {
+			a.emitEvent(ctx, EventDelete, fullPath, cntr.CID, &obj.ObjectID, obj.PayloadSize)
+		}
+		return err
+	}
 
-		_, err = a.pool.ObjectDelete(ctx, cntr.CID, obj.ObjectID, a.signer, prm)
+	if cntr.Mounted || cntr.Foreign {
+		return sftp.ErrSSHFxPermissionDenied
+	}
+	if err = a.removeContainer(ctx, cntr.CID); err != nil {
 		return err
 	}
+	a.emitEvent(ctx, EventDelete, fullPath, cntr.CID, nil, 0)
+	return nil
+}
+
+func (a *App) deleteObject(ctx context.Context, address oid.Address) error {
+	ctx, span := startSpan(ctx, "neofs.delete", attribute.Stringer("neofs.address", address))
+	err := withSessionRenewal(requestLogger(ctx), func() error {
+		if lifetime := a.config().TombstoneLifetime; lifetime > 0 {
+			err := a.putTombstone(ctx, address, lifetime)
+			if !errors.Is(err, errNoLinkObject) {
+				return err
+			}
+			requestLogger(ctx).Debug("tombstone lifetime isn't applied", zap.Stringer("address", address), zap.Error(err))
+		}
+
+		var prm client.PrmObjectDelete
+		if token := a.bearerToken(address.Container()); token != nil {
+			prm.WithBearerToken(*token)
+		}
 
-	return a.deleteContainer(ctx, cntr.CID)
+		_, err := a.layer().ObjectDelete(ctx, address.Container(), address.Object(), a.signerFor(address.Container()), prm)
+		return err
+	})
+	endSpan(span, err)
+	return err
 }
 
 func (a *App) deleteContainer(ctx context.Context, cnrID cid.ID) error {
+	ctx, span := startSpan(ctx, "neofs.container.delete", attribute.Stringer("neofs.container", cnrID))
+
 	var prm client.PrmContainerDelete
-	return a.pool.ContainerDelete(ctx, cnrID, a.signer, prm)
+	err := a.layer().ContainerDelete(ctx, cnrID, a.signer, prm)
+	endSpan(span, err)
+	a.acls.remove(ctx, cnrID)
+	return err
 }
 
 // Filecmd called for Methods: Setstat, Rename, Rmdir, Mkdir, Link, Symlink, Remove.
-func (a *App) Filecmd(r *sftp.Request) error {
-	if a.sftConfig.ReadOnly {
+func (a *App) Filecmd(r *sftp.Request) (err error) {
+	a.session.setOperation(r.Method, r.Filepath)
+	ctx, span := a.startRequest(r)
+	defer func() {
+		if p := recover(); p != nil {
+			err = a.recoverRequest(ctx, r, p)
+		}
+		endSpan(span, err)
+		a.finishRequest(ctx, r, err)
+	}()
+
+	if a.isReadOnly(r.Filepath, r.Target) || isNeofsPath(r.Filepath) || isNeofsPath(r.Target) {
 		return sftp.ErrSSHFxPermissionDenied
 	}
 	switch r.Method {
 	case "Mkdir":
-		// valid Filepath "/somedir" or "somedir".
-		path := strings.TrimPrefix(r.Filepath, delimiter)
-		// invalid "/somedir/subdir", "somedir/subdir"
-		if parts := strings.Split(path, delimiter); len(parts) > 1 {
-			return fmt.Errorf("supported only first level dirs")
+		path, err := containerDirName(r.Filepath)
+		if err != nil {
+			return err
 		}
 
-		return a.putContainer(r.Context(), path, *a.owner, a.defaultBucketPolicy)
+		var policy string
+		if a.config().InlinePolicy {
+			path, policy = splitInlinePolicy(path, a.config().PolicyAliases)
+		}
+		params := a.newContainerParams(path)
+		if policy != "" {
+			params.policy = a.resolvePolicy(policy)
+		}
+
+		return a.putContainer(ctx, path, *a.owner, params)
 	case "Remove", "Rmdir":
-		err := a.deleteNeofsFile(r.Context(), r.Filepath)
-		return err
+		return a.deleteNeofsFile(ctx, r.Filepath)
+	case "Rename":
+		if isVersionPath(r.Filepath) {
+			return a.restoreVersion(ctx, r.Filepath, r.Target)
+		}
+		if isTrashPath(r.Filepath) {
+			return a.restoreTrashed(ctx, r.Filepath, r.Target)
+		}
+		return a.moveObject(ctx, r.Filepath, r.Target)
 	}
 
 	return nil
 }
 
-func (a *App) putContainer(ctx context.Context, name string, owner user.ID, policyStr string) error {
-	var policy netmap.PlacementPolicy
-	if err := policy.DecodeString(policyStr); err != nil {
-		return fmt.Errorf("invalid placement policy: %w", err)
+// policyRule returns the first policy rule matching the name of the new container, nil if none matches.
+func (a *App) policyRule(name string) *PolicyRule {
+	rules := a.config().PolicyRules
+	for i := range rules {
+		// Patterns are validated on configuration.
+		if ok, _ := path.Match(rules[i].Pattern, name); ok {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// resolvePolicy returns the placement policy the alias refers to, or the policy itself if it's not an alias.
+func (a *App) resolvePolicy(policy string) string {
+	if resolved, ok := a.config().PolicyAliases[strings.ToLower(policy)]; ok {
+		return resolved
+	}
+	return policy
+}
+
+// newContainerParams returns the settings of the container created by the session user.
+func (a *App) newContainerParams(name string) containerParams {
+	cfg := a.config()
+	params := containerParams{
+		policy:             a.resolvePolicy(a.defaultBucketPolicy),
+		basicACL:           cfg.BasicACL,
+		eacl:               cfg.EACL,
+		nnsZone:            cfg.NNSZone,
+		homomorphicHashing: cfg.HomomorphicHashing,
+	}
+
+	if rule := a.policyRule(name); rule != nil {
+		params.policy = a.resolvePolicy(rule.Policy)
+		if rule.HomomorphicHashing != "" {
+			params.homomorphicHashing = rule.HomomorphicHashing
+		}
+	}
+
+	if basicACL, ok := cfg.UserBasicACL[a.userName]; ok {
+		params.basicACL = basicACL
+	}
+	if table, ok := cfg.UserEACL[a.userName]; ok {
+		params.eacl = table
+	}
+
+	userAttrs := cfg.UserContainerAttributes[a.userName]
+	params.attributes = make([]Attribute, 0, len(cfg.ContainerAttributes)+len(userAttrs))
+	params.attributes = append(params.attributes, cfg.ContainerAttributes...)
+	params.attributes = append(params.attributes, userAttrs...)
+
+	return params
+}
+
+func (a *App) putContainer(ctx context.Context, name string, owner user.ID, params containerParams) error {
+	if params.eacl != nil && !params.basicACL.Extendable() {
+		requestLogger(ctx).Warn("basic ACL doesn't allow extended ACL, the template is ignored",
+			zap.String("basic_acl", params.basicACL.EncodeToString()))
+		params.eacl = nil
+	}
+
+	policy, err := DecodePolicy(params.policy)
+	if err != nil {
+		return err
 	}
 
 	var cnr container.Container
 	cnr.Init()
 	cnr.SetPlacementPolicy(policy)
-	cnr.SetBasicACL(acl.Private)
+	cnr.SetBasicACL(params.basicACL)
 	cnr.SetOwner(owner)
 
+	// Later attributes override the earlier ones, the name and the creation time can't be overridden.
+	for _, attr := range params.attributes {
+		cnr.SetAttribute(attr.Key, attr.Value)
+	}
 	cnr.SetName(name)
 	cnr.SetCreationTime(time.Now())
+	if err := a.setHomomorphicHashing(ctx, &cnr, params.homomorphicHashing); err != nil {
+		return err
+	}
+	if params.nnsZone != "" {
+		// The domain is registered by NeoFS when the container is saved.
+		var domain container.Domain
+		domain.SetName(name)
+		domain.SetZone(params.nnsZone)
+		cnr.WriteDomain(domain)
+	}
+
+	ctx, span := startSpan(ctx, "neofs.container.put", attribute.String("neofs.container_name", name))
 
 	var prm client.PrmContainerPut
-	w := waiter.NewContainerPutWaiter(a.pool, waiter.DefaultPollInterval)
+	wcfg := a.config().ContainerWaiter
+
+	if wcfg.Async {
+		cnrID, err := a.layer().ContainerPut(ctx, cnr, a.signer, prm)
+		endSpan(span, err)
+		if err != nil {
+			return fmt.Errorf("container put: %w", err)
+		}
 
-	if _, err := w.ContainerPut(ctx, cnr, a.signer, prm); err != nil {
+		requestLogger(ctx).Info("container creation is requested", zap.Stringer("container", cnrID))
+		if params.eacl != nil {
+			a.setEACLAsync(cnrID, params.eacl)
+		}
+		return nil
+	}
+
+	ctx, cancel := wcfg.waiterContext(ctx)
+	defer cancel()
+
+	w := waiter.NewContainerPutWaiter(a.layer(), wcfg.PollInterval)
+
+	cnrID, err := w.ContainerPut(ctx, cnr, a.signer, prm)
+	endSpan(span, err)
+	if err != nil {
 		return fmt.Errorf("container put: %w", err)
 	}
 
+	if params.eacl != nil {
+		return a.setEACL(ctx, cnrID, params.eacl)
+	}
+	return nil
+}
+
+// setEACL sets eACL made of the template on the container.
+func (a *App) setEACL(ctx context.Context, cnrID cid.ID, eaclTemplate *eacl.Table) error {
+	var table eacl.Table
+	eaclTemplate.CopyTo(&table)
+	table.SetCID(cnrID)
+
+	ctx, span := startSpan(ctx, "neofs.container.seteacl", attribute.Stringer("neofs.container", cnrID))
+
+	var prm client.PrmContainerSetEACL
+	w := waiter.NewContainerSetEACLWaiter(a.layer(), a.config().ContainerWaiter.PollInterval)
+
+	err := w.ContainerSetEACL(ctx, table, a.signer, prm)
+	endSpan(span, err)
+	a.acls.remove(ctx, cnrID)
+	if err != nil {
+		return fmt.Errorf("container set eACL: %w", err)
+	}
+
 	return nil
 }
 
 // Filewrite prepares io.WriterAt to upload files.
 // Called for Methods: Put, Open.
-func (a *App) Filewrite(r *sftp.Request) (io.WriterAt, error) {
-	if a.sftConfig.ReadOnly {
+func (a *App) Filewrite(r *sftp.Request) (_ io.WriterAt, err error) {
+	a.session.setOperation(r.Method, r.Filepath)
+	// The span lasts until the upload is finished on Close, the request is audited then as well.
+	ctx, span := a.startRequest(r)
+	defer func() {
+		if p := recover(); p != nil {
+			err = a.recoverRequest(ctx, r, p)
+		}
+		if err != nil {
+			endSpan(span, err)
+			a.finishRequest(ctx, r, err)
+		}
+	}()
+
+	if a.isReadOnly(r.Filepath) || isNeofsPath(r.Filepath) {
 		return nil, sftp.ErrSSHFxPermissionDenied
 	}
 	trimmed := strings.TrimPrefix(r.Filepath, delimiter)
 	split := strings.Split(trimmed, delimiter)
-	cnr, err := a.getContainerByName(r.Context(), split[0])
+	cnr, err := a.getContainerByName(ctx, split[0])
 	if err != nil {
 		return nil, err
 	}
 
+	relativePath := strings.TrimPrefix(trimmed, split[0]+delimiter)
+	if _, ok := versionsPath(relativePath); ok || relativePath == infoFile || isQuery(relativePath) || isTrashPath(r.Filepath) {
+		return nil, sftp.ErrSSHFxPermissionDenied
+	}
+	if err = a.checkAccess(ctx, cnr.CID, acl.OpObjectPut); err != nil {
+		return nil, err
+	}
 	obj := &ObjectInfo{
-		FileName:  strings.TrimPrefix(trimmed, split[0]+delimiter),
+		FileName:  relativePath,
+		FilePath:  relativePath,
 		Container: cnr,
 	}
 
-	w, err := newWriter(r.Context(), obj, a.pool, a.owner, a.signer, a.maxObjectSize)
+	quota, err := a.uploadQuota(ctx, cnr)
+	if err != nil {
+		return nil, err
+	}
+
+	var expirationEpoch uint64
+	if rule := a.expirationRule(r.Filepath); rule != nil {
+		if expirationEpoch, err = a.expirationEpoch(ctx, *rule); err != nil {
+			return nil, err
+		}
+	}
+
+	storage := a.storage.Load()
+	w, err := newWriter(ctx, obj, storage.measuredLayer, a.ownerFor(cnr.CID), a.signerFor(cnr.CID))
 	if err != nil {
 		return nil, fmt.Errorf("newWriter: %w", err)
 	}
+	w.release = storage.acquire()
+	w.expirationEpoch = expirationEpoch
+	w.quota = quota
+	w.usage = a.usage
+	if defaults := a.directoryDefaults(r.Filepath); defaults != nil {
+		w.attributes = append(w.attributes, defaults.Attributes...)
+		w.contentTypePolicy = defaults.ContentType
+	}
+	w.attributes = append(w.attributes, a.uploadAttributes(r.Filepath)...)
+
+	// A file opened without truncation keeps its content, clients resume uploads
+	// this way writing the rest at the offset or appending it.
+	if flags := r.Pflags(); flags.Append || flags.Write && !flags.Trunc {
+		size, err := a.loadCurrent(ctx, w, relativePath)
+		if err != nil {
+			w.abort()
+			return nil, err
+		}
+		if flags.Append {
+			w.appendOffset = size
+		}
+	}
+
+	if err = a.transfers.add(w); err != nil {
+		w.abort()
+		return nil, err
+	}
+	w.transfers = a.transfers
+	w.limiters = []*rate.Limiter{a.uploadLimiter, a.globalUpload}
+	w.session = a.session
+	w.bearer = a.bearerToken(cnr.CID)
+	w.timeout = a.config().PutTimeout
+	w.session.handleOpened()
+	w.span = span
+	w.finish = func(err error) {
+		a.finishRequest(ctx, r, err)
+		if err == nil {
+			a.usage.add(cnr.CID, uint64(obj.PayloadSize))
+			a.emitEvent(ctx, EventUpload, r.Filepath, cnr.CID, &obj.ObjectID, obj.PayloadSize)
+			a.mirrorUpload(ctx, cnr, obj)
+		}
+	}
 
 	return w, nil
 }
 
+// loadCurrent copies the payload of the current version of the file into the writer buffer
+// and returns its size, nothing is copied if there is no such file.
+func (a *App) loadCurrent(ctx context.Context, w *objWriter, name string) (int64, error) {
+	cnrID := w.file.Container.CID
+	objects, err := a.searchObjects(ctx, cnrID, name)
+	if err != nil || len(objects) == 0 {
+		return 0, err
+	}
+	current := groupVersions(objects)[0][0]
+	if err = a.checkAccess(ctx, cnrID, acl.OpObjectGet); err != nil {
+		return 0, err
+	}
+	if w.quota > 0 {
+		if err = w.reserve(uint64(current.PayloadSize)); err != nil {
+			return 0, err
+		}
+	}
+
+	var prm client.PrmObjectGet
+	if token := a.bearerToken(cnrID); token != nil {
+		prm.WithBearerToken(*token)
+	}
+	_, payload, err := w.layer.ObjectGet(ctx, cnrID, current.ObjectID, w.signer, prm)
+	if err != nil {
+		return 0, fmt.Errorf("get %s: %w", current.ObjectID, err)
+	}
+	defer func() { _ = payload.Close() }()
+
+	size, err := io.Copy(w.buffer, payload)
+	if err != nil {
+		return 0, fmt.Errorf("copy %s: %w", current.ObjectID, err)
+	}
+	return size, nil
+}
+
 // Fileread prepares io.ReaderAt to download file.
 // Called for Methods: Get.
-func (a *App) Fileread(r *sftp.Request) (io.ReaderAt, error) {
-	file, err := a.getFileStat(r.Context(), r.Filepath)
+func (a *App) Fileread(r *sftp.Request) (_ io.ReaderAt, err error) {
+	a.session.setOperation(r.Method, r.Filepath)
+	// The span lasts until the download is finished on Close.
+	ctx, span := a.startRequest(r)
+	defer func() {
+		if p := recover(); p != nil {
+			err = a.recoverRequest(ctx, r, p)
+		}
+		if err != nil {
+			endSpan(span, err)
+		}
+		a.finishRequest(ctx, r, err)
+	}()
+
+	file, err := a.getFileStat(ctx, r.Filepath)
 	if err != nil {
 		return nil, err
 	}
+	if vf, ok := file.(*virtualFile); ok {
+		endSpan(span, nil)
+		return bytes.NewReader(vf.content), nil
+	}
 
 	obj, ok := file.(*ObjectInfo)
 	if !ok {
 		return nil, fmt.Errorf("couldn't get file stat")
 	}
+	if err = a.checkAccess(ctx, obj.Container.CID, acl.OpObjectRange); err != nil {
+		return nil, err
+	}
 
-	return newReader(r.Context(), obj, a.pool, a.signer), nil
+	storage := a.storage.Load()
+	reader := newReader(ctx, obj, storage.measuredLayer, a.signerFor(obj.Container.CID))
+	reader.release = storage.acquire()
+	reader.limiters = []*rate.Limiter{a.downloadLimiter, a.globalDownload}
+	reader.session = a.session
+	reader.bearer = a.bearerToken(obj.Container.CID)
+	reader.timeout = a.config().GetTimeout
+	reader.session.handleOpened()
+	reader.span = span
+
+	return reader, nil
 }
 
 // Filelist returns files information.
 // Called for Methods: List, Stat, Readlink.
-func (a *App) Filelist(r *sftp.Request) (sftp.ListerAt, error) {
+func (a *App) Filelist(r *sftp.Request) (_ sftp.ListerAt, err error) {
+	a.session.setOperation(r.Method, r.Filepath)
+	ctx, span := a.startRequest(r)
+	defer func() {
+		if p := recover(); p != nil {
+			err = a.recoverRequest(ctx, r, p)
+		}
+		endSpan(span, err)
+		a.finishRequest(ctx, r, err)
+	}()
+
 	switch r.Method {
 	case "List":
-		files, err := a.listPath(r.Context(), r.Filepath)
+		files, err := a.listPath(ctx, r.Filepath)
 		if err != nil {
 			return nil, err
 		}
+		for i := range files {
+			files[i] = a.displayedInfo(files[i])
+		}
 		return ListerAt(files), nil
 	case "Stat":
-		stat, err := a.getFileStat(r.Context(), r.Filepath)
+		stat, err := a.getFileStat(ctx, r.Filepath)
 		if err != nil {
 			return nil, err
 		}
-		return ListerAt([]os.FileInfo{stat}), nil
+		return ListerAt([]os.FileInfo{a.displayedInfo(stat)}), nil
 	case "Readlink":
 	}
 
@@ -512,50 +1442,212 @@ func newAddress(cnrID cid.ID, objID oid.ID) oid.Address {
 	return addr
 }
 
-func (w *objWriter) Close() error {
+func newAttribute(key, value string) object.Attribute {
+	attr := object.NewAttribute()
+	attr.SetKey(key)
+	attr.SetValue(value)
+	return *attr
+}
+
+// abort cancels the upload and removes its buffer.
+func (w *objWriter) abort() {
+	w.cancel()
+	w.releaseQuota()
+	w.removeBuffer()
+	w.releaseLayer()
+}
+
+// releaseLayer unregisters the file opened with the layer.
+func (w *objWriter) releaseLayer() {
+	if w.release != nil {
+		w.release()
+	}
+}
+
+// reserve extends the space reserved by the upload up to the end of the written data.
+func (w *objWriter) reserve(end uint64) error {
+	w.reservedMu.Lock()
+	defer w.reservedMu.Unlock()
+	if end <= w.reserved {
+		return nil
+	}
+	if err := w.usage.reserve(w.file.Container.CID, end-w.reserved, w.quota); err != nil {
+		return fmt.Errorf("container %s: %w", w.file.Container.Name(), err)
+	}
+	w.reserved = end
+	return nil
+}
+
+// releaseQuota returns the space reserved by the upload, the stored object is accounted by then.
+func (w *objWriter) releaseQuota() {
+	w.reservedMu.Lock()
+	defer w.reservedMu.Unlock()
+	if w.reserved > 0 {
+		w.usage.release(w.file.Container.CID, w.reserved)
+		w.reserved = 0
+	}
+}
+
+func (w *objWriter) removeBuffer() {
+	if err := w.buffer.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+		requestLogger(w.ctx).Error("close tmp file", zap.String("file", w.buffer.Name()), zap.Error(err))
+	}
+	if err := os.Remove(w.buffer.Name()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		requestLogger(w.ctx).Error("remove tmp file", zap.String("file", w.buffer.Name()), zap.Error(err))
+	}
+}
+
+func (w *objWriter) Close() (err error) {
 	defer func() {
-		if err := os.Remove(w.buffer.Name()); err != nil {
-			zap.L().Error("remove tmp file", zap.String("file", w.buffer.Name()), zap.Error(err))
+		if w.span != nil {
+			endSpan(w.span, err)
+		}
+		if w.finish != nil {
+			w.finish(err)
+		}
+		w.releaseQuota()
+		w.session.handleClosed()
+		w.cancel()
+		w.removeBuffer()
+		w.releaseLayer()
+		if w.transfers != nil {
+			w.transfers.remove(w)
 		}
 	}()
 
-	attributes := make([]object.Attribute, 0, 2)
-	filename := object.NewAttribute()
-	filename.SetKey(object.AttributeFileName)
-	filename.SetValue(w.file.Name())
-
-	createdAt := object.NewAttribute()
-	createdAt.SetKey(object.AttributeTimestamp)
-	createdAt.SetValue(strconv.FormatInt(time.Now().UTC().Unix(), 10))
+	if w.transferErr != nil {
+		return fmt.Errorf("upload is interrupted: %w", w.transferErr)
+	}
 
-	attributes = append(attributes, *filename, *createdAt)
+	attributes := []object.Attribute{
+		newAttribute(object.AttributeFileName, path.Base(w.file.Name())),
+		newAttribute(object.AttributeTimestamp, strconv.FormatInt(time.Now().UTC().Unix(), 10)),
+	}
+	if w.file.FilePath != "" {
+		// Other gateways restore the directories of the file from its path.
+		attributes = append(attributes, newAttribute(filePathAttribute, w.file.FilePath))
+	}
+	if w.expirationEpoch > 0 {
+		attributes = append(attributes, newAttribute(object.AttributeExpirationEpoch, strconv.FormatUint(w.expirationEpoch, 10)))
+	}
+	contentType, err := w.contentType(w.contentTypePolicy)
+	if err != nil {
+		return fmt.Errorf("detect content type: %w", err)
+	}
+	if contentType != "" {
+		attributes = append(attributes, newAttribute(object.AttributeContentType, contentType))
+	}
+	for _, attr := range w.attributes {
+		attributes = setAttribute(attributes, attr.Key, attr.Value)
+	}
 
 	obj := object.New()
 	obj.SetOwnerID(w.owner)
 	obj.SetContainerID(w.file.Container.CID)
 	obj.SetAttributes(attributes...)
 
+	return withSessionRenewal(requestLogger(w.ctx), func() error {
+		return w.put(obj)
+	})
+}
+
+// uploadAttributes returns the attributes of all the rules matching the file path.
+func (a *App) uploadAttributes(filePath string) []Attribute {
+	var attrs []Attribute
+	for _, rule := range a.config().AttributeRules {
+		if matchPath(rule.Pattern, filePath) {
+			attrs = append(attrs, rule.Attributes...)
+		}
+	}
+	return attrs
+}
+
+// IsReservedAttribute reports whether the attribute is set by the gateway from the file itself
+// and can't be overridden by upload settings.
+func IsReservedAttribute(key string) bool {
+	switch key {
+	case object.AttributeFileName, filePathAttribute, object.AttributeTimestamp:
+		return true
+	}
+	return false
+}
+
+// setAttribute replaces the value of the attribute with the key or adds a new attribute.
+func setAttribute(attrs []object.Attribute, key, value string) []object.Attribute {
+	for i := range attrs {
+		if attrs[i].Key() == key {
+			attrs[i].SetValue(value)
+			return attrs
+		}
+	}
+	return append(attrs, newAttribute(key, value))
+}
+
+// TransferError is called by the server when the connection is lost before the file is closed,
+// so that the incomplete file isn't stored.
+func (w *objWriter) TransferError(err error) {
+	w.transferErr = err
+}
+
+func (w *objWriter) put(obj *object.Object) (err error) {
+	if _, err := w.buffer.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("seek buffer: %w", err)
+	}
+
+	ctx, span := startSpan(w.ctx, "neofs.put", attribute.Stringer("neofs.container", w.file.Container.CID))
+	defer func() { endSpan(span, err) }()
+	if w.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.timeout)
+		defer cancel()
+	}
+
 	var prm client.PrmObjectPutInit
+	if w.bearer != nil {
+		prm.WithBearerToken(*w.bearer)
+	}
 
-	writer, err := w.pool.ObjectPutInit(w.ctx, *obj, w.signer, prm)
+	info, err := w.buffer.Stat()
 	if err != nil {
-		return fmt.Errorf("ObjectPutInit: %w", err)
+		return fmt.Errorf("stat buffer: %w", err)
 	}
 
-	chunk := make([]byte, w.maxObjectSize)
-	if _, err = io.CopyBuffer(writer, w.buffer, chunk); err != nil {
-		return fmt.Errorf("CopyBuffer: %w", err)
+	id, err := w.layer.ObjectPut(ctx, *obj, w.signer, prm, w.buffer)
+	if err != nil {
+		return err
 	}
 
-	if err = writer.Close(); err != nil {
-		return fmt.Errorf("writer close: %w", err)
-	}
+	w.file.ObjectID = id
+	w.file.PayloadSize = info.Size()
 
-	return err
+	return nil
 }
 
 func (w *objWriter) WriteAt(p []byte, off int64) (n int, err error) {
-	return w.buffer.WriteAt(p, off)
+	off += w.appendOffset
+	if w.quota > 0 {
+		if err = w.reserve(uint64(off) + uint64(len(p))); err != nil {
+			return 0, err
+		}
+	}
+	if err = throttle(w.ctx, len(p), w.limiters...); err != nil {
+		return 0, err
+	}
+	n, err = w.buffer.WriteAt(p, off)
+	w.session.addUploaded(n)
+	return n, err
+}
+
+// Close releases the download handle.
+func (r *objReader) Close() error {
+	if r.span != nil {
+		r.span.End()
+	}
+	r.session.handleClosed()
+	if r.release != nil {
+		r.release()
+	}
+	return nil
 }
 
 func (r *objReader) ReadAt(b []byte, off int64) (n int, err error) {
@@ -575,16 +1667,45 @@ func (r *objReader) ReadAt(b []byte, off int64) (n int, err error) {
 
 	addr := newAddress(r.file.Container.CID, r.file.ObjectID)
 
-	var prm client.PrmObjectRange
+	ctx, span := startSpan(r.ctx, "neofs.range",
+		attribute.Stringer("neofs.address", addr),
+		attribute.Int64("neofs.offset", off),
+		attribute.Int64("neofs.length", int64(length)))
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	var res io.ReadCloser
+	err = withSessionRenewal(requestLogger(r.ctx), func() error {
+		var prm client.PrmObjectRange
+		if r.bearer != nil {
+			prm.WithBearerToken(*r.bearer)
+		}
 
-	res, err := r.pool.ObjectRangeInit(r.ctx, addr.Container(), addr.Object(), uint64(off), length, r.signer, prm)
+		res, err = r.layer.ObjectRange(ctx, addr.Container(), addr.Object(), uint64(off), length, r.signer, prm)
+		return err
+	})
 	if err != nil {
+		endSpan(span, err)
 		return 0, err
 	}
 
 	n, err = io.ReadFull(res, b)
+	_ = res.Close()
+	if n == int(length) {
+		// Reading less than the buffer size is expected at the end of the object.
+		endSpan(span, nil)
+	} else {
+		endSpan(span, err)
+	}
 	if n < len(b) {
 		err = io.EOF
 	}
+	if errThrottle := throttle(r.ctx, n, r.limiters...); errThrottle != nil {
+		return 0, errThrottle
+	}
+	r.session.addDownloaded(n)
 	return
 }
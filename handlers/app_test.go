@@ -19,6 +19,7 @@ import (
 	"github.com/nspcc-dev/neofs-sdk-go/pool"
 	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"github.com/nspcc-dev/neofs-sdk-go/waiter"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/layer"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -32,6 +33,9 @@ var (
 )
 
 func TestSftpHandlers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("neofs-aio container is needed")
+	}
 	rootCtx := context.Background()
 	key, err := keys.NewPrivateKeyFromHex("1dd37fba80fec4e6a6f13fd708d8dcb3b29def768017052f6c930fa1c5d90bbb")
 	require.NoError(t, err)
@@ -69,7 +73,7 @@ func testReader(ctx context.Context, t *testing.T, clientPool *pool.Pool, ownerI
 		PayloadSize: int64(len(content)),
 	}
 
-	reader := newReader(ctx, obj, clientPool, signer)
+	reader := newReader(ctx, obj, layer.NewNeoFS(clientPool), signer)
 
 	_, err := reader.ReadAt(nil, -1)
 	require.Error(t, err)
@@ -101,10 +105,7 @@ func testWriter(ctx context.Context, t *testing.T, clientPool *pool.Pool, ownerI
 		FileName: "write-test-object",
 	}
 
-	ni, err := clientPool.NetworkInfo(ctx, client.PrmNetworkInfo{})
-	require.NoError(t, err)
-
-	writer, err := newWriter(ctx, obj, clientPool, ownerID, signer, ni.MaxObjectSize())
+	writer, err := newWriter(ctx, obj, layer.NewNeoFS(clientPool), ownerID, signer)
 	require.NoError(t, err)
 
 	_, err = writer.WriteAt(nil, -1)
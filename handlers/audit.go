@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/pkg/sftp"
+	"go.uber.org/zap"
+)
+
+const (
+	// auditPreviousAttribute links the batch to the previous one uploaded by the gateway,
+	// so a removed or replaced batch breaks the chain.
+	auditPreviousAttribute = "AuditPrevious"
+
+	defaultAuditBatchSize     = 100
+	defaultAuditFlushInterval = time.Minute
+
+	// auditMaxPendingBatches limits records kept while the container is unavailable.
+	auditMaxPendingBatches = 100
+)
+
+type (
+	// AuditConfig sets up uploading of audit records into the NeoFS container.
+	AuditConfig struct {
+		Container cid.ID
+		// BatchSize is the number of records stored in a single object.
+		BatchSize int
+		// FlushInterval limits the time records wait for the batch to be filled.
+		FlushInterval time.Duration
+	}
+
+	// AuditRecord describes a single SFTP request.
+	AuditRecord struct {
+		Time    time.Time `json:"time"`
+		Session string    `json:"session,omitempty"`
+		Request string    `json:"request,omitempty"`
+		User    string    `json:"user,omitempty"`
+		Remote  string    `json:"remote,omitempty"`
+		Method  string    `json:"method"`
+		Path    string    `json:"path"`
+		Target  string    `json:"target,omitempty"`
+		Error   string    `json:"error,omitempty"`
+	}
+
+	// auditLog batches audit records and uploads them as objects.
+	auditLog struct {
+		log           *zap.Logger
+		batchSize     int
+		flushInterval time.Duration
+		upload        func(ctx context.Context, payload []byte, previous *oid.ID) (oid.ID, error)
+
+		mu       sync.Mutex
+		records  []AuditRecord
+		previous *oid.ID
+
+		flushCh chan struct{}
+		done    chan struct{}
+		stop    context.CancelFunc
+	}
+)
+
+// StartAudit starts uploading audit records of all sessions into the container until ctx is done
+// or the App is shut down.
+func (a *App) StartAudit(ctx context.Context, conf AuditConfig) {
+	l := newAuditLog(a.Log, conf, func(ctx context.Context, payload []byte, previous *oid.ID) (oid.ID, error) {
+		return a.putAuditBatch(ctx, conf.Container, payload, previous)
+	})
+
+	ctx, l.stop = context.WithCancel(ctx)
+	go l.run(ctx)
+
+	a.audit = l
+}
+
+func newAuditLog(log *zap.Logger, conf AuditConfig,
+	upload func(ctx context.Context, payload []byte, previous *oid.ID) (oid.ID, error)) *auditLog {
+	if conf.BatchSize <= 0 {
+		conf.BatchSize = defaultAuditBatchSize
+	}
+	if conf.FlushInterval <= 0 {
+		conf.FlushInterval = defaultAuditFlushInterval
+	}
+
+	return &auditLog{
+		log:           log,
+		batchSize:     conf.BatchSize,
+		flushInterval: conf.FlushInterval,
+		upload:        upload,
+		flushCh:       make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+}
+
+// auditRequest records the result of the SFTP request if audit is enabled.
+func (a *App) auditRequest(ctx context.Context, r *sftp.Request, err error) {
+	l := a.audit
+	if l == nil {
+		return
+	}
+
+	rec := AuditRecord{
+		Time:    time.Now().UTC(),
+		Request: requestID(ctx),
+		Method:  r.Method,
+		Path:    r.Filepath,
+		Target:  r.Target,
+	}
+	if a.session != nil {
+		rec.Session = a.session.id
+		rec.User = a.session.user
+		rec.Remote = a.session.remote
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+
+	l.add(rec)
+}
+
+// stopAudit uploads pending records and stops the audit log.
+func (a *App) stopAudit() {
+	if l := a.audit; l != nil {
+		l.stop()
+		<-l.done
+	}
+}
+
+func (a *App) putAuditBatch(ctx context.Context, cnrID cid.ID, payload []byte, previous *oid.ID) (oid.ID, error) {
+	now := time.Now().UTC()
+
+	attributes := make([]object.Attribute, 0, 4)
+	attributes = append(attributes,
+		newAttribute(object.AttributeFileName, "audit-"+now.Format("20060102T150405.000000000Z")+".jsonl"),
+		newAttribute(object.AttributeTimestamp, strconv.FormatInt(now.Unix(), 10)),
+		newAttribute(object.AttributeContentType, "application/x-ndjson"),
+	)
+	if previous != nil {
+		attributes = append(attributes, newAttribute(auditPreviousAttribute, previous.EncodeToString()))
+	}
+
+	obj := object.New()
+	obj.SetOwnerID(a.owner)
+	obj.SetContainerID(cnrID)
+	obj.SetAttributes(attributes...)
+
+	var id oid.ID
+	err := withSessionRenewal(a.Log, func() error {
+		var prm client.PrmObjectPutInit
+
+		var err error
+		id, err = a.layer().ObjectPut(ctx, *obj, a.signer, prm, bytes.NewReader(payload))
+		return err
+	})
+
+	return id, err
+}
+
+func (l *auditLog) add(rec AuditRecord) {
+	l.mu.Lock()
+	l.records = append(l.records, rec)
+	full := len(l.records) >= l.batchSize
+	l.mu.Unlock()
+
+	if full {
+		select {
+		case l.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (l *auditLog) run(ctx context.Context) {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// The parent context is done, so the last upload gets its own deadline.
+			flushCtx, cancel := context.WithTimeout(context.Background(), l.flushInterval)
+			l.flush(flushCtx)
+			cancel()
+			return
+		case <-ticker.C:
+		case <-l.flushCh:
+		}
+		l.flush(ctx)
+	}
+}
+
+// flush uploads pending records batch by batch. Records failed to be uploaded are kept for
+// the next attempt unless there are too many of them.
+func (l *auditLog) flush(ctx context.Context) {
+	for {
+		l.mu.Lock()
+		n := len(l.records)
+		if n > l.batchSize {
+			n = l.batchSize
+		}
+		batch := l.records[:n]
+		previous := l.previous
+		l.mu.Unlock()
+
+		if len(batch) == 0 {
+			return
+		}
+
+		var payload bytes.Buffer
+		enc := json.NewEncoder(&payload)
+		for i := range batch {
+			if err := enc.Encode(batch[i]); err != nil {
+				l.log.Error("encode audit record", zap.Error(err))
+			}
+		}
+
+		id, err := l.upload(ctx, payload.Bytes(), previous)
+		if err != nil {
+			l.log.Error("failed to upload audit records", zap.Int("count", len(batch)), zap.Error(err))
+			l.dropOverflow()
+			return
+		}
+
+		l.mu.Lock()
+		l.records = l.records[n:]
+		l.previous = &id
+		l.mu.Unlock()
+
+		l.log.Debug("audit records uploaded", zap.Int("count", n), zap.Stringer("object", id))
+	}
+}
+
+// dropOverflow removes the oldest records exceeding the pending limit.
+func (l *auditLog) dropOverflow() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limit := l.batchSize * auditMaxPendingBatches; len(l.records) > limit {
+		dropped := len(l.records) - limit
+		l.records = l.records[dropped:]
+		l.log.Warn("audit records dropped", zap.Int("count", dropped))
+	}
+}
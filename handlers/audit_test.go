@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAuditLogFlush(t *testing.T) {
+	type upload struct {
+		records  []AuditRecord
+		previous *oid.ID
+	}
+
+	var (
+		uploads []upload
+		fail    bool
+	)
+	l := newAuditLog(zap.NewNop(), AuditConfig{BatchSize: 2, FlushInterval: time.Hour},
+		func(_ context.Context, payload []byte, previous *oid.ID) (oid.ID, error) {
+			if fail {
+				return oid.ID{}, errors.New("unavailable")
+			}
+
+			var up upload
+			dec := json.NewDecoder(bytes.NewReader(payload))
+			for dec.More() {
+				var rec AuditRecord
+				require.NoError(t, dec.Decode(&rec))
+				up.records = append(up.records, rec)
+			}
+			up.previous = previous
+			uploads = append(uploads, up)
+			return oidtest.ID(), nil
+		})
+
+	for _, path := range []string{"/a", "/b", "/c"} {
+		l.add(AuditRecord{Method: "Put", Path: path})
+	}
+
+	fail = true
+	l.flush(context.Background())
+	require.Empty(t, uploads)
+	require.Len(t, l.records, 3)
+
+	fail = false
+	l.flush(context.Background())
+	require.Len(t, uploads, 2)
+	require.Empty(t, l.records)
+
+	require.Len(t, uploads[0].records, 2)
+	require.Equal(t, "/a", uploads[0].records[0].Path)
+	require.Nil(t, uploads[0].previous)
+
+	require.Len(t, uploads[1].records, 1)
+	require.Equal(t, "/c", uploads[1].records[0].Path)
+	require.NotNil(t, uploads[1].previous)
+}
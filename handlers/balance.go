@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"go.uber.org/zap"
+)
+
+// DefaultBalanceRefreshInterval is the default interval of account balance refreshing.
+const DefaultBalanceRefreshInterval = 10 * time.Minute
+
+type (
+	// Balance is the NeoFS accounting balance of the account.
+	Balance struct {
+		Value     int64
+		Precision uint32
+		Updated   time.Time
+	}
+
+	// balanceCache keeps the balances of the gateway accounts by their addresses,
+	// it's shared by all users of the App.
+	balanceCache struct {
+		mu       sync.Mutex
+		accounts map[string]Balance
+	}
+)
+
+func newBalanceCache() *balanceCache {
+	return &balanceCache{accounts: make(map[string]Balance)}
+}
+
+// String formats the balance as a decimal number, e.g. 12.5.
+func (b Balance) String() string {
+	if b.Precision == 0 {
+		return strconv.FormatInt(b.Value, 10)
+	}
+
+	digits := strconv.FormatUint(absInt64(b.Value), 10)
+	if pad := int(b.Precision) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	point := len(digits) - int(b.Precision)
+	res := strings.TrimRight(digits[:point]+"."+digits[point:], "0")
+	res = strings.TrimSuffix(res, ".")
+	if b.Value < 0 {
+		res = "-" + res
+	}
+	return res
+}
+
+// Float returns the approximate balance value.
+func (b Balance) Float() float64 {
+	return float64(b.Value) / math.Pow10(int(b.Precision))
+}
+
+func absInt64(v int64) uint64 {
+	if v < 0 {
+		return uint64(-(v + 1)) + 1
+	}
+	return uint64(v)
+}
+
+// Balances returns the cached balances of the gateway accounts by their addresses.
+func (a *App) Balances() map[string]Balance {
+	a.balances.mu.Lock()
+	defer a.balances.mu.Unlock()
+	res := make(map[string]Balance, len(a.balances.accounts))
+	for account, b := range a.balances.accounts {
+		res[account] = b
+	}
+	return res
+}
+
+// balanceAccounts returns the accounts whose balances are watched: the gateway owner and
+// the foreign owners of all the users if ForeignBalances is set.
+func (a *App) balanceAccounts() []user.ID {
+	accounts := []user.ID{*a.owner}
+	if !a.config().ForeignBalances {
+		return accounts
+	}
+
+	seen := map[string]struct{}{a.owner.EncodeToString(): {}}
+	for _, owners := range a.config().ForeignOwners {
+		for _, owner := range owners {
+			if _, ok := seen[owner.EncodeToString()]; !ok {
+				seen[owner.EncodeToString()] = struct{}{}
+				accounts = append(accounts, owner)
+			}
+		}
+	}
+	return accounts
+}
+
+// StartBalanceRefresh refreshes the balances of the gateway accounts now and then with the interval
+// until ctx is done. Empty balances are reported as warnings since container operations fail then.
+func (a *App) StartBalanceRefresh(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultBalanceRefreshInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			for _, account := range a.balanceAccounts() {
+				b, err := a.refreshBalance(ctx, account)
+				if err != nil {
+					a.Log.Warn("failed to refresh balance", zap.Stringer("account", account), zap.Error(err))
+					continue
+				}
+				if b.Value <= 0 {
+					a.Log.Warn("account balance is empty", zap.Stringer("account", account), zap.Stringer("balance", b))
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (a *App) refreshBalance(ctx context.Context, account user.ID) (_ Balance, err error) {
+	ctx, span := startSpan(ctx, "neofs.balance")
+	defer func() { endSpan(span, err) }()
+
+	var prm client.PrmBalanceGet
+	prm.SetAccount(account)
+	d, err := a.layer().BalanceGet(ctx, prm)
+	if err != nil {
+		return Balance{}, fmt.Errorf("get balance: %w", err)
+	}
+
+	b := Balance{Value: d.Value(), Precision: d.Precision(), Updated: time.Now()}
+	a.balances.mu.Lock()
+	a.balances.accounts[account.EncodeToString()] = b
+	a.balances.mu.Unlock()
+	return b, nil
+}
+
+// accountBalance returns the cached balance of the account, it's requested if not cached yet.
+func (a *App) accountBalance(ctx context.Context, account user.ID) (Balance, error) {
+	a.balances.mu.Lock()
+	b, ok := a.balances.accounts[account.EncodeToString()]
+	a.balances.mu.Unlock()
+	if ok {
+		return b, nil
+	}
+	return a.refreshBalance(ctx, account)
+}
+
+// balanceFile returns the file of the network info directory with the balances of the gateway
+// owner and the foreign owners of the session user if ForeignBalances is set.
+func (a *App) balanceFile(ctx context.Context) (*virtualFile, error) {
+	accounts := []user.ID{*a.owner}
+	if a.config().ForeignBalances {
+		accounts = append(accounts, a.config().ForeignOwners[a.userName]...)
+	}
+
+	var (
+		buf     bytes.Buffer
+		updated time.Time
+	)
+	for _, account := range accounts {
+		b, err := a.accountBalance(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "%s: %s\n", account, b)
+		if b.Updated.After(updated) {
+			updated = b.Updated
+		}
+	}
+
+	return &virtualFile{name: "balance", content: buf.Bytes(), modTime: updated}, nil
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceString(t *testing.T) {
+	for _, tc := range []struct {
+		balance Balance
+		res     string
+	}{
+		{Balance{Value: 0, Precision: 8}, "0"},
+		{Balance{Value: 1250000000, Precision: 8}, "12.5"},
+		{Balance{Value: 1, Precision: 8}, "0.00000001"},
+		{Balance{Value: -150, Precision: 2}, "-1.5"},
+		{Balance{Value: 42}, "42"},
+		{Balance{Value: math.MinInt64, Precision: 1}, "-922337203685477580.8"},
+	} {
+		require.Equal(t, tc.res, tc.balance.String())
+	}
+	require.InDelta(t, 12.5, Balance{Value: 1250000000, Precision: 8}.Float(), 1e-9)
+}
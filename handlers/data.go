@@ -15,6 +15,18 @@ type (
 		CID      cid.ID
 		FileName string
 		Created  time.Time
+		// Mounted is true for the containers of configured mounts.
+		Mounted bool
+		// Foreign is true for the containers of other owners accessed by their IDs.
+		Foreign bool
+		// Policy is the placement policy in the readable form, one clause per line.
+		Policy string
+	}
+
+	// ContainerSys is returned by Sys() of containers.
+	ContainerSys struct {
+		CID    cid.ID
+		Policy string
 	}
 
 	// ObjectInfo contains neofs object data.
@@ -26,6 +38,10 @@ type (
 		FileName    string
 		PayloadSize int64
 		Created     time.Time
+		// ExpirationEpoch is the last epoch of the object, 0 if it doesn't expire.
+		ExpirationEpoch uint64
+		// Trashed is the time the file was moved to the trash, zero if it wasn't.
+		Trashed time.Time
 	}
 )
 
@@ -49,8 +65,12 @@ func (t *ContainerInfo) IsDir() bool {
 	return true
 }
 
+// Sys returns *ContainerSys, nil for virtual directories not backed by containers.
 func (t *ContainerInfo) Sys() any {
-	return nil
+	if t.Policy == "" {
+		return nil
+	}
+	return &ContainerSys{CID: t.CID, Policy: t.Policy}
 }
 
 func (t *ObjectInfo) Name() string {
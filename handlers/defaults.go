@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// ContentTypePolicy defines how the Content-Type attribute of uploaded objects is set.
+type ContentTypePolicy string
+
+const (
+	// ContentTypeDetect detects the type by the file extension, or by its content if the
+	// extension is unknown.
+	ContentTypeDetect ContentTypePolicy = "detect"
+	// ContentTypeExtension detects the type by the file extension only, unknown ones are
+	// application/octet-stream.
+	ContentTypeExtension ContentTypePolicy = "extension"
+	// ContentTypeNone doesn't set the attribute.
+	ContentTypeNone ContentTypePolicy = "none"
+)
+
+// defaultContentType is the type of data that can't be detected.
+const defaultContentType = "application/octet-stream"
+
+// DirectoryDefaults are the settings of objects uploaded to the paths matching the pattern
+// (see matchPath). Expiration and attribute rules matching the path take precedence.
+type DirectoryDefaults struct {
+	Pattern string
+	// Attributes are added to the objects, the ones of AttributeRules override them.
+	Attributes []Attribute
+	// Lifetime in epochs or TTL of the objects, they don't expire if both are 0.
+	Lifetime uint64
+	TTL      time.Duration
+	// ContentType is one of the policies or the fixed MIME type, ContentTypeDetect if empty.
+	ContentType ContentTypePolicy
+}
+
+// CheckContentTypePolicy checks the policy is one of the known ones or a MIME type.
+func CheckContentTypePolicy(policy ContentTypePolicy) error {
+	switch policy {
+	case "", ContentTypeDetect, ContentTypeExtension, ContentTypeNone:
+		return nil
+	}
+	if _, _, err := mime.ParseMediaType(string(policy)); err != nil || !strings.Contains(string(policy), "/") {
+		return fmt.Errorf("invalid content type policy %q", policy)
+	}
+	return nil
+}
+
+// directoryDefaults returns the first directory defaults matching the file path, nil if there are none.
+func (a *App) directoryDefaults(filePath string) *DirectoryDefaults {
+	defaults := a.config().DirectoryDefaults
+	for i := range defaults {
+		if matchPath(defaults[i].Pattern, filePath) {
+			return &defaults[i]
+		}
+	}
+	return nil
+}
+
+// contentType returns the MIME type of the file according to the policy, empty if the attribute
+// isn't needed.
+func (w *objWriter) contentType(policy ContentTypePolicy) (string, error) {
+	switch policy {
+	case "", ContentTypeDetect:
+	case ContentTypeNone:
+		return "", nil
+	case ContentTypeExtension:
+		if typ := mime.TypeByExtension(path.Ext(w.file.Name())); typ != "" {
+			return typ, nil
+		}
+		return defaultContentType, nil
+	default:
+		return string(policy), nil
+	}
+
+	if typ := mime.TypeByExtension(path.Ext(w.file.Name())); typ != "" {
+		return typ, nil
+	}
+
+	// DetectContentType considers at most 512 bytes.
+	head := make([]byte, 512)
+	n, err := w.buffer.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
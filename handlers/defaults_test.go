@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestDirectoryDefaults(t *testing.T) {
+	app := NewApp(nil, nil, new(user.ID), zap.NewNop(), &SftpServerConfig{
+		ExpirationRules: []ExpirationRule{
+			{Pattern: "/reports/tmp/**", Lifetime: 1},
+		},
+		DirectoryDefaults: []DirectoryDefaults{
+			{Pattern: "/reports/**", TTL: time.Hour, ContentType: ContentTypeExtension},
+			{Pattern: "/raw/**", ContentType: ContentTypeNone},
+		},
+	}, "")
+
+	require.Equal(t, ContentTypeExtension, app.directoryDefaults("/reports/q1.csv").ContentType)
+	require.Nil(t, app.directoryDefaults("/other/file"))
+
+	require.Equal(t, uint64(1), app.expirationRule("/reports/tmp/file").Lifetime)
+	require.Equal(t, time.Hour, app.expirationRule("/reports/q1.csv").TTL)
+	require.Nil(t, app.expirationRule("/raw/file"))
+}
+
+func TestContentTypePolicy(t *testing.T) {
+	buffer, err := os.Create(filepath.Join(t.TempDir(), "buffer"))
+	require.NoError(t, err)
+	defer buffer.Close()
+	_, err = buffer.WriteString("plain text")
+	require.NoError(t, err)
+
+	w := &objWriter{file: &ObjectInfo{FileName: "data.unknown"}, buffer: buffer}
+	for policy, expected := range map[ContentTypePolicy]string{
+		"":                   "text/plain; charset=utf-8",
+		ContentTypeDetect:    "text/plain; charset=utf-8",
+		ContentTypeExtension: "application/octet-stream",
+		ContentTypeNone:      "",
+		"application/x-data": "application/x-data",
+	} {
+		typ, err := w.contentType(policy)
+		require.NoError(t, err)
+		require.Equal(t, expected, typ, policy)
+	}
+
+	require.NoError(t, CheckContentTypePolicy("text/csv"))
+	require.Error(t, CheckContentTypePolicy("guess"))
+}
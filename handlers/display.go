@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"io/fs"
+	"os"
+)
+
+// FileOwner is the numeric owner of files shown to clients.
+type FileOwner struct {
+	UID uint32
+	GID uint32
+}
+
+// displayInfo overrides the permissions and the owner of the file shown to clients.
+// Implements sftp.FileInfoUidGid.
+type displayInfo struct {
+	fs.FileInfo
+	perm  fs.FileMode
+	owner FileOwner
+}
+
+func (d displayInfo) Mode() fs.FileMode {
+	return d.FileInfo.Mode()&^fs.ModePerm | d.perm
+}
+
+func (d displayInfo) Uid() uint32 {
+	return d.owner.UID
+}
+
+func (d displayInfo) Gid() uint32 {
+	return d.owner.GID
+}
+
+// fileOwner returns the owner of files shown to the user.
+func (a *App) fileOwner() FileOwner {
+	cfg := a.config()
+	if owner, ok := cfg.UserFileOwners[a.userName]; ok {
+		return owner
+	}
+	return cfg.FileOwner
+}
+
+// displayedInfo returns the file information with the configured permissions of objects
+// and containers and the configured owner. Files are returned as is if neither is set.
+func (a *App) displayedInfo(info os.FileInfo) os.FileInfo {
+	perm := info.Mode() & fs.ModePerm
+	switch info.(type) {
+	case *ObjectInfo:
+		if mode := a.config().FileMode; mode != 0 {
+			perm = mode & fs.ModePerm
+		}
+	case *ContainerInfo:
+		if mode := a.config().DirectoryMode; mode != 0 {
+			perm = mode & fs.ModePerm
+		}
+	}
+	owner := a.fileOwner()
+	if perm == info.Mode()&fs.ModePerm && owner == (FileOwner{}) {
+		return info
+	}
+	return displayInfo{FileInfo: info, perm: perm, owner: owner}
+}
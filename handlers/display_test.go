@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestDisplayedInfo(t *testing.T) {
+	cfg := &SftpServerConfig{}
+	app := NewApp(nil, nil, new(user.ID), zap.NewNop(), cfg, "")
+
+	obj := &ObjectInfo{FileName: "file"}
+	cnr := &ContainerInfo{FileName: "dir", Policy: "REP 1"}
+	require.Equal(t, fs.ModePerm, app.displayedInfo(obj).Mode())
+	require.Equal(t, fs.ModePerm|fs.ModeDir, app.displayedInfo(cnr).Mode())
+
+	app.UpdateConfig(&SftpServerConfig{FileMode: 0o644, DirectoryMode: 0o755})
+	require.Equal(t, fs.FileMode(0o644), app.displayedInfo(obj).Mode())
+	require.Equal(t, "file", app.displayedInfo(obj).Name())
+
+	dir := app.displayedInfo(cnr)
+	require.Equal(t, 0o755|fs.ModeDir, dir.Mode())
+	require.True(t, dir.IsDir())
+	require.Equal(t, &ContainerSys{Policy: "REP 1"}, dir.Sys())
+
+	virtual := &virtualFile{name: ".info"}
+	require.Equal(t, fs.FileMode(0o444), app.displayedInfo(virtual).Mode())
+	require.Same(t, virtual, app.displayedInfo(virtual))
+}
+
+func TestDisplayedOwner(t *testing.T) {
+	app := NewApp(nil, nil, new(user.ID), zap.NewNop(), &SftpServerConfig{
+		FileOwner:      FileOwner{UID: 1000, GID: 1000},
+		UserFileOwners: map[string]FileOwner{"alice": {UID: 1001, GID: 1000}},
+	}, "")
+	obj := &ObjectInfo{FileName: "file"}
+
+	info, ok := app.displayedInfo(obj).(sftp.FileInfoUidGid)
+	require.True(t, ok)
+	require.Equal(t, uint32(1000), info.Uid())
+	require.Equal(t, fs.ModePerm, info.Mode())
+
+	sess := app.StartSession("Alice", "192.0.2.1:50000", func() error { return nil })
+	defer sess.EndSession()
+	info = sess.displayedInfo(obj).(sftp.FileInfoUidGid)
+	require.Equal(t, uint32(1001), info.Uid())
+	require.Equal(t, uint32(1000), info.Gid())
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+)
+
+// netInfoTTL limits the time the network info is reused to convert epochs to time.
+const netInfoTTL = time.Minute
+
+// netInfoCache keeps the network info, it's shared by all users of the App.
+type netInfoCache struct {
+	mu      sync.Mutex
+	info    netmap.NetworkInfo
+	fetched time.Time
+}
+
+func (a *App) networkInfo(ctx context.Context) (ni netmap.NetworkInfo, err error) {
+	ctx, span := startSpan(ctx, "neofs.netinfo")
+	defer func() { endSpan(span, err) }()
+
+	ni, err = a.layer().NetworkInfo(ctx, client.PrmNetworkInfo{})
+	if err != nil {
+		return ni, fmt.Errorf("get network info: %w", err)
+	}
+	return ni, nil
+}
+
+// cachedNetworkInfo returns the network info fetched at most netInfoTTL ago and the time it was fetched.
+func (a *App) cachedNetworkInfo(ctx context.Context) (netmap.NetworkInfo, time.Time, error) {
+	a.netInfo.mu.Lock()
+	defer a.netInfo.mu.Unlock()
+
+	if time.Since(a.netInfo.fetched) > netInfoTTL {
+		ni, err := a.networkInfo(ctx)
+		if err != nil {
+			return ni, time.Time{}, err
+		}
+		a.netInfo.info, a.netInfo.fetched = ni, time.Now()
+	}
+	return a.netInfo.info, a.netInfo.fetched, nil
+}
+
+// epochDuration returns the duration of the network epoch, 0 if it's unknown.
+func epochDuration(ni netmap.NetworkInfo) time.Duration {
+	return time.Duration(ni.EpochDuration()) * time.Duration(ni.MsPerBlock()) * time.Millisecond
+}
+
+// epochTime returns the approximate time of the epoch, it's accurate to the epoch duration.
+func (a *App) epochTime(ctx context.Context, epoch uint64) (time.Time, error) {
+	ni, fetched, err := a.cachedNetworkInfo(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return epochToTime(ni, fetched, epoch)
+}
+
+// epochToTime converts the epoch to time using the network info fetched at the time.
+func epochToTime(ni netmap.NetworkInfo, fetched time.Time, epoch uint64) (time.Time, error) {
+	duration := epochDuration(ni)
+	if duration <= 0 {
+		return time.Time{}, errors.New("unknown epoch duration")
+	}
+
+	current := ni.CurrentEpoch()
+	if epoch >= current {
+		return fetched, nil
+	}
+	return fetched.Add(-time.Duration(current-epoch) * duration), nil
+}
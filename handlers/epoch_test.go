@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEpochToTime(t *testing.T) {
+	fetched := time.Unix(1700000000, 0)
+
+	var ni netmap.NetworkInfo
+	ni.SetCurrentEpoch(100)
+
+	_, err := epochToTime(ni, fetched, 90)
+	require.Error(t, err, "epoch duration is unknown")
+
+	ni.SetEpochDuration(240)
+	ni.SetMsPerBlock(15000)
+
+	created, err := epochToTime(ni, fetched, 90)
+	require.NoError(t, err)
+	require.Equal(t, fetched.Add(-10*time.Hour), created)
+
+	created, err = epochToTime(ni, fetched, 100)
+	require.NoError(t, err)
+	require.Equal(t, fetched, created)
+}
+
+func TestWithoutExpired(t *testing.T) {
+	var ni netmap.NetworkInfo
+	ni.SetCurrentEpoch(100)
+	a := &App{netInfo: &netInfoCache{info: ni, fetched: time.Now()}}
+
+	permanent := &ObjectInfo{FileName: "permanent"}
+	expired := &ObjectInfo{FileName: "expired", ExpirationEpoch: 99}
+	last := &ObjectInfo{FileName: "last", ExpirationEpoch: 100}
+
+	res := a.withoutExpired(context.Background(), []*ObjectInfo{permanent, expired, last})
+	require.Equal(t, []*ObjectInfo{permanent, last}, res)
+}
+
+func TestExpirationDeadline(t *testing.T) {
+	created := time.Unix(1700000000, 0)
+
+	require.Equal(t, created.Add(720*time.Hour), expirationDeadline(ExpirationRule{TTL: 720 * time.Hour}, created, time.Hour))
+	require.Equal(t, created.Add(10*time.Hour), expirationDeadline(ExpirationRule{Lifetime: 10}, created, time.Hour))
+}
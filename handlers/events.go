@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+)
+
+// Event types.
+const (
+	EventUpload = "upload"
+	EventDelete = "delete"
+)
+
+// Event describes a successful change made by the client.
+type Event struct {
+	Type      string    `json:"type"`
+	Time      time.Time `json:"time"`
+	Session   string    `json:"session,omitempty"`
+	User      string    `json:"user,omitempty"`
+	Request   string    `json:"request,omitempty"`
+	Path      string    `json:"path"`
+	Container string    `json:"container"`
+	// Object is empty for the container events.
+	Object string `json:"object,omitempty"`
+	Size   int64  `json:"size,omitempty"`
+}
+
+// OnEvent sets the handler called after every successful upload and deletion.
+// It must be set before sessions are started and mustn't block.
+func (a *App) OnEvent(handler func(Event)) {
+	a.onEvent = handler
+}
+
+func (a *App) emitEvent(ctx context.Context, typ, path string, cnrID cid.ID, objID *oid.ID, size int64) {
+	if a.onEvent == nil {
+		return
+	}
+
+	e := Event{
+		Type:      typ,
+		Time:      time.Now().UTC(),
+		Request:   requestID(ctx),
+		Path:      path,
+		Container: cnrID.EncodeToString(),
+		Size:      size,
+	}
+	if objID != nil {
+		e.Object = objID.EncodeToString()
+	}
+	if a.session != nil {
+		e.Session = a.session.id
+		e.User = a.session.user
+	}
+
+	a.onEvent(e)
+}
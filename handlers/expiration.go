@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ExpirationRule sets the lifetime of objects uploaded to the paths matching the pattern
+// (see matchPath). Either Lifetime or TTL is set.
+type ExpirationRule struct {
+	Pattern string
+	// Lifetime is the number of epochs.
+	Lifetime uint64
+	// TTL is converted to epochs using the network epoch duration.
+	TTL time.Duration
+}
+
+// expirationRule returns the first expiration rule matching the file path or the one of the
+// directory defaults, nil if there is none.
+func (a *App) expirationRule(filePath string) *ExpirationRule {
+	rules := a.config().ExpirationRules
+	for i := range rules {
+		if matchPath(rules[i].Pattern, filePath) {
+			return &rules[i]
+		}
+	}
+	if defaults := a.directoryDefaults(filePath); defaults != nil && (defaults.Lifetime > 0 || defaults.TTL > 0) {
+		return &ExpirationRule{Pattern: defaults.Pattern, Lifetime: defaults.Lifetime, TTL: defaults.TTL}
+	}
+	return nil
+}
+
+// expirationEpoch returns the last epoch of the object uploaded now according to the rule.
+func (a *App) expirationEpoch(ctx context.Context, rule ExpirationRule) (uint64, error) {
+	ni, err := a.networkInfo(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	lifetime := rule.Lifetime
+	if rule.TTL > 0 {
+		duration := epochDuration(ni)
+		if duration <= 0 {
+			return 0, errors.New("unknown epoch duration")
+		}
+		// Objects are kept at least for TTL.
+		lifetime = uint64((rule.TTL + duration - 1) / duration)
+	}
+
+	return ni.CurrentEpoch() + lifetime, nil
+}
+
+// expirationDeadline returns the time the object created at the time expires according to the rule.
+// Lifetime in epochs is converted to time using the epoch duration.
+func expirationDeadline(rule ExpirationRule, created time.Time, epochDuration time.Duration) time.Time {
+	ttl := rule.TTL
+	if ttl <= 0 {
+		ttl = time.Duration(rule.Lifetime) * epochDuration
+	}
+	return created.Add(ttl)
+}
+
+// StartExpirationSweep deletes the objects uploaded without the expiration epoch (before the rules
+// were configured or by other clients) once their expiration rules deadline passes. Containers
+// of the gateway owner are scanned with the interval until ctx is done, it's disabled if the interval is 0.
+func (a *App) StartExpirationSweep(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			deleted, err := a.sweepExpired(ctx)
+			if err != nil {
+				a.Log.Warn("expiration sweep failed", zap.Int("deleted", deleted), zap.Error(err))
+				continue
+			}
+			a.Log.Info("expiration sweep finished", zap.Int("deleted", deleted))
+		}
+	}()
+}
+
+// sweepExpired deletes the objects without the expiration epoch whose expiration rules deadline passed,
+// it returns the number of deleted objects.
+func (a *App) sweepExpired(ctx context.Context) (int, error) {
+	if cfg := a.config(); len(cfg.ExpirationRules) == 0 && len(cfg.DirectoryDefaults) == 0 {
+		return 0, nil
+	}
+
+	ni, err := a.networkInfo(ctx)
+	if err != nil {
+		return 0, err
+	}
+	duration := epochDuration(ni)
+
+	containers, err := a.listContainers(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int
+	now := time.Now()
+	for _, info := range containers {
+		cnr := info.(*ContainerInfo)
+		objects, err := a.searchObjects(ctx, cnr.CID, "")
+		if err != nil {
+			a.Log.Warn("skip container in expiration sweep", zap.Stringer("container", cnr.CID), zap.Error(err))
+			continue
+		}
+
+		for _, obj := range objects {
+			if obj.ExpirationEpoch > 0 {
+				// The network removes it.
+				continue
+			}
+			filePath := delimiter + path.Join(cnr.Name(), obj.Name())
+			rule := a.expirationRule(filePath)
+			if rule == nil || (rule.TTL <= 0 && duration <= 0) {
+				continue
+			}
+			if now.Before(expirationDeadline(*rule, obj.Created, duration)) {
+				continue
+			}
+
+			if err = a.deleteObject(ctx, newAddress(cnr.CID, obj.ObjectID)); err != nil {
+				return deleted, fmt.Errorf("delete object %s: %w", obj.ObjectID, err)
+			}
+			deleted++
+			a.Log.Debug("expired object deleted", zap.String("path", filePath), zap.Stringer("object", obj.ObjectID))
+			a.emitEvent(ctx, EventDelete, filePath, cnr.CID, &obj.ObjectID, obj.PayloadSize)
+		}
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/sftp"
+	"go.uber.org/zap"
+)
+
+// Extended requests served by the gateway.
+const (
+	// ExtensionGetAttr returns attributes of the object: the request carries the path,
+	// the reply carries the number of attributes and their key-value string pairs.
+	ExtensionGetAttr = "neofs-getattr@nspcc.ru"
+	// ExtensionSetAttr sets attributes of the object by putting its copy: the request carries
+	// the path, the number of attributes and their key-value string pairs. Empty values remove
+	// the attributes.
+	ExtensionSetAttr = "neofs-setattr@nspcc.ru"
+	// ExtensionMkdir creates the container: the request carries the directory path, the placement
+	// policy, the basic ACL and the attributes in the format of ExtensionSetAttr.
+	ExtensionMkdir = "neofs-mkdir@nspcc.ru"
+)
+
+// SFTP protocol values used to serve the extended requests.
+const (
+	fxpVersion       = 2
+	fxpStatus        = 101
+	fxpExtended      = 200
+	fxpExtendedReply = 201
+
+	fxOK               = 0
+	fxNoSuchFile       = 2
+	fxPermissionDenied = 3
+	fxFailure          = 4
+	fxOpUnsupported    = 8
+
+	// maxPacketLength is the limit of pkg/sftp.
+	maxPacketLength = 256 * 1024
+)
+
+// extensionHandler serves the extended request for the file and returns the reply data,
+// nil if only the status is replied.
+type extensionHandler func(a *App, ctx context.Context, filePath string, data []byte) ([]byte, error)
+
+var extensions = map[string]extensionHandler{
+	ExtensionGetAttr: (*App).getAttrExtension,
+	ExtensionSetAttr: (*App).setAttrExtension,
+	ExtensionMkdir:   (*App).mkdirExtension,
+}
+
+var errBadMessage = errors.New("malformed extended request")
+
+// extendedConn passes SFTP packets between the connection and the request server
+// except the gateway extended requests served by the App.
+type extendedConn struct {
+	app    *App
+	conn   io.ReadWriteCloser
+	ctx    context.Context
+	cancel context.CancelFunc
+	in     *io.PipeReader
+
+	// mu guards writes to conn, so that the packets aren't interleaved.
+	mu sync.Mutex
+	// out keeps the incomplete packet written by the request server.
+	out []byte
+}
+
+// ExtendConn returns the connection to be served by sftp.RequestServer of the session.
+// The gateway extended requests are served by the App and never reach the server.
+func (a *App) ExtendConn(conn io.ReadWriteCloser) io.ReadWriteCloser {
+	in, pipe := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := &extendedConn{
+		app:    a,
+		conn:   conn,
+		ctx:    ctx,
+		cancel: cancel,
+		in:     in,
+	}
+	go c.readPackets(pipe)
+
+	return c
+}
+
+func (c *extendedConn) Read(p []byte) (int, error) {
+	return c.in.Read(p)
+}
+
+// Write passes complete packets of the request server to the connection, the supported
+// extensions are added to the version packet.
+func (c *extendedConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.out = append(c.out, p...)
+
+	var off int
+	for len(c.out)-off >= 4 {
+		end := off + 4 + int(binary.BigEndian.Uint32(c.out[off:]))
+		if len(c.out) < end {
+			break
+		}
+
+		packet := c.out[off:end]
+		if packet[4] == fxpVersion {
+			packet = appendExtensions(packet)
+		}
+		if _, err := c.conn.Write(packet); err != nil {
+			return 0, err
+		}
+		off = end
+	}
+	c.out = append(c.out[:0], c.out[off:]...)
+
+	return len(p), nil
+}
+
+func (c *extendedConn) Close() error {
+	c.cancel()
+	return c.conn.Close()
+}
+
+// readPackets passes the packets from the connection to the request server until the connection
+// is closed, the error is returned by the reading side of the pipe.
+func (c *extendedConn) readPackets(pipe *io.PipeWriter) {
+	var err error
+	defer func() { _ = pipe.CloseWithError(err) }()
+
+	header := make([]byte, 4)
+	for {
+		if _, err = io.ReadFull(c.conn, header); err != nil {
+			return
+		}
+		length := binary.BigEndian.Uint32(header)
+		if length == 0 || length > maxPacketLength {
+			err = fmt.Errorf("invalid packet length %d", length)
+			return
+		}
+
+		packet := make([]byte, 4+length)
+		copy(packet, header)
+		if _, err = io.ReadFull(c.conn, packet[4:]); err != nil {
+			return
+		}
+
+		if c.serveExtended(packet[4:]) {
+			continue
+		}
+		if _, err = pipe.Write(packet); err != nil {
+			return
+		}
+	}
+}
+
+// serveExtended starts serving the packet if it's the gateway extended request.
+func (c *extendedConn) serveExtended(payload []byte) bool {
+	if payload[0] != fxpExtended || len(payload) < 5 {
+		return false
+	}
+
+	id := binary.BigEndian.Uint32(payload[1:])
+	name, data, err := unmarshalString(payload[5:])
+	if err != nil {
+		return false
+	}
+	handler, ok := extensions[name]
+	if !ok {
+		return false
+	}
+
+	// Requests are served concurrently as the request server does.
+	go func() {
+		filePath, data, err := unmarshalString(data)
+		if err != nil {
+			c.writeStatus(id, errBadMessage)
+			return
+		}
+
+		reply, err := c.app.serveExtension(sftp.NewRequest(name, filePath).WithContext(c.ctx), handler, data)
+		if err != nil || reply == nil {
+			c.writeStatus(id, err)
+			return
+		}
+		c.writePacket(fxpExtendedReply, id, reply)
+	}()
+
+	return true
+}
+
+func (c *extendedConn) writeStatus(id uint32, err error) {
+	code, msg := uint32(fxOK), ""
+	if err != nil {
+		code, msg = statusCode(err), err.Error()
+	}
+
+	data := binary.BigEndian.AppendUint32(nil, code)
+	data = appendString(data, msg)
+	data = appendString(data, "") // language tag
+	c.writePacket(fxpStatus, id, data)
+}
+
+func (c *extendedConn) writePacket(typ byte, id uint32, data []byte) {
+	packet := make([]byte, 9, 9+len(data))
+	binary.BigEndian.PutUint32(packet, uint32(5+len(data)))
+	packet[4] = typ
+	binary.BigEndian.PutUint32(packet[5:], id)
+	packet = append(packet, data...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.conn.Write(packet); err != nil {
+		c.app.Log.Debug("failed to send extended reply", zap.Error(err))
+	}
+}
+
+// serveExtension serves the extended request as the other SFTP requests are served.
+func (a *App) serveExtension(r *sftp.Request, handler extensionHandler, data []byte) (reply []byte, err error) {
+	a.session.setOperation(r.Method, r.Filepath)
+	ctx, span := a.startRequest(r)
+	defer func() {
+		if p := recover(); p != nil {
+			err = a.recoverRequest(ctx, r, p)
+		}
+		endSpan(span, err)
+		a.finishRequest(ctx, r, err)
+	}()
+
+	return handler(a, ctx, r.Filepath, data)
+}
+
+func statusCode(err error) uint32 {
+	switch {
+	case errors.Is(err, sftp.ErrSSHFxNoSuchFile):
+		return fxNoSuchFile
+	case errors.Is(err, sftp.ErrSSHFxPermissionDenied):
+		return fxPermissionDenied
+	case errors.Is(err, sftp.ErrSSHFxOpUnsupported):
+		return fxOpUnsupported
+	default:
+		return fxFailure
+	}
+}
+
+// appendExtensions returns the version packet with the gateway extensions added.
+func appendExtensions(packet []byte) []byte {
+	names := make([]string, 0, len(extensions))
+	for name := range extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := append([]byte{}, packet...)
+	for _, name := range names {
+		res = appendString(res, name)
+		res = appendString(res, "1")
+	}
+	binary.BigEndian.PutUint32(res, uint32(len(res)-4))
+	return res
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+func unmarshalString(b []byte) (string, []byte, error) {
+	if len(b) < 4 {
+		return "", nil, errBadMessage
+	}
+	n := binary.BigEndian.Uint32(b)
+	if uint32(len(b)-4) < n {
+		return "", nil, errBadMessage
+	}
+	return string(b[4 : 4+n]), b[4+n:], nil
+}
+
+// unmarshalAttributes decodes the number of attributes followed by their key-value pairs.
+func unmarshalAttributes(b []byte) ([]Attribute, error) {
+	if len(b) < 4 {
+		return nil, errBadMessage
+	}
+	n := binary.BigEndian.Uint32(b)
+	b = b[4:]
+
+	var (
+		attrs []Attribute
+		err   error
+	)
+	for i := uint32(0); i < n; i++ {
+		var attr Attribute
+		if attr.Key, b, err = unmarshalString(b); err != nil {
+			return nil, err
+		}
+		if attr.Value, b, err = unmarshalString(b); err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(attr.Key) == "" {
+			return nil, errBadMessage
+		}
+		attrs = append(attrs, attr)
+	}
+	return attrs, nil
+}
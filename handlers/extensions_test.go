@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newPacket(typ byte, data []byte) []byte {
+	packet := binary.BigEndian.AppendUint32(nil, uint32(1+len(data)))
+	packet = append(packet, typ)
+	return append(packet, data...)
+}
+
+func TestExtendConn(t *testing.T) {
+	client, server := net.Pipe()
+	conn := new(App).ExtendConn(server)
+	defer conn.Close()
+
+	// Requests other than the gateway extensions reach the server untouched.
+	open := newPacket(3, appendString([]byte{0, 0, 0, 1}, "/cnr/file"))
+	go func() { _, _ = client.Write(open) }()
+
+	received := make([]byte, len(open))
+	_, err := io.ReadFull(conn, received)
+	require.NoError(t, err)
+	require.Equal(t, open, received)
+
+	// The version packet is sent when it's complete and advertises the extensions.
+	version := newPacket(fxpVersion, []byte{0, 0, 0, 3})
+	go func() {
+		_, _ = conn.Write(version[:3])
+		_, _ = conn.Write(version[3:])
+	}()
+
+	header := make([]byte, 4)
+	_, err = io.ReadFull(client, header)
+	require.NoError(t, err)
+	payload := make([]byte, binary.BigEndian.Uint32(header))
+	_, err = io.ReadFull(client, payload)
+	require.NoError(t, err)
+	require.Equal(t, version[4:], payload[:len(version)-4])
+
+	data := payload[len(version)-4:]
+	for _, expected := range []string{ExtensionGetAttr, "1", ExtensionMkdir, "1", ExtensionSetAttr, "1"} {
+		var s string
+		s, data, err = unmarshalString(data)
+		require.NoError(t, err)
+		require.Equal(t, expected, s)
+	}
+	require.Empty(t, data)
+}
+
+func TestUnmarshalAttributes(t *testing.T) {
+	data := binary.BigEndian.AppendUint32(nil, 2)
+	data = appendString(appendString(data, "Author"), "me")
+	data = appendString(appendString(data, "Draft"), "")
+
+	attrs, err := unmarshalAttributes(data)
+	require.NoError(t, err)
+	require.Equal(t, []Attribute{{Key: "Author", Value: "me"}, {Key: "Draft"}}, attrs)
+
+	_, err = unmarshalAttributes(data[:len(data)-2])
+	require.ErrorIs(t, err, errBadMessage)
+}
+
+func TestContainerDirName(t *testing.T) {
+	name, err := containerDirName("/backups")
+	require.NoError(t, err)
+	require.Equal(t, "backups", name)
+
+	for _, dir := range []string{"/backups/daily", "/", "/.neofs"} {
+		_, err = containerDirName(dir)
+		require.Error(t, err, dir)
+	}
+}
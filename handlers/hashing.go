@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/nspcc-dev/neofs-sdk-go/container"
+)
+
+// Supported homomorphic hashing settings.
+const (
+	// HomomorphicHashingAuto follows the network setting, networks with disabled homomorphic
+	// hashing reject containers having it.
+	HomomorphicHashingAuto HomomorphicHashing = "auto"
+	// HomomorphicHashingEnabled leaves homomorphic hashing enabled unless the container
+	// attributes disable it.
+	HomomorphicHashingEnabled HomomorphicHashing = "enabled"
+	// HomomorphicHashingDisabled disables homomorphic hashing of containers.
+	HomomorphicHashingDisabled HomomorphicHashing = "disabled"
+)
+
+// setHomomorphicHashing disables homomorphic hashing of the new container if required by the setting.
+func (a *App) setHomomorphicHashing(ctx context.Context, cnr *container.Container, setting HomomorphicHashing) error {
+	switch setting {
+	case HomomorphicHashingDisabled:
+		cnr.DisableHomomorphicHashing()
+	case HomomorphicHashingAuto:
+		ni, err := a.networkInfo(ctx)
+		if err != nil {
+			return err
+		}
+		if ni.HomomorphicHashingDisabled() {
+			cnr.DisableHomomorphicHashing()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/accounting"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/container"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	neofscrypto "github.com/nspcc-dev/neofs-sdk-go/crypto"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/stat"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/layer"
+	"go.uber.org/zap"
+)
+
+// NeoFS operations whose latencies are recorded.
+const (
+	opSearch           = "search"
+	opHead             = "head"
+	opGet              = "get"
+	opRange            = "range"
+	opPut              = "put"
+	opDelete           = "delete"
+	opContainerGet     = "container_get"
+	opContainerList    = "container_list"
+	opContainerPut     = "container_put"
+	opContainerDelete  = "container_delete"
+	opContainerEACL    = "container_eacl"
+	opContainerSetEACL = "container_set_eacl"
+	opBalance          = "balance"
+	opNetworkInfo      = "network_info"
+)
+
+// opMethods are the node API methods the operations are served with.
+var opMethods = map[string][]stat.Method{
+	opSearch:           {stat.MethodObjectSearch, stat.MethodObjectSearchStream},
+	opHead:             {stat.MethodObjectHead},
+	opGet:              {stat.MethodObjectGet, stat.MethodObjectGetStream},
+	opRange:            {stat.MethodObjectRange, stat.MethodObjectRangeStream},
+	opPut:              {stat.MethodObjectPut, stat.MethodObjectPutStream},
+	opDelete:           {stat.MethodObjectDelete},
+	opContainerGet:     {stat.MethodContainerGet},
+	opContainerList:    {stat.MethodContainerList},
+	opContainerPut:     {stat.MethodContainerPut},
+	opContainerDelete:  {stat.MethodContainerDelete},
+	opContainerEACL:    {stat.MethodContainerEACL},
+	opContainerSetEACL: {stat.MethodContainerSetEACL},
+	opBalance:          {stat.MethodBalanceGet},
+	opNetworkInfo:      {stat.MethodNetworkInfo},
+}
+
+// LatencyBuckets are the upper bounds of the latency histogram buckets in seconds.
+var LatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+type (
+	// NodeCalls provides the nodes NeoFS calls are served by, e.g. recorded with the statistic callback
+	// of the connection pool.
+	NodeCalls interface {
+		// Nodes returns the addresses of the nodes serving the calls of the methods between start and end.
+		Nodes(methods []stat.Method, start, end time.Time) []string
+	}
+
+	// Latency is the histogram of NeoFS call durations of a single operation.
+	Latency struct {
+		Count uint64
+		// Sum is the total duration of the calls in seconds.
+		Sum float64
+		// Buckets are the cumulative numbers of calls by the upper bounds of LatencyBuckets.
+		Buckets map[float64]uint64
+	}
+
+	// latencies are the histograms of NeoFS calls by operations, they're shared by all users of the App.
+	latencies struct {
+		mu  sync.Mutex
+		ops map[string]*latency
+		// slowThreshold is the duration of calls logged as slow, they aren't logged if it's 0.
+		slowThreshold atomic.Int64
+		// nodes provides the nodes slow calls are logged with, nil if unknown.
+		nodes NodeCalls
+	}
+
+	latency struct {
+		count   uint64
+		sum     float64
+		buckets []uint64
+	}
+
+	// measuredLayer records the latencies of all the calls of the layer. Calls returning readers
+	// are finished when the readers are closed.
+	measuredLayer struct {
+		layer.Layer
+		latencies *latencies
+	}
+
+	measuredReader struct {
+		io.ReadCloser
+		ctx       context.Context
+		op        string
+		start     time.Time
+		latencies *latencies
+		once      sync.Once
+	}
+)
+
+func newLatencies() *latencies {
+	return &latencies{ops: make(map[string]*latency)}
+}
+
+// observe records the duration of the NeoFS call of the operation started at start,
+// the call is logged with the SFTP request it's made for if it's slow.
+func (l *latencies) observe(ctx context.Context, op string, start time.Time) {
+	if l == nil {
+		return
+	}
+	end := time.Now()
+	duration := end.Sub(start)
+	if threshold := time.Duration(l.slowThreshold.Load()); threshold > 0 && duration > threshold {
+		fields := []zap.Field{zap.String("operation", op), zap.Duration("duration", duration)}
+		if l.nodes != nil {
+			fields = append(fields, zap.Strings("nodes", l.nodes.Nodes(opMethods[op], start, end)))
+		}
+		if r, ok := ctx.Value(requestInfoKey{}).(requestInfo); ok {
+			fields = append(fields, zap.String("method", r.method), zap.String("path", r.path))
+		}
+		requestLogger(ctx).Warn("slow NeoFS call", fields...)
+	}
+	seconds := duration.Seconds()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h, ok := l.ops[op]
+	if !ok {
+		h = &latency{buckets: make([]uint64, len(LatencyBuckets))}
+		l.ops[op] = h
+	}
+	h.count++
+	h.sum += seconds
+	for i, bound := range LatencyBuckets {
+		if seconds <= bound {
+			h.buckets[i]++
+			break
+		}
+	}
+}
+
+// SetNodeCalls sets the provider of the nodes slow NeoFS calls are logged with.
+// It must be set before sessions are started.
+func (a *App) SetNodeCalls(nodes NodeCalls) {
+	a.latencies.nodes = nodes
+}
+
+// NeoFSLatencies returns the histograms of NeoFS call durations by operations.
+func (a *App) NeoFSLatencies() map[string]Latency {
+	a.latencies.mu.Lock()
+	defer a.latencies.mu.Unlock()
+
+	res := make(map[string]Latency, len(a.latencies.ops))
+	for op, h := range a.latencies.ops {
+		l := Latency{Count: h.count, Sum: h.sum, Buckets: make(map[float64]uint64, len(LatencyBuckets))}
+		var cumulative uint64
+		for i, bound := range LatencyBuckets {
+			cumulative += h.buckets[i]
+			l.Buckets[bound] = cumulative
+		}
+		res[op] = l
+	}
+	return res
+}
+
+func (m *measuredLayer) ObjectSearch(ctx context.Context, cnrID cid.ID, signer user.Signer, prm client.PrmObjectSearch, filters object.SearchFilters, f func(oid.ID) bool) error {
+	defer m.latencies.observe(ctx, opSearch, time.Now())
+	return m.Layer.ObjectSearch(ctx, cnrID, signer, prm, filters, f)
+}
+
+func (m *measuredLayer) ObjectHead(ctx context.Context, cnrID cid.ID, objID oid.ID, signer user.Signer, prm client.PrmObjectHead) (*object.Object, error) {
+	defer m.latencies.observe(ctx, opHead, time.Now())
+	return m.Layer.ObjectHead(ctx, cnrID, objID, signer, prm)
+}
+
+func (m *measuredLayer) ObjectGet(ctx context.Context, cnrID cid.ID, objID oid.ID, signer user.Signer, prm client.PrmObjectGet) (object.Object, io.ReadCloser, error) {
+	start := time.Now()
+	hdr, payload, err := m.Layer.ObjectGet(ctx, cnrID, objID, signer, prm)
+	if err != nil {
+		m.latencies.observe(ctx, opGet, start)
+		return hdr, nil, err
+	}
+	return hdr, m.measureReader(ctx, opGet, start, payload), nil
+}
+
+func (m *measuredLayer) ObjectRange(ctx context.Context, cnrID cid.ID, objID oid.ID, offset, length uint64, signer user.Signer, prm client.PrmObjectRange) (io.ReadCloser, error) {
+	start := time.Now()
+	payload, err := m.Layer.ObjectRange(ctx, cnrID, objID, offset, length, signer, prm)
+	if err != nil {
+		m.latencies.observe(ctx, opRange, start)
+		return nil, err
+	}
+	return m.measureReader(ctx, opRange, start, payload), nil
+}
+
+func (m *measuredLayer) ObjectPut(ctx context.Context, hdr object.Object, signer user.Signer, prm client.PrmObjectPutInit, payload io.Reader) (oid.ID, error) {
+	defer m.latencies.observe(ctx, opPut, time.Now())
+	return m.Layer.ObjectPut(ctx, hdr, signer, prm, payload)
+}
+
+func (m *measuredLayer) ObjectDelete(ctx context.Context, cnrID cid.ID, objID oid.ID, signer user.Signer, prm client.PrmObjectDelete) (oid.ID, error) {
+	defer m.latencies.observe(ctx, opDelete, time.Now())
+	return m.Layer.ObjectDelete(ctx, cnrID, objID, signer, prm)
+}
+
+func (m *measuredLayer) ContainerPut(ctx context.Context, cnr container.Container, signer neofscrypto.Signer, prm client.PrmContainerPut) (cid.ID, error) {
+	defer m.latencies.observe(ctx, opContainerPut, time.Now())
+	return m.Layer.ContainerPut(ctx, cnr, signer, prm)
+}
+
+func (m *measuredLayer) ContainerGet(ctx context.Context, cnrID cid.ID, prm client.PrmContainerGet) (container.Container, error) {
+	defer m.latencies.observe(ctx, opContainerGet, time.Now())
+	return m.Layer.ContainerGet(ctx, cnrID, prm)
+}
+
+func (m *measuredLayer) ContainerList(ctx context.Context, owner user.ID, prm client.PrmContainerList) ([]cid.ID, error) {
+	defer m.latencies.observe(ctx, opContainerList, time.Now())
+	return m.Layer.ContainerList(ctx, owner, prm)
+}
+
+func (m *measuredLayer) ContainerDelete(ctx context.Context, cnrID cid.ID, signer neofscrypto.Signer, prm client.PrmContainerDelete) error {
+	defer m.latencies.observe(ctx, opContainerDelete, time.Now())
+	return m.Layer.ContainerDelete(ctx, cnrID, signer, prm)
+}
+
+func (m *measuredLayer) ContainerEACL(ctx context.Context, cnrID cid.ID, prm client.PrmContainerEACL) (eacl.Table, error) {
+	defer m.latencies.observe(ctx, opContainerEACL, time.Now())
+	return m.Layer.ContainerEACL(ctx, cnrID, prm)
+}
+
+func (m *measuredLayer) ContainerSetEACL(ctx context.Context, table eacl.Table, signer user.Signer, prm client.PrmContainerSetEACL) error {
+	defer m.latencies.observe(ctx, opContainerSetEACL, time.Now())
+	return m.Layer.ContainerSetEACL(ctx, table, signer, prm)
+}
+
+func (m *measuredLayer) BalanceGet(ctx context.Context, prm client.PrmBalanceGet) (accounting.Decimal, error) {
+	defer m.latencies.observe(ctx, opBalance, time.Now())
+	return m.Layer.BalanceGet(ctx, prm)
+}
+
+func (m *measuredLayer) NetworkInfo(ctx context.Context, prm client.PrmNetworkInfo) (netmap.NetworkInfo, error) {
+	defer m.latencies.observe(ctx, opNetworkInfo, time.Now())
+	return m.Layer.NetworkInfo(ctx, prm)
+}
+
+func (m *measuredLayer) measureReader(ctx context.Context, op string, start time.Time, r io.ReadCloser) io.ReadCloser {
+	return &measuredReader{ReadCloser: r, ctx: ctx, op: op, start: start, latencies: m.latencies}
+}
+
+// Close records the latency of the call the reader is returned by.
+func (r *measuredReader) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(func() { r.latencies.observe(r.ctx, r.op, r.start) })
+	return err
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/stat"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNeoFSLatencies(t *testing.T) {
+	app := NewApp(nil, nil, new(user.ID), zap.NewNop(), &SftpServerConfig{}, "")
+	require.Empty(t, app.NeoFSLatencies())
+
+	ctx, now := context.Background(), time.Now()
+	app.latencies.observe(ctx, opHead, now)
+	app.latencies.observe(ctx, opHead, now.Add(-time.Second))
+	app.latencies.observe(ctx, opHead, now.Add(-time.Hour))
+	app.latencies.observe(ctx, opPut, now.Add(-3*time.Second))
+
+	res := app.NeoFSLatencies()
+	require.Len(t, res, 2)
+
+	head := res[opHead]
+	require.Equal(t, uint64(3), head.Count)
+	require.Greater(t, head.Sum, 3601.0)
+	require.Equal(t, uint64(1), head.Buckets[.005])
+	require.Equal(t, uint64(1), head.Buckets[.5])
+	require.Equal(t, uint64(2), head.Buckets[2.5])
+	// Calls longer than the largest bound are only counted in the total.
+	require.Equal(t, uint64(2), head.Buckets[60])
+
+	put := res[opPut]
+	require.Equal(t, uint64(1), put.Count)
+	require.Zero(t, put.Buckets[2.5])
+	require.Equal(t, uint64(1), put.Buckets[5])
+	require.Len(t, put.Buckets, len(LatencyBuckets))
+
+	// A nil registry records nothing.
+	var l *latencies
+	l.observe(ctx, opGet, now)
+}
+
+type fixedNodes []string
+
+func (n fixedNodes) Nodes(methods []stat.Method, _, _ time.Time) []string {
+	if len(methods) != 1 || methods[0] != stat.MethodObjectHead {
+		return nil
+	}
+	return n
+}
+
+func TestSlowOperationsLog(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	cfg := &SftpServerConfig{}
+	app := NewApp(nil, nil, new(user.ID), zap.New(core), cfg, "")
+	app.SetNodeCalls(fixedNodes{"node:8080"})
+	sess := app.StartSession("alice", "192.0.2.1:50000", nil)
+	defer sess.EndSession()
+
+	r := sftp.NewRequest("Rename", "/backups/old")
+	r.Target = "/backups/new"
+
+	ctx, span := sess.startRequest(r)
+	sess.latencies.observe(ctx, opHead, time.Now())
+	endSpan(span, nil)
+	sess.finishRequest(ctx, r, nil)
+	require.Zero(t, logs.Len())
+
+	slowCfg := *cfg
+	slowCfg.SlowThreshold = time.Nanosecond
+	app.UpdateConfig(&slowCfg)
+
+	ctx, span = sess.startRequest(r)
+	sess.latencies.observe(ctx, opHead, time.Now().Add(-time.Second))
+	endSpan(span, nil)
+	sess.finishRequest(ctx, r, nil)
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+
+	require.Equal(t, "slow NeoFS call", entries[0].Message)
+	fields := entries[0].ContextMap()
+	require.Equal(t, opHead, fields["operation"])
+	require.Equal(t, []any{"node:8080"}, fields["nodes"])
+	require.Equal(t, "/backups/old", fields["path"])
+	require.GreaterOrEqual(t, fields["duration"], time.Second)
+
+	require.Equal(t, "slow request", entries[1].Message)
+	fields = entries[1].ContextMap()
+	require.Equal(t, "Rename", fields["method"])
+	require.Equal(t, "/backups/old", fields["path"])
+	require.Equal(t, "/backups/new", fields["target"])
+	require.Equal(t, "alice", fields["user"])
+	require.NotEmpty(t, fields["request"])
+}
+
+func TestMeasuredLayer(t *testing.T) {
+	app, storage := newMemoryApp(t, &SftpServerConfig{})
+	require.NoError(t, app.Filecmd(sftp.NewRequest("Mkdir", "/docs")))
+	uploadFile(t, app, "/docs/a.txt", "content")
+	cnr, err := app.getContainerByName(context.Background(), "docs")
+	require.NoError(t, err)
+	id, _ := storage.Objects(cnr.CID)[0].ID()
+	require.Equal(t, "content", downloadFile(t, app, "/docs/"+id.EncodeToString()))
+
+	res := app.NeoFSLatencies()
+	// Polls of the waiter are recorded apart from the put.
+	require.Equal(t, uint64(1), res[opContainerPut].Count)
+	require.NotZero(t, res[opContainerGet].Count)
+	require.Equal(t, uint64(1), res[opPut].Count)
+	require.NotZero(t, res[opRange].Count)
+}
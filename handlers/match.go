@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"path"
+	"strings"
+)
+
+// CheckPathPattern checks the syntax of the file path pattern, see matchPath.
+func CheckPathPattern(pattern string) error {
+	for _, segment := range strings.Split(strings.Trim(pattern, delimiter), delimiter) {
+		if _, err := path.Match(segment, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchPath reports whether the file path matches the pattern. Segments of the pattern are matched
+// with path.Match and "**" matches any number of segments, e.g. "/alpha/**" matches every file of
+// the alpha container.
+func matchPath(pattern, name string) bool {
+	return matchSegments(
+		strings.Split(strings.Trim(pattern, delimiter), delimiter),
+		strings.Split(strings.Trim(name, delimiter), delimiter),
+	)
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchPath(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, name string
+		match         bool
+	}{
+		{pattern: "/alpha/**", name: "/alpha/file", match: true},
+		{pattern: "/alpha/**", name: "/alpha/dir/file", match: true},
+		{pattern: "/alpha/**", name: "/beta/file"},
+		{pattern: "/tmp-*/*", name: "/tmp-1/file", match: true},
+		{pattern: "/tmp-*/*", name: "/tmp-1/dir/file"},
+		{pattern: "/**/*.log", name: "/cnr/dir/app.log", match: true},
+		{pattern: "/**/*.log", name: "/cnr/dir/app.txt"},
+		{pattern: "/cnr/file", name: "/cnr/file", match: true},
+		{pattern: "/cnr/file", name: "/cnr"},
+	} {
+		require.Equal(t, tc.match, matchPath(tc.pattern, tc.name), "%s %s", tc.pattern, tc.name)
+	}
+}
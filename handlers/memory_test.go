@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/layer/layertest"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newMemoryApp returns the session App working with the in-memory layer.
+func newMemoryApp(t *testing.T, cfg *SftpServerConfig) (*App, *layertest.Memory) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	signer := user.NewAutoIDSignerRFC6979(key.PrivateKey)
+	owner := signer.UserID()
+
+	if cfg.BasicACL == 0 {
+		cfg.BasicACL = acl.PrivateExtended
+	}
+	cfg.ContainerWaiter.PollInterval = time.Millisecond
+
+	storage := layertest.NewMemory()
+	app := NewApp(storage, signer, &owner, zap.NewNop(), cfg, "REP 1").StartSession("alice", "192.0.2.1:50000", nil)
+	t.Cleanup(app.EndSession)
+	return app, storage
+}
+
+func listNames(t *testing.T, app *App, path string) []string {
+	lister, err := app.Filelist(sftp.NewRequest("List", path))
+	require.NoError(t, err)
+
+	files := make([]os.FileInfo, 100)
+	n, err := lister.ListAt(files, 0)
+	if !errors.Is(err, io.EOF) {
+		require.NoError(t, err)
+	}
+
+	names := make([]string, 0, n)
+	for _, f := range files[:n] {
+		names = append(names, f.Name())
+	}
+	return names
+}
+
+func uploadFile(t *testing.T, app *App, path, content string) {
+	w, err := app.Filewrite(sftp.NewRequest("Put", path))
+	require.NoError(t, err)
+	_, err = w.WriteAt([]byte(content), 0)
+	require.NoError(t, err)
+	require.NoError(t, w.(io.Closer).Close())
+}
+
+func downloadFile(t *testing.T, app *App, path string) string {
+	lister, err := app.Filelist(sftp.NewRequest("Stat", path))
+	require.NoError(t, err)
+	files := make([]os.FileInfo, 1)
+	_, err = lister.ListAt(files, 0)
+	if !errors.Is(err, io.EOF) {
+		require.NoError(t, err)
+	}
+
+	r, err := app.Fileread(sftp.NewRequest("Get", path))
+	require.NoError(t, err)
+	// Virtual files aren't closed.
+	if c, ok := r.(io.Closer); ok {
+		defer func() { require.NoError(t, c.Close()) }()
+	}
+
+	buf := make([]byte, files[0].Size())
+	n, err := r.ReadAt(buf, 0)
+	if !errors.Is(err, io.EOF) {
+		require.NoError(t, err)
+	}
+	return string(buf[:n])
+}
+
+func TestMemoryLayerFiles(t *testing.T) {
+	app, storage := newMemoryApp(t, &SftpServerConfig{})
+
+	require.NoError(t, app.Filecmd(sftp.NewRequest("Mkdir", "/docs")))
+	require.NoError(t, app.Filecmd(sftp.NewRequest("Mkdir", "/archive")))
+	require.Subset(t, listNames(t, app, "/"), []string{"docs", "archive", neofsDir})
+
+	uploadFile(t, app, "/docs/notes/a.txt", "first file")
+	uploadFile(t, app, "/docs/b.txt", "second file")
+	require.ElementsMatch(t, []string{"notes/a.txt", "b.txt"}, listNames(t, app, "/docs"))
+	require.Contains(t, downloadFile(t, app, "/docs/"+infoFile), "objects: 2")
+
+	cnr, err := app.getContainerByName(context.Background(), "docs")
+	require.NoError(t, err)
+	objects := storage.Objects(cnr.CID)
+	require.Len(t, objects, 2)
+	id, _ := objects[0].ID()
+	require.Equal(t, "first file", downloadFile(t, app, "/docs/"+id.EncodeToString()))
+
+	t.Run("rename", func(t *testing.T) {
+		r := sftp.NewRequest("Rename", "/docs/b.txt")
+		r.Target = "/archive/old/b.txt"
+		require.NoError(t, app.Filecmd(r))
+
+		require.NotContains(t, listNames(t, app, "/docs"), "b.txt")
+		require.Contains(t, listNames(t, app, "/archive"), "old/b.txt")
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		require.NoError(t, app.Filecmd(sftp.NewRequest("Remove", "/docs/notes/a.txt")))
+		require.Empty(t, listNames(t, app, "/docs"))
+
+		require.NoError(t, app.Filecmd(sftp.NewRequest("Rmdir", "/docs")))
+		require.NotContains(t, listNames(t, app, "/"), "docs")
+	})
+}
+
+func TestSearchByName(t *testing.T) {
+	app, storage := newMemoryApp(t, &SftpServerConfig{})
+	ctx := context.Background()
+	require.NoError(t, app.Filecmd(sftp.NewRequest("Mkdir", "/docs")))
+	uploadFile(t, app, "/docs/dir/a.txt", "with path")
+
+	cnr, err := app.getContainerByName(ctx, "docs")
+	require.NoError(t, err)
+	// Objects put by other tools may have no FilePath.
+	hdr := object.New()
+	hdr.SetContainerID(cnr.CID)
+	hdr.SetAttributes(newAttribute(object.AttributeFileName, "b.txt"))
+	_, err = storage.ObjectPut(ctx, *hdr, nil, client.PrmObjectPutInit{}, strings.NewReader("without path"))
+	require.NoError(t, err)
+
+	for name, found := range map[string]bool{"dir/a.txt": true, "b.txt": true, "a.txt": false, "dir": false} {
+		objects, err := app.searchObjects(ctx, cnr.CID, name)
+		require.NoError(t, err)
+		if !found {
+			require.Empty(t, objects, name)
+			continue
+		}
+		require.Len(t, objects, 1, name)
+		require.Equal(t, name, objects[0].Name())
+	}
+
+	require.NoError(t, app.Filecmd(sftp.NewRequest("Remove", "/docs/b.txt")))
+	require.Equal(t, []string{"dir/a.txt"}, listNames(t, app, "/docs"))
+}
+
+func TestMemoryLayerErrors(t *testing.T) {
+	app, storage := newMemoryApp(t, &SftpServerConfig{})
+	require.NoError(t, app.Filecmd(sftp.NewRequest("Mkdir", "/docs")))
+
+	_, err := app.Filelist(sftp.NewRequest("List", "/missing"))
+	require.Error(t, err)
+	require.ErrorIs(t, app.Filecmd(sftp.NewRequest("Mkdir", "/.neofs/dir")), sftp.ErrSSHFxPermissionDenied)
+	_, err = app.Filewrite(sftp.NewRequest("Put", "/docs/"+infoFile))
+	require.ErrorIs(t, err, sftp.ErrSSHFxPermissionDenied)
+
+	failure := errors.New("node is down")
+	storage.SetError("ObjectPut", failure)
+	w, err := app.Filewrite(sftp.NewRequest("Put", "/docs/file"))
+	require.NoError(t, err)
+	require.ErrorIs(t, w.(io.Closer).Close(), failure)
+	require.Empty(t, listNames(t, app, "/docs"))
+
+	storage.SetError("ObjectPut", nil)
+	uploadFile(t, app, "/docs/file", "content")
+	require.Equal(t, []string{"file"}, listNames(t, app, "/docs"))
+	require.Error(t, app.Filecmd(sftp.NewRequest("Remove", "/docs/missing")))
+
+	t.Run("quota", func(t *testing.T) {
+		app, _ := newMemoryApp(t, &SftpServerConfig{QuotaRules: []QuotaRule{{Pattern: "*", Size: 10}}})
+		require.NoError(t, app.Filecmd(sftp.NewRequest("Mkdir", "/docs")))
+
+		first, err := app.Filewrite(sftp.NewRequest("Put", "/docs/first"))
+		require.NoError(t, err)
+		second, err := app.Filewrite(sftp.NewRequest("Put", "/docs/second"))
+		require.NoError(t, err)
+
+		_, err = first.WriteAt([]byte("123456"), 0)
+		require.NoError(t, err)
+		_, err = second.WriteAt([]byte("123456"), 0)
+		require.ErrorIs(t, err, errQuotaExceeded)
+		second.(sftp.TransferError).TransferError(err)
+		require.Error(t, second.(io.Closer).Close())
+		require.NoError(t, first.(io.Closer).Close())
+
+		uploadFile(t, app, "/docs/third", "1234")
+		_, err = app.Filewrite(sftp.NewRequest("Put", "/docs/fourth"))
+		require.ErrorIs(t, err, errQuotaExceeded)
+	})
+
+	t.Run("denied by eACL", func(t *testing.T) {
+		record := eacl.NewRecord()
+		record.SetOperation(eacl.OperationPut)
+		record.SetAction(eacl.ActionDeny)
+		eacl.AddFormedTarget(record, eacl.RoleUser)
+		table := eacl.NewTable()
+		table.AddRecord(record)
+
+		app, _ := newMemoryApp(t, &SftpServerConfig{EACL: table})
+		require.NoError(t, app.Filecmd(sftp.NewRequest("Mkdir", "/docs")))
+
+		_, err := app.Filewrite(sftp.NewRequest("Put", "/docs/file"))
+		require.ErrorIs(t, err, sftp.ErrSSHFxPermissionDenied)
+		require.Equal(t, uint32(fxPermissionDenied), statusCode(err))
+	})
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"path"
+	"time"
+
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"go.uber.org/zap"
+)
+
+// mirrorTimeout limits copying the uploaded file into the mirror container.
+const mirrorTimeout = 10 * time.Minute
+
+// MirrorRule copies the files uploaded to containers with names matching the pattern
+// (see path.Match) into the target container, referenced by name or ID.
+type MirrorRule struct {
+	Pattern string
+	Target  string
+}
+
+// mirrorRule returns the first mirror rule matching the container name, nil if there is none.
+func (a *App) mirrorRule(cnrName string) *MirrorRule {
+	rules := a.config().MirrorRules
+	for i := range rules {
+		// Patterns are validated on configuration.
+		if ok, _ := path.Match(rules[i].Pattern, cnrName); ok {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// mirrorUpload copies the uploaded file into the target container of the matching mirror rule
+// in background, EndSession and Shutdown wait for the copy. Failures are logged only, the upload
+// is successful anyway.
+func (a *App) mirrorUpload(ctx context.Context, cnr *ContainerInfo, obj *ObjectInfo) {
+	rule := a.mirrorRule(cnr.Name())
+	if rule == nil {
+		return
+	}
+
+	l := requestLogger(ctx).With(
+		zap.String("file", obj.FilePath),
+		zap.Stringer("object", obj.ObjectID),
+		zap.String("target", rule.Target))
+	address := newAddress(cnr.CID, obj.ObjectID)
+
+	// The request context is done once the upload is finished.
+	bgCtx, cancel := context.WithTimeout(detachRequest(ctx), mirrorTimeout)
+	a.goBackground(func() {
+		defer cancel()
+
+		target, err := a.getContainerByName(bgCtx, rule.Target)
+		if err != nil {
+			l.Error("failed to get mirror container", zap.Error(err))
+			return
+		}
+		if target.CID.Equals(cnr.CID) {
+			return
+		}
+
+		var id oid.ID
+		err = withSessionRenewal(l, func() error {
+			id, err = a.putObjectCopy(bgCtx, address, target.CID, nil)
+			return err
+		})
+		if err != nil {
+			l.Error("failed to mirror upload", zap.Error(err))
+			return
+		}
+
+		l.Debug("upload mirrored", zap.Stringer("mirror_object", id))
+		a.emitEvent(bgCtx, EventUpload, delimiter+path.Join(target.Name(), obj.FilePath), target.CID, &id, obj.PayloadSize)
+	})
+}
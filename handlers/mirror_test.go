@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMirrorRule(t *testing.T) {
+	app := NewApp(nil, nil, new(user.ID), zap.NewNop(), &SftpServerConfig{
+		MirrorRules: []MirrorRule{
+			{Pattern: "backups", Target: "backups-mirror"},
+			{Pattern: "photos-*", Target: "photos-archive"},
+		},
+	}, "")
+
+	require.Equal(t, "backups-mirror", app.mirrorRule("backups").Target)
+	require.Equal(t, "photos-archive", app.mirrorRule("photos-2023").Target)
+	require.Nil(t, app.mirrorRule("backups-mirror"))
+}
+
+func TestMirrorUpload(t *testing.T) {
+	app, storage := newMemoryApp(t, &SftpServerConfig{
+		MirrorRules: []MirrorRule{{Pattern: "photos", Target: "archive"}},
+	})
+	require.NoError(t, app.Filecmd(sftp.NewRequest("Mkdir", "/photos")))
+	require.NoError(t, app.Filecmd(sftp.NewRequest("Mkdir", "/archive")))
+
+	uploadFile(t, app, "/photos/cat.jpg", "meow")
+	// The session ends once the copies are finished.
+	app.EndSession()
+
+	require.Equal(t, []string{"cat.jpg"}, listNames(t, app, "/archive"))
+	archive, err := app.getContainerByName(context.Background(), "archive")
+	require.NoError(t, err)
+	objects := storage.Objects(archive.CID)
+	require.Len(t, objects, 1)
+	id, _ := objects[0].ID()
+	require.Equal(t, "meow", downloadFile(t, app, "/archive/"+id.EncodeToString()))
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/sftp"
+)
+
+// containerDirName returns the name of the container created for the directory,
+// only first level directories are supported.
+func containerDirName(filePath string) (string, error) {
+	// valid Filepath "/somedir" or "somedir".
+	name := strings.TrimPrefix(filePath, delimiter)
+	// invalid "/somedir/subdir", "somedir/subdir"
+	if strings.Contains(name, delimiter) {
+		return "", fmt.Errorf("supported only first level dirs")
+	}
+	if name == "" || isNeofsPath(name) {
+		return "", sftp.ErrSSHFxPermissionDenied
+	}
+	return name, nil
+}
+
+// mkdirExtension creates the container with the settings from the request: the placement policy
+// (or its alias), the basic ACL and the number of attributes followed by their key-value pairs.
+// Empty policy and basic ACL mean the ones Mkdir would use, the attributes are added to
+// the configured ones overriding the ones with the same keys.
+func (a *App) mkdirExtension(ctx context.Context, filePath string, data []byte) ([]byte, error) {
+	if a.isReadOnly(filePath) {
+		return nil, sftp.ErrSSHFxPermissionDenied
+	}
+
+	name, err := containerDirName(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, data, err := unmarshalString(data)
+	if err != nil {
+		return nil, err
+	}
+	basicACL, data, err := unmarshalString(data)
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := unmarshalAttributes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	params := a.newContainerParams(name)
+	if policy != "" {
+		params.policy = a.resolvePolicy(policy)
+	}
+	if basicACL != "" {
+		if err = params.basicACL.DecodeString(basicACL); err != nil {
+			return nil, fmt.Errorf("invalid basic ACL: %w", err)
+		}
+	}
+	params.attributes = append(params.attributes, attrs...)
+
+	return nil, a.putContainer(ctx, name, *a.owner, params)
+}
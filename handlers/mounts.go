@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+)
+
+// Mount is a container shown in the root directory under the name regardless of its owner.
+type Mount struct {
+	Name      string
+	Container cid.ID
+	// BearerToken is attached to object requests to the container, nil if not needed.
+	BearerToken *bearer.Token
+	// Signer signs object requests to the container instead of the session signer, nil if not needed.
+	Signer user.Signer
+}
+
+// mountByName returns the mount with the name, nil if there is no such mount.
+func (a *App) mountByName(name string) *Mount {
+	mounts := a.config().Mounts
+	for i := range mounts {
+		if mounts[i].Name == name {
+			return &mounts[i]
+		}
+	}
+	return nil
+}
+
+// bearerToken returns the bearer token of the mounted container, nil if it's not needed.
+func (a *App) bearerToken(cnrID cid.ID) *bearer.Token {
+	mounts := a.config().Mounts
+	for i := range mounts {
+		if mounts[i].Container.Equals(cnrID) {
+			return mounts[i].BearerToken
+		}
+	}
+	return nil
+}
+
+// signerFor returns the signer of object requests to the container: the signer of the mount
+// if it's set, the session signer otherwise.
+func (a *App) signerFor(cnrID cid.ID) user.Signer {
+	mounts := a.config().Mounts
+	for i := range mounts {
+		if mounts[i].Container.Equals(cnrID) && mounts[i].Signer != nil {
+			return mounts[i].Signer
+		}
+	}
+	return a.signer
+}
+
+// ownerFor returns the owner of objects put to the container, it's the account of the signer.
+func (a *App) ownerFor(cnrID cid.ID) *user.ID {
+	mounts := a.config().Mounts
+	for i := range mounts {
+		if mounts[i].Container.Equals(cnrID) && mounts[i].Signer != nil {
+			owner := mounts[i].Signer.UserID()
+			return &owner
+		}
+	}
+	return a.owner
+}
+
+// getMountedContainer returns the container of the mount named after the mount.
+func (a *App) getMountedContainer(ctx context.Context, mount *Mount) (*ContainerInfo, error) {
+	cnr, err := a.getContainer(ctx, mount.Container)
+	if err != nil {
+		return nil, err
+	}
+	cnr.FileName = mount.Name
+	cnr.Mounted = true
+	return cnr, nil
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestSigner(t *testing.T) user.Signer {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	return user.NewAutoIDSignerRFC6979(key.PrivateKey)
+}
+
+func TestSigners(t *testing.T) {
+	gateway, alice, partner := newTestSigner(t), newTestSigner(t), newTestSigner(t)
+	gatewayID, aliceID, partnerID := gateway.UserID(), alice.UserID(), partner.UserID()
+	mounted, other := cidtest.ID(), cidtest.ID()
+
+	app := NewApp(nil, gateway, &gatewayID, zap.NewNop(), &SftpServerConfig{
+		UserSigners: map[string]user.Signer{"alice": alice},
+		Mounts:      []Mount{{Name: "partner", Container: mounted, Signer: partner}},
+	}, "")
+
+	require.Equal(t, partnerID, *app.ownerFor(mounted))
+	require.Equal(t, gatewayID, *app.ownerFor(other))
+	require.Equal(t, gatewayID, app.signerFor(other).UserID())
+
+	sess := app.StartSession("Alice", "", nil)
+	defer sess.EndSession()
+	require.Equal(t, aliceID, *sess.owner)
+	require.Equal(t, aliceID, *sess.ownerFor(other))
+	require.Equal(t, partnerID, sess.signerFor(mounted).UserID())
+}
+
+func TestListBrokenMount(t *testing.T) {
+	app, _ := newMemoryApp(t, &SftpServerConfig{
+		Mounts: []Mount{{Name: "partner", Container: cidtest.ID()}},
+	})
+	require.NoError(t, app.Filecmd(sftp.NewRequest("Mkdir", "/docs")))
+
+	names := listNames(t, app, "/")
+	require.Contains(t, names, "docs")
+	require.NotContains(t, names, "partner")
+}
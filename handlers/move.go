@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/pkg/sftp"
+)
+
+// moveObject renames the file, the target may be in another container. The object is copied
+// with the attributes, including the timestamp, and the original object is deleted.
+func (a *App) moveObject(ctx context.Context, source, target string) error {
+	targetCnrName, targetName, _ := strings.Cut(strings.TrimPrefix(target, delimiter), delimiter)
+	if targetName == "" {
+		return sftp.ErrSSHFxOpUnsupported
+	}
+	if isVersionPath(target) || isTrashPath(target) {
+		return sftp.ErrSSHFxPermissionDenied
+	}
+
+	obj, err := a.getObjectByPath(ctx, source)
+	if err != nil {
+		return err
+	}
+	targetCnr, err := a.getContainerByName(ctx, targetCnrName)
+	if err != nil {
+		return err
+	}
+
+	return a.replaceObject(ctx, obj, targetCnr.CID, source, target, []Attribute{
+		{Key: object.AttributeFileName, Value: path.Base(targetName)},
+		{Key: filePathAttribute, Value: targetName},
+	})
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import "sort"
+
+const (
+	// duplicateSeparator separates the friendly name of the container from the prefix of its ID
+	// when several containers share the name, e.g. `backups~5DfuKc3d`.
+	duplicateSeparator = "~"
+	// duplicateIDPrefix is the length of the container ID prefix added to duplicate names.
+	duplicateIDPrefix = 8
+)
+
+// sortContainers sorts the containers of the owner from the oldest one, so that the same
+// container keeps the friendly name whatever order NeoFS lists them in.
+func sortContainers(containers []*ContainerInfo) {
+	sort.SliceStable(containers, func(i, j int) bool {
+		if !containers[i].Created.Equal(containers[j].Created) {
+			return containers[i].Created.Before(containers[j].Created)
+		}
+		return containers[i].CID.EncodeToString() < containers[j].CID.EncodeToString()
+	})
+}
+
+// disambiguateNames keeps the name of the first container with the name and suffixes
+// the names of the following ones with the prefix of their IDs, or the whole IDs if the
+// suffixed names are taken too, so every container remains addressable.
+func disambiguateNames(containers []*ContainerInfo) {
+	seen := make(map[string]struct{}, len(containers))
+	for _, cnr := range containers {
+		if _, ok := seen[cnr.FileName]; ok {
+			id := cnr.CID.EncodeToString()
+			cnr.FileName += duplicateSeparator + id[:duplicateIDPrefix]
+			if _, ok = seen[cnr.FileName]; ok {
+				cnr.FileName = id
+			}
+		}
+		seen[cnr.FileName] = struct{}{}
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisambiguateNames(t *testing.T) {
+	newer := &ContainerInfo{CID: cidtest.ID(), FileName: "backups", Created: time.Unix(2000, 0)}
+	older := &ContainerInfo{CID: cidtest.ID(), FileName: "backups", Created: time.Unix(1000, 0)}
+	other := &ContainerInfo{CID: cidtest.ID(), FileName: "photos", Created: time.Unix(3000, 0)}
+
+	containers := []*ContainerInfo{newer, other, older}
+	sortContainers(containers)
+	require.Equal(t, []*ContainerInfo{older, newer, other}, containers)
+
+	disambiguateNames(containers)
+	require.Equal(t, "backups", older.Name())
+	require.Equal(t, "backups~"+newer.CID.EncodeToString()[:duplicateIDPrefix], newer.Name())
+	require.Equal(t, "photos", other.Name())
+
+	// The suffixed name is taken by another container.
+	taken := &ContainerInfo{CID: cidtest.ID(), FileName: "a"}
+	duplicate := &ContainerInfo{CID: cidtest.ID(), FileName: "a"}
+	squatter := &ContainerInfo{CID: cidtest.ID(), FileName: "a~" + duplicate.CID.EncodeToString()[:duplicateIDPrefix]}
+	disambiguateNames([]*ContainerInfo{taken, squatter, duplicate})
+	require.Equal(t, duplicate.CID.EncodeToString(), duplicate.Name())
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+	"go.uber.org/zap"
+)
+
+// neofsDir is the virtual read-only root directory describing the network, e.g. /.neofs/epoch.
+const neofsDir = ".neofs"
+
+// neofsFiles returns the files of the network info directory, the values are refreshed
+// at most once in netInfoTTL.
+func (a *App) neofsFiles(ctx context.Context) ([]*virtualFile, error) {
+	ni, fetched, err := a.cachedNetworkInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []struct{ name, value string }{
+		{"epoch", strconv.FormatUint(ni.CurrentEpoch(), 10)},
+		{"epoch_duration", strconv.FormatUint(ni.EpochDuration(), 10) + " blocks, " + epochDuration(ni).String()},
+		{"max_object_size", strconv.FormatUint(ni.MaxObjectSize(), 10)},
+		{"storage_price", strconv.FormatUint(ni.StoragePrice(), 10)},
+		{"container_fee", strconv.FormatUint(ni.ContainerFee(), 10)},
+		{"peers", strings.Join(a.config().Peers, "\n")},
+	}
+
+	files := make([]*virtualFile, 0, len(values)+1)
+	for _, v := range values {
+		files = append(files, &virtualFile{
+			name:    v.name,
+			content: []byte(v.value + "\n"),
+			modTime: fetched,
+		})
+	}
+
+	// The network info is shown even if the balance can't be fetched.
+	balance, err := a.balanceFile(ctx)
+	if err != nil {
+		requestLogger(ctx).Warn("failed to get balance", zap.Error(err))
+		return files, nil
+	}
+	return append(files, balance), nil
+}
+
+func neofsDirInfo() *ContainerInfo {
+	return &ContainerInfo{FileName: neofsDir, Created: time.Now()}
+}
+
+// isNeofsPath checks whether the full path is inside the network info directory.
+func isNeofsPath(fullPath string) bool {
+	root, _, _ := strings.Cut(strings.TrimPrefix(fullPath, delimiter), delimiter)
+	return root == neofsDir
+}
+
+func (a *App) listNeofsDir(ctx context.Context) ([]os.FileInfo, error) {
+	files, err := a.neofsFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]os.FileInfo, 0, len(files))
+	for _, f := range files {
+		result = append(result, f)
+	}
+	return result, nil
+}
+
+// neofsStat returns the information about the file of the network info directory.
+func (a *App) neofsStat(ctx context.Context, name string) (os.FileInfo, error) {
+	if name == "" {
+		return neofsDirInfo(), nil
+	}
+
+	files, err := a.neofsFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.name == name {
+			return f, nil
+		}
+	}
+	return nil, sftp.ErrSSHFxNoSuchFile
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsNeofsPath(t *testing.T) {
+	require.True(t, isNeofsPath("/.neofs"))
+	require.True(t, isNeofsPath("/.neofs/epoch"))
+	require.True(t, isNeofsPath(".neofs/peers"))
+	require.False(t, isNeofsPath("/"))
+	require.False(t, isNeofsPath("/cnr/.neofs"))
+	require.False(t, isNeofsPath("/.neofs-backups/epoch"))
+}
+
+func TestListNeofsDirWithoutBalance(t *testing.T) {
+	app, storage := newMemoryApp(t, &SftpServerConfig{})
+	storage.SetError("BalanceGet", errors.New("node is down"))
+	names := listNames(t, app, "/"+neofsDir)
+	require.Contains(t, names, "epoch")
+	require.NotContains(t, names, "balance")
+
+	// The balance is requested again since it isn't cached.
+	storage.SetError("BalanceGet", nil)
+	require.Contains(t, listNames(t, app, "/"+neofsDir), "balance")
+}
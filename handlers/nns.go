@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrDomainNotFound is returned by NNSResolver if the domain isn't registered or has no container ID.
+var ErrDomainNotFound = errors.New("domain not found")
+
+// NNSResolver resolves NNS domains to the IDs of containers registered with them.
+type NNSResolver interface {
+	ResolveContainerDomain(ctx context.Context, domain string) (cid.ID, error)
+}
+
+// SetNNSResolver enables addressing containers by their NNS domains, e.g. `/data.mycompany/file`.
+// It must be set before sessions are started.
+func (a *App) SetNNSResolver(resolver NNSResolver) {
+	a.nns = resolver
+}
+
+// resolveContainerDomain returns the ID of the container registered with the domain.
+// Only names with a zone are resolved, false is returned otherwise or if the domain isn't found.
+func (a *App) resolveContainerDomain(ctx context.Context, name string) (cid.ID, bool, error) {
+	if a.nns == nil || !strings.Contains(name, ".") {
+		return cid.ID{}, false, nil
+	}
+
+	ctx, span := startSpan(ctx, "nns.resolve", attribute.String("nns.domain", name))
+	cnrID, err := a.nns.ResolveContainerDomain(ctx, name)
+	if errors.Is(err, ErrDomainNotFound) {
+		endSpan(span, nil)
+		return cid.ID{}, false, nil
+	}
+	endSpan(span, err)
+	if err != nil {
+		return cid.ID{}, false, fmt.Errorf("resolve NNS domain: %w", err)
+	}
+
+	return cnrID, true, nil
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+)
+
+type mapResolver map[string]cid.ID
+
+func (r mapResolver) ResolveContainerDomain(ctx context.Context, domain string) (cid.ID, error) {
+	if err := ctx.Err(); err != nil {
+		return cid.ID{}, err
+	}
+	if domain == "broken.company" {
+		return cid.ID{}, errors.New("RPC is unavailable")
+	}
+	cnrID, ok := r[domain]
+	if !ok {
+		return cid.ID{}, ErrDomainNotFound
+	}
+	return cnrID, nil
+}
+
+func TestResolveContainerDomain(t *testing.T) {
+	app, _ := newMemoryApp(t, &SftpServerConfig{})
+	require.NoError(t, app.Filecmd(sftp.NewRequest("Mkdir", "/docs")))
+
+	ctx := context.Background()
+	cnr, err := app.getContainerByName(ctx, "docs")
+	require.NoError(t, err)
+	app.SetNNSResolver(mapResolver{"docs.company": cnr.CID})
+
+	resolved, err := app.getContainerByName(ctx, "docs.company")
+	require.NoError(t, err)
+	require.Equal(t, cnr.CID, resolved.CID)
+
+	_, ok, err := app.resolveContainerDomain(ctx, "missing.company")
+	require.NoError(t, err)
+	require.False(t, ok)
+	_, err = app.getContainerByName(ctx, "missing.company")
+	require.EqualError(t, err, "not found")
+
+	_, err = app.getContainerByName(ctx, "broken.company")
+	require.ErrorContains(t, err, "RPC is unavailable")
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, _, err = app.resolveContainerDomain(canceled, "docs.company")
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+)
+
+// ErrECPolicyUnsupported is returned for erasure-coded placement policies, they aren't supported
+// by the NeoFS SDK the gateway is built with.
+var ErrECPolicyUnsupported = errors.New("erasure-coded placement policies are not supported")
+
+// ecClause matches `EC <data>/<parity>` replica clauses of placement policies.
+var ecClause = regexp.MustCompile(`(?i)(^|\s)EC\s+\d+\s*/\s*\d+`)
+
+// DecodePolicy parses the placement policy, ErrECPolicyUnsupported is returned for erasure-coded ones.
+func DecodePolicy(s string) (netmap.PlacementPolicy, error) {
+	var policy netmap.PlacementPolicy
+	if ecClause.MatchString(s) {
+		return policy, ErrECPolicyUnsupported
+	}
+	if err := policy.DecodeString(s); err != nil {
+		return policy, fmt.Errorf("invalid placement policy: %w", err)
+	}
+	return policy, nil
+}
+
+// formatPolicy returns the placement policy in the readable form, one clause per line.
+func formatPolicy(policy netmap.PlacementPolicy) string {
+	var b strings.Builder
+	if err := policy.WriteStringTo(&b); err != nil {
+		return ""
+	}
+	return b.String()
+}
+
+// Separators of the placement policy set inline in the directory name on Mkdir:
+// `backups@REP3` or `archive#policy=cold`.
+const (
+	inlinePolicySeparator = "#policy="
+	inlinePolicyShort     = "@"
+)
+
+// repShorthand matches `REP3`, the short form of `REP 3` policy usable in directory names.
+var repShorthand = regexp.MustCompile(`(?i)^REP(\d+)$`)
+
+// splitInlinePolicy splits the directory name into the container name and the placement policy
+// (or its alias) set inline. The name is split only if the suffix is one of the aliases or a valid
+// policy, so `mail@example.org` stays as is. The policy is empty if it isn't set.
+func splitInlinePolicy(dir string, aliases map[string]string) (name, policy string) {
+	if i := strings.Index(dir, inlinePolicySeparator); i > 0 {
+		name, policy = dir[:i], dir[i+len(inlinePolicySeparator):]
+	} else if i = strings.LastIndex(dir, inlinePolicyShort); i > 0 {
+		name, policy = dir[:i], dir[i+len(inlinePolicyShort):]
+	}
+	if policy == "" {
+		return dir, ""
+	}
+	if m := repShorthand.FindStringSubmatch(policy); m != nil {
+		policy = "REP " + m[1]
+	}
+	if _, ok := aliases[strings.ToLower(policy)]; ok {
+		return name, policy
+	}
+	// Erasure-coded policies are split to be rejected on the container creation.
+	if _, err := DecodePolicy(policy); err != nil && !errors.Is(err, ErrECPolicyUnsupported) {
+		return dir, ""
+	}
+	return name, policy
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodePolicy(t *testing.T) {
+	_, err := DecodePolicy("REP 3")
+	require.NoError(t, err)
+
+	for _, policy := range []string{"EC 4/2", "ec 6 / 3 IN X SELECT 9 FROM * AS X", "REP 1 EC 4/2"} {
+		_, err = DecodePolicy(policy)
+		require.ErrorIs(t, err, ErrECPolicyUnsupported, policy)
+	}
+
+	_, err = DecodePolicy("REP")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrECPolicyUnsupported)
+}
+
+func TestSplitInlinePolicy(t *testing.T) {
+	aliases := map[string]string{"gold": "REP 3", "cold": "REP 1"}
+	for dir, expected := range map[string][2]string{
+		"backups":                       {"backups", ""},
+		"backups@REP3":                  {"backups", "REP 3"},
+		"backups@rep2":                  {"backups", "REP 2"},
+		"backups@gold":                  {"backups", "gold"},
+		"a@b@REP1":                      {"a@b", "REP 1"},
+		"archive#policy=cold":           {"archive", "cold"},
+		"archive#policy=REP 2 CBF 1":    {"archive", "REP 2 CBF 1"},
+		"archive#policy=EC 4/2":         {"archive", "EC 4/2"},
+		"mail@example.org#policy=R1":    {"mail@example.org#policy=R1", ""},
+		"mail@example.org#policy=REP 1": {"mail@example.org", "REP 1"},
+		"mail@example.org":              {"mail@example.org", ""},
+		"backups@silver":                {"backups@silver", ""},
+		"backups@":                      {"backups@", ""},
+		"@REP3":                         {"@REP3", ""},
+		"#policy=REP3":                  {"#policy=REP3", ""},
+	} {
+		name, policy := splitInlinePolicy(dir, aliases)
+		require.Equal(t, expected[0], name, dir)
+		require.Equal(t, expected[1], policy, dir)
+	}
+}
+
+func TestFormatPolicy(t *testing.T) {
+	policy, err := DecodePolicy("REP 2 IN X CBF 3 SELECT 2 FROM * AS X")
+	require.NoError(t, err)
+	require.Equal(t, "REP 2 IN X\nCBF 3\nSELECT 2 FROM * AS X", formatPolicy(policy))
+
+	info := newInfoFile(&ContainerInfo{FileName: "photos", Policy: formatPolicy(policy)}, ContainerUsage{})
+	require.Contains(t, string(info.content), "policy:\n  REP 2 IN X\n  CBF 3\n  SELECT 2 FROM * AS X\n")
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+)
+
+// queryPrefix starts the virtual directory of the container listing only the objects
+// matching the attribute filters, e.g. /<container>/?X-Project=alpha&Owner!=bob.
+const queryPrefix = "?"
+
+var errEmptyQuery = errors.New("empty attribute query")
+
+// isQuery checks whether the path element is an attribute query.
+func isQuery(elem string) bool {
+	return strings.HasPrefix(elem, queryPrefix)
+}
+
+// parseQuery converts the attribute query into search filters. `key=value` matches objects
+// having the attribute, `key!=value` matches the ones not having it. Filters are ANDed, so
+// repeated keys are rejected: `a=1&a=2` would never match.
+func parseQuery(elem string) (object.SearchFilters, error) {
+	values, err := url.ParseQuery(strings.TrimPrefix(elem, queryPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("invalid attribute query: %w", err)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	filters := object.NewSearchFilters()
+	for _, key := range keys {
+		match := object.MatchStringEqual
+		attr := key
+		if strings.HasSuffix(key, "!") {
+			match = object.MatchStringNotEqual
+			attr = strings.TrimSuffix(key, "!")
+		}
+		if attr == "" {
+			return nil, fmt.Errorf("invalid attribute query: empty key")
+		}
+		if len(values[key]) > 1 {
+			return nil, fmt.Errorf("invalid attribute query: repeated key %s", key)
+		}
+		filters.AddFilter(attr, values[key][0], match)
+	}
+	if len(filters) == 0 {
+		return nil, errEmptyQuery
+	}
+	return filters, nil
+}
+
+// withoutQuery removes the attribute query element of the container path,
+// files of the query directory are the files of the container.
+func withoutQuery(path string) string {
+	cnrName, rest, ok := strings.Cut(path, delimiter)
+	if !ok {
+		return path
+	}
+	if elem, file, ok := strings.Cut(rest, delimiter); ok && isQuery(elem) {
+		return cnrName + delimiter + file
+	}
+	return path
+}
+
+// queryDirInfo returns the information about the attribute query directory.
+func queryDirInfo(cnr *ContainerInfo, query string) (*ContainerInfo, error) {
+	if _, err := parseQuery(query); err != nil {
+		return nil, err
+	}
+	info := *cnr
+	info.FileName = query
+	return &info, nil
+}
+
+// listQuery lists the current versions of files having the attributes from the query.
+func (a *App) listQuery(ctx context.Context, cnr *ContainerInfo, query string) ([]os.FileInfo, error) {
+	filters, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := a.searchByAttributes(ctx, cnr.CID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []os.FileInfo
+	for _, versions := range groupVersions(objects) {
+		result = append(result, versions[0])
+	}
+	return result, nil
+}
+
+// searchByAttributes returns the root objects of the container matching the filters
+// except the trashed ones.
+func (a *App) searchByAttributes(ctx context.Context, cnrID cid.ID, filters object.SearchFilters) ([]*ObjectInfo, error) {
+	filters.AddRootFilter()
+
+	ids, err := a.searchIDs(ctx, cnrID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*ObjectInfo, 0, len(ids))
+	for _, id := range ids {
+		obj, err := a.getObjectFile(ctx, newAddress(cnrID, id))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, obj)
+	}
+
+	files, _ := splitTrashed(a.withoutExpired(ctx, result))
+	return files, nil
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuery(t *testing.T) {
+	filters, err := parseQuery("?X-Project=alpha&Owner!=bob&X-Project!=beta")
+	require.NoError(t, err)
+	require.Len(t, filters, 3)
+
+	require.Equal(t, "Owner", filters[0].Header())
+	require.Equal(t, "bob", filters[0].Value())
+	require.Equal(t, object.MatchStringNotEqual, filters[0].Operation())
+
+	require.Equal(t, "X-Project", filters[1].Header())
+	require.Equal(t, "alpha", filters[1].Value())
+	require.Equal(t, object.MatchStringEqual, filters[1].Operation())
+	require.Equal(t, "X-Project", filters[2].Header())
+	require.Equal(t, "beta", filters[2].Value())
+	require.Equal(t, object.MatchStringNotEqual, filters[2].Operation())
+
+	filters, err = parseQuery("?Name=a%20b")
+	require.NoError(t, err)
+	require.Equal(t, "a b", filters[0].Value())
+
+	_, err = parseQuery("?")
+	require.ErrorIs(t, err, errEmptyQuery)
+	_, err = parseQuery("?!=a")
+	require.Error(t, err)
+	_, err = parseQuery("?a=%zz")
+	require.Error(t, err)
+	_, err = parseQuery("?a=1&a=2")
+	require.ErrorContains(t, err, "repeated key")
+	_, err = parseQuery("?a!=1&a!=2")
+	require.ErrorContains(t, err, "repeated key")
+}
+
+func TestWithoutQuery(t *testing.T) {
+	for path, expected := range map[string]string{
+		"cnr":                       "cnr",
+		"cnr/file":                  "cnr/file",
+		"cnr/?a=b":                  "cnr/?a=b",
+		"cnr/?a=b/file":             "cnr/file",
+		"cnr/?a=b/.versions/file/1": "cnr/.versions/file/1",
+	} {
+		require.Equal(t, expected, withoutQuery(path), path)
+	}
+}
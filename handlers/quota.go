@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/pkg/sftp"
+)
+
+// QuotaRule limits the total payload size of containers with names matching the pattern (see path.Match).
+type QuotaRule struct {
+	Pattern string
+	Size    uint64
+}
+
+// errQuotaExceeded is replied with SSH_FX_FAILURE status and the message, pkg/sftp has no error
+// for SSH_FX_QUOTA_EXCEEDED.
+var errQuotaExceeded = fmt.Errorf("%w: quota exceeded", sftp.ErrSSHFxFailure)
+
+// containerQuota returns the quota of the first rule matching the container name, 0 if it's unlimited.
+func (a *App) containerQuota(name string) uint64 {
+	rules := a.config().QuotaRules
+	for i := range rules {
+		// Patterns are validated on configuration.
+		if ok, _ := path.Match(rules[i].Pattern, name); ok {
+			return rules[i].Size
+		}
+	}
+	return 0
+}
+
+// uploadQuota returns the quota of the container, 0 if it's unlimited. Uploads to the full
+// container are refused, the others reserve the space in the cached usage as they write.
+func (a *App) uploadQuota(ctx context.Context, cnr *ContainerInfo) (uint64, error) {
+	quota := a.containerQuota(cnr.Name())
+	if quota == 0 {
+		return 0, nil
+	}
+
+	if _, err := a.containerUsage(ctx, cnr.CID); err != nil {
+		return 0, fmt.Errorf("container usage: %w", err)
+	}
+	if err := a.usage.reserve(cnr.CID, 0, quota); err != nil {
+		return 0, fmt.Errorf("container %s: %w", cnr.Name(), err)
+	}
+	return quota, nil
+}
+
+// add accounts the new object in the cached usage of the container.
+func (c *usageCache) add(cnrID cid.ID, size uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if u, ok := c.containers[cnrID]; ok {
+		u.Size += size
+		u.Objects++
+		c.containers[cnrID] = u
+	}
+}
+
+// reserve books the space for the upload to the container if the used space and the space
+// reserved by other uploads leave it within the quota. The container full up to the quota
+// is refused even if the size is 0.
+func (c *usageCache) reserve(cnrID cid.ID, size, quota uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	used := c.containers[cnrID].Size + c.reserved[cnrID]
+	if used >= quota || size > quota-used {
+		return fmt.Errorf("%w: %d of %d bytes are used or reserved", errQuotaExceeded, used, quota)
+	}
+	c.reserved[cnrID] += size
+	return nil
+}
+
+// release returns the space reserved by the finished upload, the stored object is accounted with add.
+func (c *usageCache) release(cnrID cid.ID, size uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reserved[cnrID] <= size {
+		delete(c.reserved, cnrID)
+		return
+	}
+	c.reserved[cnrID] -= size
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestUploadQuota(t *testing.T) {
+	app := NewApp(nil, nil, new(user.ID), zap.NewNop(), &SftpServerConfig{
+		QuotaRules: []QuotaRule{
+			{Pattern: "tmp-*", Size: 1000},
+			{Pattern: "*", Size: 5000},
+		},
+	}, "")
+	require.EqualValues(t, 1000, app.containerQuota("tmp-1"))
+	require.EqualValues(t, 5000, app.containerQuota("photos"))
+
+	cnr := &ContainerInfo{CID: cidtest.ID(), FileName: "tmp-1"}
+	app.usage.set(cnr.CID, ContainerUsage{Size: 400, Objects: 1})
+
+	quota, err := app.uploadQuota(context.Background(), cnr)
+	require.NoError(t, err)
+	require.EqualValues(t, 1000, quota)
+
+	app.usage.add(cnr.CID, 600)
+	u, _ := app.usage.get(cnr.CID)
+	require.EqualValues(t, 1000, u.Size)
+	require.EqualValues(t, 2, u.Objects)
+
+	_, err = app.uploadQuota(context.Background(), cnr)
+	require.ErrorIs(t, err, errQuotaExceeded)
+	require.ErrorIs(t, err, sftp.ErrSSHFxFailure)
+
+	app = NewApp(nil, nil, new(user.ID), zap.NewNop(), &SftpServerConfig{}, "")
+	quota, err = app.uploadQuota(context.Background(), cnr)
+	require.NoError(t, err)
+	require.Zero(t, quota, "unlimited")
+}
+
+func TestQuotaReservation(t *testing.T) {
+	cnrID := cidtest.ID()
+	c := newUsageCache()
+	c.set(cnrID, ContainerUsage{Size: 400})
+
+	// Concurrent uploads can't exceed the quota together.
+	require.NoError(t, c.reserve(cnrID, 300, 1000))
+	require.NoError(t, c.reserve(cnrID, 300, 1000))
+	require.ErrorIs(t, c.reserve(cnrID, 1, 1000), errQuotaExceeded)
+
+	// The first upload is stored.
+	c.add(cnrID, 300)
+	c.release(cnrID, 300)
+	require.ErrorIs(t, c.reserve(cnrID, 1, 1000), errQuotaExceeded)
+
+	// The second one fails.
+	c.release(cnrID, 300)
+	require.NoError(t, c.reserve(cnrID, 300, 1000))
+	require.ErrorIs(t, c.reserve(cnrID, 0, 1000), errQuotaExceeded, "full container")
+}
@@ -0,0 +1,21 @@
+package handlers
+
+// isReadOnly checks whether the server is read-only or any of the paths matches the read-only
+// patterns. Empty paths, e.g. the target of requests other than Rename, are ignored.
+func (a *App) isReadOnly(paths ...string) bool {
+	cfg := a.config()
+	if cfg.ReadOnly {
+		return true
+	}
+	for _, filePath := range paths {
+		if filePath == "" {
+			continue
+		}
+		for _, pattern := range cfg.ReadOnlyPaths {
+			if matchPath(pattern, filePath) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestIsReadOnly(t *testing.T) {
+	app := NewApp(nil, nil, new(user.ID), zap.NewNop(), &SftpServerConfig{
+		ReadOnlyPaths: []string{"/datasets/**", "/shared/releases/**", "/*"},
+	}, "")
+
+	require.True(t, app.isReadOnly("/datasets"))
+	require.True(t, app.isReadOnly("/datasets/2023/data.csv"))
+	require.True(t, app.isReadOnly("/shared/releases/v1.tar"))
+	require.True(t, app.isReadOnly("/uploads/file", "/datasets/file"))
+	require.True(t, app.isReadOnly("/new-container"))
+	require.False(t, app.isReadOnly("/shared/incoming/file"))
+	require.False(t, app.isReadOnly("/uploads/file", ""))
+
+	app.UpdateConfig(&SftpServerConfig{ReadOnly: true})
+	require.True(t, app.isReadOnly("/uploads/file"))
+}
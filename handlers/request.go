@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/pkg/sftp"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// errInternal is returned to the client when serving of the request panicked.
+var errInternal = errors.New("internal error")
+
+type (
+	requestIDKey   struct{}
+	loggerKey      struct{}
+	requestInfoKey struct{}
+
+	// requestInfo describes the SFTP request for the slow operations log.
+	requestInfo struct {
+		method  string
+		path    string
+		target  string
+		started time.Time
+	}
+)
+
+// startRequest returns the context of the SFTP request carrying the request ID, the logger
+// tagged with the session and the request IDs and the request span.
+func (a *App) startRequest(r *sftp.Request) (context.Context, trace.Span) {
+	id := newID()
+	l := a.Log.With(zap.String("request", id))
+
+	ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+	ctx = context.WithValue(ctx, loggerKey{}, l)
+	ctx = context.WithValue(ctx, requestInfoKey{}, requestInfo{
+		method:  r.Method,
+		path:    r.Filepath,
+		target:  r.Target,
+		started: time.Now(),
+	})
+
+	l.Debug("request", zap.String("method", r.Method), zap.String("path", r.Filepath))
+
+	return a.startRequestSpan(ctx, r, id)
+}
+
+// finishRequest logs, counts and audits the result of the SFTP request.
+func (a *App) finishRequest(ctx context.Context, r *sftp.Request, err error) {
+	a.session.addRequest(r.Method, err)
+	if err != nil {
+		requestLogger(ctx).Debug("request failed", zap.String("method", r.Method), zap.Error(err))
+	}
+	a.logSlowRequest(ctx, err)
+	a.auditRequest(ctx, r, err)
+}
+
+// logSlowRequest logs the SFTP request if it has taken longer than the configured threshold.
+func (a *App) logSlowRequest(ctx context.Context, err error) {
+	threshold := a.config().SlowThreshold
+	info, ok := ctx.Value(requestInfoKey{}).(requestInfo)
+	if threshold <= 0 || !ok {
+		return
+	}
+	duration := time.Since(info.started)
+	if duration <= threshold {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String("method", info.method),
+		zap.String("path", info.path),
+		zap.Duration("duration", duration),
+	}
+	if info.target != "" {
+		fields = append(fields, zap.String("target", info.target))
+	}
+	if a.session != nil {
+		fields = append(fields, zap.String("user", a.session.user), zap.String("remote", a.session.remote))
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	requestLogger(ctx).Warn("slow request", fields...)
+}
+
+// recoverRequest logs the panic occurred while serving the SFTP request and returns the error
+// for the client, so the gateway continues serving other requests.
+func (a *App) recoverRequest(ctx context.Context, r *sftp.Request, p any) error {
+	requestLogger(ctx).Error("panic while serving request",
+		zap.String("method", r.Method),
+		zap.String("path", r.Filepath),
+		zap.Any("panic", p),
+		zap.Stack("stack"))
+	return errInternal
+}
+
+// detachRequest returns the context of the work started by the SFTP request and going on after it's
+// finished, it isn't canceled with the request but carries its ID and logger.
+func detachRequest(ctx context.Context) context.Context {
+	bgCtx := context.WithValue(context.Background(), requestIDKey{}, requestID(ctx))
+	return context.WithValue(bgCtx, loggerKey{}, requestLogger(ctx))
+}
+
+// requestID returns the ID of the SFTP request ctx belongs to, empty if there is none.
+func requestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// requestLogger returns the logger of the SFTP request ctx belongs to, the global one if there is none.
+func requestLogger(ctx context.Context) *zap.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+		return l
+	}
+	return zap.L()
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/pkg/sftp"
+	"go.uber.org/zap"
+)
+
+// RmdirMode defines how Rmdir of a container treats its objects.
+type RmdirMode string
+
+// Supported Rmdir modes.
+const (
+	// RmdirDelete removes the container regardless of its objects, the network decides
+	// whether non-empty containers can be removed.
+	RmdirDelete RmdirMode = "delete"
+	// RmdirPurge deletes all the objects of the container before removing it.
+	RmdirPurge RmdirMode = "purge"
+	// RmdirStrict refuses to remove containers having objects.
+	RmdirStrict RmdirMode = "strict"
+)
+
+// errDirNotEmpty is replied with SSH_FX_FAILURE status and the message, pkg/sftp has no error
+// for SSH_FX_DIR_NOT_EMPTY.
+var errDirNotEmpty = fmt.Errorf("%w: directory isn't empty", sftp.ErrSSHFxFailure)
+
+// purgeProgressInterval is the number of deleted objects between progress log entries.
+const purgeProgressInterval = 100
+
+// removeContainer removes the container with its objects according to the Rmdir mode.
+func (a *App) removeContainer(ctx context.Context, cnrID cid.ID) error {
+	switch a.config().RmdirMode {
+	case RmdirPurge:
+		if err := a.purgeContainer(ctx, cnrID); err != nil {
+			return err
+		}
+	case RmdirStrict:
+		filters := object.NewSearchFilters()
+		filters.AddRootFilter()
+
+		objID, err := a.searchFirst(ctx, cnrID, filters)
+		if err != nil {
+			return err
+		}
+		if objID != nil {
+			return fmt.Errorf("container isn't empty: %w", errDirNotEmpty)
+		}
+	}
+	return a.deleteContainer(ctx, cnrID)
+}
+
+// purgeContainer deletes all the objects of the container.
+func (a *App) purgeContainer(ctx context.Context, cnrID cid.ID) error {
+	l := requestLogger(ctx).With(zap.Stringer("container", cnrID))
+
+	filters := object.NewSearchFilters()
+	filters.AddRootFilter()
+
+	ids, err := a.searchIDs(ctx, cnrID, filters)
+	if err != nil {
+		return err
+	}
+	l.Info("purging container", zap.Int("objects", len(ids)))
+
+	for i, id := range ids {
+		if err = a.deleteObject(ctx, newAddress(cnrID, id)); err != nil {
+			return fmt.Errorf("delete object %s: %w", id, err)
+		}
+		if deleted := i + 1; deleted%purgeProgressInterval == 0 {
+			l.Info("purging container", zap.Int("deleted", deleted), zap.Int("objects", len(ids)))
+		}
+	}
+
+	l.Info("container purged", zap.Int("deleted", len(ids)))
+	return nil
+}
@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirNotEmptyStatus(t *testing.T) {
+	// The server replies with the code of the wrapped status error and the message.
+	require.ErrorIs(t, errDirNotEmpty, sftp.ErrSSHFxFailure)
+	require.Contains(t, errDirNotEmpty.Error(), "directory isn't empty")
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrSessionNotFound is returned when there is no active session with the given ID.
+var ErrSessionNotFound = errors.New("session not found")
+
+type (
+	// session is the state of a single client session.
+	session struct {
+		id        string
+		user      string
+		remote    string
+		started   time.Time
+		terminate func() error
+		// stats are the counters of the session user, nil if not tracked.
+		stats *userStats
+
+		uploaded    atomic.Int64
+		downloaded  atomic.Int64
+		openHandles atomic.Int64
+		operations  atomic.Int64
+		errors      atomic.Int64
+		operation   atomic.Value
+		// background is the work started by the session requests, e.g. mirror copies.
+		background sync.WaitGroup
+	}
+
+	// SessionInfo describes an active session.
+	SessionInfo struct {
+		ID          string    `json:"id"`
+		User        string    `json:"user"`
+		Remote      string    `json:"remote"`
+		Started     time.Time `json:"started"`
+		OpenHandles int64     `json:"open_handles"`
+		Uploaded    int64     `json:"bytes_uploaded"`
+		Downloaded  int64     `json:"bytes_downloaded"`
+		Operations  int64     `json:"operations"`
+		Errors      int64     `json:"errors"`
+		Operation   string    `json:"operation"`
+	}
+
+	// sessions is the registry of active sessions.
+	sessions struct {
+		mu     sync.RWMutex
+		active map[string]*session
+	}
+)
+
+func newSessions() *sessions {
+	return &sessions{active: make(map[string]*session)}
+}
+
+// newID returns a random ID of a session or a request.
+func newID() string {
+	var id [8]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+func (s *sessions) add(sess *session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active[sess.id] = sess
+}
+
+func (s *sessions) remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.active, id)
+}
+
+func (s *sessions) get(id string) (*session, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sess, ok := s.active[id]
+	return sess, ok
+}
+
+func (s *sessions) list() []SessionInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := make([]SessionInfo, 0, len(s.active))
+	for _, sess := range s.active {
+		res = append(res, sess.info())
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Started.Before(res[j].Started) })
+	return res
+}
+
+// setOperation records the request being served.
+func (s *session) setOperation(method, path string) {
+	if s != nil {
+		s.operation.Store(method + " " + path)
+	}
+}
+
+// addRequest counts the finished request of the method in the session and its user stats.
+func (s *session) addRequest(method string, err error) {
+	if s == nil {
+		return
+	}
+	s.operations.Add(1)
+	if err != nil {
+		s.errors.Add(1)
+	}
+	s.stats.addRequest(method, err)
+}
+
+func (s *session) addUploaded(n int) {
+	if s != nil {
+		s.uploaded.Add(int64(n))
+		if s.stats != nil {
+			s.stats.uploaded.Add(int64(n))
+		}
+	}
+}
+
+func (s *session) addDownloaded(n int) {
+	if s != nil {
+		s.downloaded.Add(int64(n))
+		if s.stats != nil {
+			s.stats.downloaded.Add(int64(n))
+		}
+	}
+}
+
+func (s *session) handleOpened() {
+	if s != nil {
+		s.openHandles.Add(1)
+	}
+}
+
+func (s *session) handleClosed() {
+	if s != nil {
+		s.openHandles.Add(-1)
+	}
+}
+
+func (s *session) info() SessionInfo {
+	op, _ := s.operation.Load().(string)
+	return SessionInfo{
+		ID:          s.id,
+		User:        s.user,
+		Remote:      s.remote,
+		Started:     s.started,
+		OpenHandles: s.openHandles.Load(),
+		Uploaded:    s.uploaded.Load(),
+		Downloaded:  s.downloaded.Load(),
+		Operations:  s.operations.Load(),
+		Errors:      s.errors.Load(),
+		Operation:   op,
+	}
+}
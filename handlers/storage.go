@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"sync"
+
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/layer"
+)
+
+// storageRef is the storage layer the App works with, it records the latencies of the layer calls
+// and tracks the files opened with the layer so that it can be closed once they're finished after
+// it's replaced.
+type storageRef struct {
+	*measuredLayer
+
+	mu      sync.Mutex
+	handles int
+	retired bool
+	// idle is closed when the last file is closed after the layer is replaced.
+	idle chan struct{}
+}
+
+func newStorageRef(storage layer.Layer, latencies *latencies) *storageRef {
+	return &storageRef{
+		measuredLayer: &measuredLayer{Layer: storage, latencies: latencies},
+		idle:          make(chan struct{}),
+	}
+}
+
+// acquire registers the file opened with the layer. The returned function unregisters it,
+// it may be called several times.
+func (s *storageRef) acquire() func() {
+	s.mu.Lock()
+	s.handles++
+	s.mu.Unlock()
+
+	var once sync.Once
+	return func() { once.Do(s.release) }
+}
+
+func (s *storageRef) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handles--
+	if s.retired && s.handles == 0 {
+		s.closeIdle()
+	}
+}
+
+// retire marks the layer replaced, the returned channel is closed once no files are opened with it.
+func (s *storageRef) retire() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.retired = true
+	if s.handles == 0 {
+		s.closeIdle()
+	}
+	return s.idle
+}
+
+func (s *storageRef) closeIdle() {
+	select {
+	case <-s.idle:
+	default:
+		close(s.idle)
+	}
+}
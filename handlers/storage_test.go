@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/layer/layertest"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaceLayer(t *testing.T) {
+	app, storage := newMemoryApp(t, &SftpServerConfig{})
+	require.NoError(t, app.Filecmd(sftp.NewRequest("Mkdir", "/docs")))
+	uploadFile(t, app, "/docs/a.txt", "content")
+	cnr, err := app.getContainerByName(context.Background(), "docs")
+	require.NoError(t, err)
+	id, _ := storage.Objects(cnr.CID)[0].ID()
+
+	r, err := app.Fileread(sftp.NewRequest("Get", "/docs/"+id.EncodeToString()))
+	require.NoError(t, err)
+	w, err := app.Filewrite(sftp.NewRequest("Put", "/docs/b.txt"))
+	require.NoError(t, err)
+
+	old, idle := app.ReplaceLayer(layertest.NewMemory())
+	require.Equal(t, storage, old)
+
+	// The files opened before keep using the previous layer.
+	buf := make([]byte, 7)
+	_, err = r.ReadAt(buf, 0)
+	if err != io.EOF {
+		require.NoError(t, err)
+	}
+	require.Equal(t, "content", string(buf))
+	require.NoError(t, r.(io.Closer).Close())
+
+	select {
+	case <-idle:
+		t.Fatal("previous layer is idle with the upload open")
+	default:
+	}
+
+	_, err = w.WriteAt([]byte("new"), 0)
+	require.NoError(t, err)
+	require.NoError(t, w.(io.Closer).Close())
+	require.Len(t, storage.Objects(cnr.CID), 2)
+	<-idle
+
+	// Closing twice doesn't release the layer twice.
+	require.NoError(t, r.(io.Closer).Close())
+}
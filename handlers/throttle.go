@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// newRateLimiter returns limiter passing bytesPerSec bytes per second, nil for no limit.
+func newRateLimiter(bytesPerSec int64) *rate.Limiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSec), int(bytesPerSec))
+}
+
+// setRate changes the shared limiter, bytesPerSec <= 0 removes the limit.
+func setRate(l *rate.Limiter, bytesPerSec int64) {
+	if bytesPerSec <= 0 {
+		l.SetLimit(rate.Inf)
+		return
+	}
+	l.SetBurst(int(bytesPerSec))
+	l.SetLimit(rate.Limit(bytesPerSec))
+}
+
+// throttle waits until n bytes are allowed by all limiters, nil limiters allow everything.
+func throttle(ctx context.Context, n int, limiters ...*rate.Limiter) error {
+	for _, l := range limiters {
+		if l == nil || l.Limit() == rate.Inf {
+			continue
+		}
+
+		for left := n; left > 0; {
+			chunk := left
+			if burst := l.Burst(); chunk > burst {
+				chunk = burst
+			}
+			if err := l.WaitN(ctx, chunk); err != nil {
+				return err
+			}
+			left -= chunk
+		}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"errors"
+
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"go.uber.org/zap"
+)
+
+// isSessionTokenErr checks whether the error is caused by an expired or unknown session token.
+func isSessionTokenErr(err error) bool {
+	return errors.Is(err, apistatus.ErrSessionTokenExpired) || errors.Is(err, apistatus.ErrSessionTokenNotFound)
+}
+
+// withSessionRenewal runs op and repeats it once if it failed because of the session token.
+// The NeoFS layer drops such tokens from its cache, so the second attempt works with a freshly issued one.
+func withSessionRenewal(l *zap.Logger, op func() error) error {
+	err := op()
+	if !isSessionTokenErr(err) {
+		return err
+	}
+
+	l.Info("session token is expired, renewing", zap.Error(err))
+	return op()
+}
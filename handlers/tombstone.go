@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+)
+
+// errNoLinkObject means that members of the split object can't be collected.
+var errNoLinkObject = errors.New("split object without link object")
+
+// putTombstone deletes the object putting the tombstone which expires after the lifetime epochs.
+// Storage nodes use their own tombstone lifetime when the object is deleted with ObjectDelete,
+// so the gateway forms the tombstone itself to control it.
+func (a *App) putTombstone(ctx context.Context, address oid.Address, lifetime uint64) error {
+	members, err := a.tombstoneMembers(ctx, address)
+	if err != nil {
+		return err
+	}
+
+	ni, err := a.networkInfo(ctx)
+	if err != nil {
+		return err
+	}
+	expiration := ni.CurrentEpoch() + lifetime
+
+	tomb := object.NewTombstone()
+	tomb.SetExpirationEpoch(expiration)
+	tomb.SetMembers(members)
+	payload, err := tomb.Marshal()
+	if err != nil {
+		return fmt.Errorf("marshal tombstone: %w", err)
+	}
+
+	obj := object.New()
+	obj.SetOwnerID(a.ownerFor(address.Container()))
+	obj.SetContainerID(address.Container())
+	obj.SetType(object.TypeTombstone)
+	obj.SetAttributes(newAttribute(object.AttributeExpirationEpoch, strconv.FormatUint(expiration, 10)))
+
+	var prm client.PrmObjectPutInit
+	if token := a.bearerToken(address.Container()); token != nil {
+		prm.WithBearerToken(*token)
+	}
+
+	_, err = a.layer().ObjectPut(ctx, *obj, a.signerFor(address.Container()), prm, bytes.NewReader(payload))
+	return err
+}
+
+// tombstoneMembers returns IDs of the object and all its parts if it's split.
+func (a *App) tombstoneMembers(ctx context.Context, address oid.Address) ([]oid.ID, error) {
+	var prm client.PrmObjectHead
+	prm.MarkRaw()
+	if token := a.bearerToken(address.Container()); token != nil {
+		prm.WithBearerToken(*token)
+	}
+
+	_, err := a.layer().ObjectHead(ctx, address.Container(), address.Object(), a.signerFor(address.Container()), prm)
+	if err == nil {
+		return []oid.ID{address.Object()}, nil
+	}
+
+	var splitErr *object.SplitInfoError
+	if !errors.As(err, &splitErr) {
+		return nil, err
+	}
+	link, ok := splitErr.SplitInfo().Link()
+	if !ok {
+		return nil, errNoLinkObject
+	}
+
+	linkObj, err := a.layer().ObjectHead(ctx, address.Container(), link, a.signerFor(address.Container()), prm)
+	if err != nil {
+		return nil, fmt.Errorf("head link object: %w", err)
+	}
+
+	return append([]oid.ID{address.Object(), link}, linkObj.Children()...), nil
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/pkg/sftp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer uses the global provider, spans are dropped until the application sets one.
+var tracer = otel.Tracer("github.com/nspcc-dev/neofs-sftp-gw/handlers")
+
+// startRequestSpan starts the root span of the SFTP request.
+func (a *App) startRequestSpan(ctx context.Context, r *sftp.Request, id string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("sftp.method", r.Method),
+		attribute.String("sftp.path", r.Filepath),
+		attribute.String("sftp.request", id),
+	}
+	if a.session != nil {
+		attrs = append(attrs,
+			attribute.String("sftp.session", a.session.id),
+			attribute.String("sftp.user", a.session.user),
+		)
+	}
+
+	return tracer.Start(ctx, "sftp."+r.Method, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+}
+
+// startSpan starts the span of a NeoFS call.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endSpan records the error of the operation, if any, and ends the span.
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, io.EOF) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// errShuttingDown is returned for new uploads after the shutdown has begun.
+var errShuttingDown = errors.New("server is shutting down")
+
+// transfers tracks in-flight uploads so that they can be drained on shutdown.
+type transfers struct {
+	mu      sync.Mutex
+	active  map[*objWriter]struct{}
+	closing bool
+	// idle is closed when the last active upload is finished during shutdown.
+	idle chan struct{}
+}
+
+func newTransfers() *transfers {
+	return &transfers{
+		active: make(map[*objWriter]struct{}),
+		idle:   make(chan struct{}),
+	}
+}
+
+func (t *transfers) add(w *objWriter) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closing {
+		return errShuttingDown
+	}
+	t.active[w] = struct{}{}
+	return nil
+}
+
+func (t *transfers) remove(w *objWriter) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.active, w)
+	if t.closing && len(t.active) == 0 {
+		t.closeIdle()
+	}
+}
+
+func (t *transfers) closeIdle() {
+	select {
+	case <-t.idle:
+	default:
+		close(t.idle)
+	}
+}
+
+// drain rejects new uploads and waits for the active ones. Uploads still
+// running when ctx is done are aborted and the number of them is returned.
+func (t *transfers) drain(ctx context.Context) int {
+	t.mu.Lock()
+	t.closing = true
+	if len(t.active) == 0 {
+		t.closeIdle()
+	}
+	t.mu.Unlock()
+
+	select {
+	case <-t.idle:
+		return 0
+	case <-ctx.Done():
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	aborted := len(t.active)
+	for w := range t.active {
+		w.abort()
+	}
+	return aborted
+}
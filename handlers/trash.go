@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/pkg/sftp"
+)
+
+const (
+	// trashDir is the virtual directory of the container listing the removed files
+	// as /<container>/.trash/<file name> when the trash is enabled.
+	trashDir = ".trash"
+
+	// trashedAttribute is the Unix time the object was moved to the trash.
+	trashedAttribute = "Trashed"
+)
+
+// Trash defines whether removed files are kept in the trash directory.
+type Trash struct {
+	Enabled bool
+	// Retention is the time trashed files are kept before the network removes them,
+	// they are kept until removed from the trash if 0.
+	Retention time.Duration
+}
+
+// splitTrashed separates the objects in the trash from the other ones.
+func splitTrashed(objects []*ObjectInfo) (files, trashed []*ObjectInfo) {
+	for _, obj := range objects {
+		if obj.Trashed.IsZero() {
+			files = append(files, obj)
+		} else {
+			trashed = append(trashed, obj)
+		}
+	}
+	return files, trashed
+}
+
+func trashDirInfo(cnr *ContainerInfo) *ContainerInfo {
+	info := *cnr
+	info.FileName = trashDir
+	return &info
+}
+
+// trashPath returns the path relative to the trash directory if the path relative
+// to the container is inside it.
+func trashPath(path string) (string, bool) {
+	if path == trashDir {
+		return "", true
+	}
+	if rest := strings.TrimPrefix(path, trashDir+delimiter); rest != path {
+		return strings.TrimSuffix(rest, delimiter), true
+	}
+	return "", false
+}
+
+// isTrashPath checks whether the full path is inside the trash directory of the container.
+func isTrashPath(fullPath string) bool {
+	_, rest, _ := strings.Cut(strings.TrimPrefix(fullPath, delimiter), delimiter)
+	_, ok := trashPath(rest)
+	return ok
+}
+
+// listTrash lists the files in the trash, the latest removed one is listed if several
+// files with the same name are removed.
+func (a *App) listTrash(ctx context.Context, cnr *ContainerInfo) ([]os.FileInfo, error) {
+	objects, err := a.searchAllObjects(ctx, cnr.CID, "")
+	if err != nil {
+		return nil, err
+	}
+	_, trashed := splitTrashed(objects)
+
+	result := make([]os.FileInfo, 0, len(trashed))
+	for _, versions := range groupTrashed(trashed) {
+		result = append(result, versions[0])
+	}
+	return result, nil
+}
+
+// groupTrashed groups the trashed objects by the file name, every group is sorted
+// from the latest removed object.
+func groupTrashed(trashed []*ObjectInfo) [][]*ObjectInfo {
+	groups := groupVersions(trashed)
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].Trashed.After(group[j].Trashed)
+		})
+	}
+	return groups
+}
+
+// getTrashed returns the latest removed file with the name.
+func (a *App) getTrashed(ctx context.Context, cnr *ContainerInfo, name string) (*ObjectInfo, error) {
+	objects, err := a.searchAllObjects(ctx, cnr.CID, name)
+	if err != nil {
+		return nil, err
+	}
+	_, trashed := splitTrashed(objects)
+	if len(trashed) == 0 {
+		return nil, sftp.ErrSSHFxNoSuchFile
+	}
+	return groupTrashed(trashed)[0][0], nil
+}
+
+// trashStat returns the information about the file in the trash directory.
+func (a *App) trashStat(ctx context.Context, cnr *ContainerInfo, name string) (os.FileInfo, error) {
+	if name == "" {
+		return trashDirInfo(cnr), nil
+	}
+	return a.getTrashed(ctx, cnr, name)
+}
+
+// moveToTrash puts the copy of the object marked as trashed, which expires after the retention
+// time, and deletes the original object.
+func (a *App) moveToTrash(ctx context.Context, cnr *ContainerInfo, obj *ObjectInfo, fullPath string) error {
+	changes := []Attribute{{Key: trashedAttribute, Value: strconv.FormatInt(time.Now().UTC().Unix(), 10)}}
+	if retention := a.config().Trash.Retention; retention > 0 {
+		expiration, err := a.expirationEpoch(ctx, ExpirationRule{TTL: retention})
+		if err != nil {
+			return err
+		}
+		// Files expiring earlier are removed by the network anyway.
+		if obj.ExpirationEpoch == 0 || obj.ExpirationEpoch > expiration {
+			changes = append(changes, Attribute{Key: object.AttributeExpirationEpoch, Value: strconv.FormatUint(expiration, 10)})
+		}
+	}
+
+	trashFile := delimiter + path.Join(cnr.Name(), trashDir, obj.Name())
+	return a.replaceObject(ctx, obj, cnr.CID, fullPath, trashFile, changes)
+}
+
+// deleteTrashed removes the file from the trash permanently.
+func (a *App) deleteTrashed(ctx context.Context, cnr *ContainerInfo, fullPath, name string) error {
+	obj, err := a.getTrashed(ctx, cnr, name)
+	if err != nil {
+		return err
+	}
+	if err = a.checkAccess(ctx, cnr.CID, acl.OpObjectDelete); err != nil {
+		return err
+	}
+
+	if err = a.deleteObject(ctx, newAddress(cnr.CID, obj.ObjectID)); err != nil {
+		return err
+	}
+	a.emitEvent(ctx, EventDelete, fullPath, cnr.CID, &obj.ObjectID, obj.PayloadSize)
+	return nil
+}
+
+// restoreTrashed moves the file from the trash back to the container, it gets the expiration
+// epoch of the rule matching the target path as a new upload does.
+func (a *App) restoreTrashed(ctx context.Context, source, target string) error {
+	cnrName, rest, _ := strings.Cut(strings.TrimPrefix(source, delimiter), delimiter)
+	name, _ := trashPath(rest)
+
+	targetCnr, targetName, _ := strings.Cut(strings.TrimPrefix(target, delimiter), delimiter)
+	if targetCnr != cnrName || name == "" || targetName == "" || isTrashPath(target) || isVersionPath(target) {
+		return sftp.ErrSSHFxOpUnsupported
+	}
+
+	cnr, err := a.getContainerByName(ctx, cnrName)
+	if err != nil {
+		return err
+	}
+	obj, err := a.getTrashed(ctx, cnr, name)
+	if err != nil {
+		return err
+	}
+
+	var expiration string
+	if rule := a.expirationRule(target); rule != nil {
+		epoch, err := a.expirationEpoch(ctx, *rule)
+		if err != nil {
+			return err
+		}
+		expiration = strconv.FormatUint(epoch, 10)
+	}
+
+	return a.replaceObject(ctx, obj, cnr.CID, source, target, []Attribute{
+		{Key: object.AttributeFileName, Value: path.Base(targetName)},
+		{Key: filePathAttribute, Value: targetName},
+		{Key: trashedAttribute},
+		{Key: object.AttributeExpirationEpoch, Value: expiration},
+	})
+}
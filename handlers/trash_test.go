@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrashPath(t *testing.T) {
+	for p, expected := range map[string]string{
+		".trash":        "",
+		".trash/":       "",
+		".trash/a.txt":  "a.txt",
+		".trash/dir/a":  "dir/a",
+		".trash/a.txt/": "a.txt",
+	} {
+		name, ok := trashPath(p)
+		require.True(t, ok, p)
+		require.Equal(t, expected, name, p)
+	}
+
+	for _, p := range []string{"", "a.txt", ".trashed", ".versions/a.txt"} {
+		_, ok := trashPath(p)
+		require.False(t, ok, p)
+	}
+
+	require.True(t, isTrashPath("/cnr/.trash/a.txt"))
+	require.False(t, isTrashPath("/cnr/a.txt"))
+	require.False(t, isTrashPath("/.trash"))
+}
+
+func TestSplitTrashed(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	file := &ObjectInfo{FileName: "a.txt", ObjectID: oidtest.ID(), Created: now}
+	older := &ObjectInfo{FileName: "a.txt", ObjectID: oidtest.ID(), Created: now.Add(time.Hour), Trashed: now.Add(time.Hour)}
+	newer := &ObjectInfo{FileName: "a.txt", ObjectID: oidtest.ID(), Created: now, Trashed: now.Add(2 * time.Hour)}
+
+	files, trashed := splitTrashed([]*ObjectInfo{file, older, newer})
+	require.Equal(t, []*ObjectInfo{file}, files)
+	require.Equal(t, []*ObjectInfo{older, newer}, trashed)
+
+	groups := groupTrashed(trashed)
+	require.Len(t, groups, 1)
+	require.Equal(t, []*ObjectInfo{newer, older}, groups[0], "the latest removed file goes first")
+}
+
+func TestTrash(t *testing.T) {
+	app, storage := newMemoryApp(t, &SftpServerConfig{Trash: Trash{Enabled: true, Retention: 2 * time.Hour}})
+	var ni netmap.NetworkInfo
+	ni.SetCurrentEpoch(100)
+	ni.SetEpochDuration(240)
+	ni.SetMsPerBlock(15000)
+	storage.SetNetworkInfo(ni)
+
+	require.NoError(t, app.Filecmd(sftp.NewRequest("Mkdir", "/docs")))
+	uploadFile(t, app, "/docs/a.txt", "content")
+	cnr, err := app.getContainerByName(context.Background(), "docs")
+	require.NoError(t, err)
+
+	require.NoError(t, app.Filecmd(sftp.NewRequest("Remove", "/docs/a.txt")))
+	require.NotContains(t, listNames(t, app, "/docs"), "a.txt")
+	require.Equal(t, []string{"a.txt"}, listNames(t, app, "/docs/.trash"))
+
+	// The removed file is kept as the copy expiring after the retention time.
+	objects := storage.Objects(cnr.CID)
+	require.Len(t, objects, 1)
+	attrs := make(map[string]string)
+	for _, attr := range objects[0].Attributes() {
+		attrs[attr.Key()] = attr.Value()
+	}
+	require.NotEmpty(t, attrs[trashedAttribute])
+	require.Equal(t, "102", attrs[object.AttributeExpirationEpoch])
+	require.Equal(t, "a.txt", attrs[object.AttributeFileName])
+
+	// Files are restored by renaming them out of the trash.
+	r := sftp.NewRequest("Rename", "/docs/.trash/a.txt")
+	r.Target = "/docs/b.txt"
+	require.NoError(t, app.Filecmd(r))
+	require.Contains(t, listNames(t, app, "/docs"), "b.txt")
+	require.Empty(t, listNames(t, app, "/docs/.trash"))
+
+	objects = storage.Objects(cnr.CID)
+	require.Len(t, objects, 1)
+	id, _ := objects[0].ID()
+	require.Equal(t, "content", downloadFile(t, app, "/docs/"+id.EncodeToString()))
+
+	// Removing from the trash deletes the file permanently.
+	require.NoError(t, app.Filecmd(sftp.NewRequest("Remove", "/docs/b.txt")))
+	require.NoError(t, app.Filecmd(sftp.NewRequest("Remove", "/docs/.trash/b.txt")))
+	require.Empty(t, listNames(t, app, "/docs/.trash"))
+	require.Empty(t, storage.Objects(cnr.CID))
+}
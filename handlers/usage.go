@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/pkg/sftp"
+	"go.uber.org/zap"
+)
+
+const (
+	// infoFile is the virtual read-only file of the container describing its usage.
+	infoFile = ".info"
+
+	// DefaultUsageRefreshInterval is the default interval of container usage refreshing.
+	DefaultUsageRefreshInterval = 10 * time.Minute
+
+	// statVFSBlockSize is the block size reported by statvfs.
+	statVFSBlockSize = 4096
+	// statVFSUnlimited is the number of free blocks and inodes reported by statvfs,
+	// NeoFS doesn't limit them.
+	statVFSUnlimited = 1 << 40
+	statVFSNameMax   = 255
+)
+
+type (
+	// ContainerUsage is the space used by the container.
+	ContainerUsage struct {
+		// Size is the sum of payload sizes of all the objects.
+		Size    uint64
+		Objects uint64
+		Updated time.Time
+	}
+
+	// usageCache keeps the usage of containers requested by clients, it's shared by all
+	// users of the App.
+	usageCache struct {
+		mu         sync.Mutex
+		containers map[cid.ID]ContainerUsage
+		// reserved is the space reserved by the uploads in progress.
+		reserved map[cid.ID]uint64
+	}
+
+	// virtualFile is the file served by the gateway itself.
+	virtualFile struct {
+		name    string
+		content []byte
+		modTime time.Time
+	}
+)
+
+func newUsageCache() *usageCache {
+	return &usageCache{containers: make(map[cid.ID]ContainerUsage), reserved: make(map[cid.ID]uint64)}
+}
+
+func (c *usageCache) get(cnrID cid.ID) (ContainerUsage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	u, ok := c.containers[cnrID]
+	return u, ok
+}
+
+func (c *usageCache) set(cnrID cid.ID, u ContainerUsage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.containers[cnrID] = u
+}
+
+func (c *usageCache) all() map[cid.ID]ContainerUsage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := make(map[cid.ID]ContainerUsage, len(c.containers))
+	for cnrID, u := range c.containers {
+		res[cnrID] = u
+	}
+	return res
+}
+
+// ContainerUsage returns the cached usage of the containers requested by clients.
+func (a *App) ContainerUsage() map[cid.ID]ContainerUsage {
+	return a.usage.all()
+}
+
+// StartUsageRefresh refreshes the usage of the cached containers with the interval until ctx is done.
+func (a *App) StartUsageRefresh(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultUsageRefreshInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			for cnrID := range a.usage.all() {
+				if _, err := a.refreshUsage(ctx, cnrID); err != nil {
+					a.Log.Warn("failed to refresh container usage", zap.Stringer("container", cnrID), zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// containerUsage returns the cached usage of the container, it's calculated if not cached yet.
+func (a *App) containerUsage(ctx context.Context, cnrID cid.ID) (ContainerUsage, error) {
+	if u, ok := a.usage.get(cnrID); ok {
+		return u, nil
+	}
+	return a.refreshUsage(ctx, cnrID)
+}
+
+func (a *App) refreshUsage(ctx context.Context, cnrID cid.ID) (ContainerUsage, error) {
+	objects, err := a.searchAllObjects(ctx, cnrID, "")
+	if err != nil {
+		return ContainerUsage{}, err
+	}
+	return a.updateUsage(cnrID, objects), nil
+}
+
+// updateUsage caches the usage of the container having the objects.
+func (a *App) updateUsage(cnrID cid.ID, objects []*ObjectInfo) ContainerUsage {
+	u := ContainerUsage{
+		Objects: uint64(len(objects)),
+		Updated: time.Now(),
+	}
+	for _, obj := range objects {
+		u.Size += uint64(obj.PayloadSize)
+	}
+	a.usage.set(cnrID, u)
+	return u
+}
+
+// newInfoFile returns the info file of the container with the usage.
+func newInfoFile(cnr *ContainerInfo, u ContainerUsage) *virtualFile {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "container: %s\n", cnr.CID)
+	fmt.Fprintf(&b, "name: %s\n", cnr.Name())
+	fmt.Fprintf(&b, "objects: %d\n", u.Objects)
+	fmt.Fprintf(&b, "size: %d\n", u.Size)
+	fmt.Fprintf(&b, "updated: %s\n", u.Updated.UTC().Format(time.RFC3339))
+	if cnr.Policy != "" {
+		b.WriteString("policy:\n")
+		for _, clause := range strings.Split(cnr.Policy, "\n") {
+			fmt.Fprintf(&b, "  %s\n", clause)
+		}
+	}
+
+	return &virtualFile{
+		name:    infoFile,
+		content: b.Bytes(),
+		modTime: u.Updated,
+	}
+}
+
+func (a *App) containerInfoFile(ctx context.Context, cnr *ContainerInfo) (*virtualFile, error) {
+	u, err := a.containerUsage(ctx, cnr.CID)
+	if err != nil {
+		return nil, err
+	}
+	return newInfoFile(cnr, u), nil
+}
+
+// StatVFS reports the space used by the container, or by all the listed containers for the root.
+// Called for statvfs@openssh.com extended requests.
+func (a *App) StatVFS(r *sftp.Request) (_ *sftp.StatVFS, err error) {
+	a.session.setOperation(r.Method, r.Filepath)
+	ctx, span := a.startRequest(r)
+	defer func() {
+		if p := recover(); p != nil {
+			err = a.recoverRequest(ctx, r, p)
+		}
+		endSpan(span, err)
+		a.finishRequest(ctx, r, err)
+	}()
+
+	file, err := a.getFileStat(ctx, r.Filepath)
+	if err != nil {
+		return nil, err
+	}
+	cnr, ok := file.(*ContainerInfo)
+	if !ok {
+		return nil, sftp.ErrSSHFxOpUnsupported
+	}
+
+	containers := []os.FileInfo{cnr}
+	if cnr.Name() == delimiter {
+		if containers, err = a.listContainers(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var total ContainerUsage
+	for _, info := range containers {
+		u, err := a.containerUsage(ctx, info.(*ContainerInfo).CID)
+		if err != nil {
+			return nil, err
+		}
+		total.Size += u.Size
+		total.Objects += u.Objects
+	}
+
+	return newStatVFS(total), nil
+}
+
+func newStatVFS(u ContainerUsage) *sftp.StatVFS {
+	used := (u.Size + statVFSBlockSize - 1) / statVFSBlockSize
+	return &sftp.StatVFS{
+		Bsize:   statVFSBlockSize,
+		Frsize:  statVFSBlockSize,
+		Blocks:  used + statVFSUnlimited,
+		Bfree:   statVFSUnlimited,
+		Bavail:  statVFSUnlimited,
+		Files:   u.Objects + statVFSUnlimited,
+		Ffree:   statVFSUnlimited,
+		Favail:  statVFSUnlimited,
+		Namemax: statVFSNameMax,
+	}
+}
+
+func (f *virtualFile) Name() string {
+	return f.name
+}
+
+func (f *virtualFile) Size() int64 {
+	return int64(len(f.content))
+}
+
+func (f *virtualFile) Mode() fs.FileMode {
+	return 0o444
+}
+
+func (f *virtualFile) ModTime() time.Time {
+	return f.modTime
+}
+
+func (f *virtualFile) IsDir() bool {
+	return false
+}
+
+func (f *virtualFile) Sys() any {
+	return nil
+}
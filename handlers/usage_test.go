@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsage(t *testing.T) {
+	cnr := &ContainerInfo{CID: cidtest.ID(), FileName: "photos"}
+	u := ContainerUsage{Size: 5000, Objects: 2, Updated: time.Unix(1700000000, 0)}
+
+	info := newInfoFile(cnr, u)
+	require.Equal(t, infoFile, info.Name())
+	require.Equal(t, "container: "+cnr.CID.EncodeToString()+"\nname: photos\nobjects: 2\nsize: 5000\n"+
+		"updated: 2023-11-14T22:13:20Z\n", string(info.content))
+	require.EqualValues(t, len(info.content), info.Size())
+
+	stat := newStatVFS(u)
+	require.EqualValues(t, 2, stat.Blocks-stat.Bfree, "used blocks are rounded up")
+	require.EqualValues(t, 2, stat.Files-stat.Ffree)
+}
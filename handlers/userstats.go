@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// userStats are the transfer counters of all sessions of a single user.
+	userStats struct {
+		sessions   atomic.Int64
+		uploaded   atomic.Int64
+		downloaded atomic.Int64
+
+		mu         sync.Mutex
+		operations map[string]int64
+		errors     map[string]int64
+	}
+
+	// UserStats describes the transfers of all sessions of a user since the gateway start.
+	UserStats struct {
+		User       string           `json:"user"`
+		Sessions   int64            `json:"sessions"`
+		Uploaded   int64            `json:"bytes_uploaded"`
+		Downloaded int64            `json:"bytes_downloaded"`
+		Operations map[string]int64 `json:"operations"`
+		Errors     map[string]int64 `json:"errors"`
+	}
+
+	// usersStats is the registry of the user counters, it's shared by all users of the App.
+	usersStats struct {
+		mu    sync.Mutex
+		users map[string]*userStats
+	}
+)
+
+func newUsersStats() *usersStats {
+	return &usersStats{users: make(map[string]*userStats)}
+}
+
+// get returns the counters of the user, they are created on the first call.
+func (u *usersStats) get(name string) *userStats {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	stats, ok := u.users[name]
+	if !ok {
+		stats = &userStats{operations: make(map[string]int64), errors: make(map[string]int64)}
+		u.users[name] = stats
+	}
+	return stats
+}
+
+func (u *usersStats) list() []UserStats {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	res := make([]UserStats, 0, len(u.users))
+	for name, stats := range u.users {
+		res = append(res, stats.info(name))
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].User < res[j].User })
+	return res
+}
+
+// addRequest counts the finished request of the method, err is its result.
+func (s *userStats) addRequest(method string, err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.operations[method]++
+	if err != nil {
+		s.errors[method]++
+	}
+}
+
+func (s *userStats) info(name string) UserStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := UserStats{
+		User:       name,
+		Sessions:   s.sessions.Load(),
+		Uploaded:   s.uploaded.Load(),
+		Downloaded: s.downloaded.Load(),
+		Operations: make(map[string]int64, len(s.operations)),
+		Errors:     make(map[string]int64, len(s.errors)),
+	}
+	for method, n := range s.operations {
+		res.Operations[method] = n
+	}
+	for method, n := range s.errors {
+		res.Errors[method] = n
+	}
+	return res
+}
+
+// UserStats returns the transfer counters of the users having had sessions, sorted by name.
+func (a *App) UserStats() []UserStats {
+	return a.users.list()
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestUserStats(t *testing.T) {
+	app := NewApp(nil, nil, new(user.ID), zap.NewNop(), &SftpServerConfig{}, "")
+
+	first := app.StartSession("Alice", "192.0.2.1:50000", nil)
+	first.session.addUploaded(10)
+	first.session.addRequest("Put", nil)
+	first.session.addRequest("Put", errors.New("failed"))
+	first.EndSession()
+
+	second := app.StartSession("alice", "192.0.2.1:50001", nil)
+	defer second.EndSession()
+	second.session.addDownloaded(5)
+	second.session.addRequest("Get", nil)
+
+	sessions := app.Sessions()
+	require.Len(t, sessions, 1)
+	require.Equal(t, int64(5), sessions[0].Downloaded)
+	require.Equal(t, int64(1), sessions[0].Operations)
+	require.Zero(t, sessions[0].Errors)
+
+	require.Equal(t, []UserStats{{
+		User:       "alice",
+		Sessions:   2,
+		Uploaded:   10,
+		Downloaded: 5,
+		Operations: map[string]int64{"Put": 2, "Get": 1},
+		Errors:     map[string]int64{"Put": 1},
+	}}, app.UserStats())
+
+	// Apps not bound to a session aren't counted.
+	app.session.addRequest("Get", nil)
+	app.session.addUploaded(1)
+	require.Equal(t, int64(10), app.UserStats()[0].Uploaded)
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/pkg/sftp"
+	"go.uber.org/zap"
+)
+
+// versionsDir is the virtual directory of the container listing older versions of files,
+// objects with the same file name. Versions of the file are available at
+// /<container>/.versions/<file name>/<unix timestamp>.
+const versionsDir = ".versions"
+
+// groupVersions returns the objects grouped by the file name in the order of the first
+// appearance, every group is sorted from the newest object to the oldest one.
+func groupVersions(objects []*ObjectInfo) [][]*ObjectInfo {
+	var (
+		groups  [][]*ObjectInfo
+		indices = make(map[string]int)
+	)
+	for _, obj := range objects {
+		i, ok := indices[obj.Name()]
+		if !ok {
+			i = len(groups)
+			indices[obj.Name()] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], obj)
+	}
+
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool {
+			if !group[i].Created.Equal(group[j].Created) {
+				return group[i].Created.After(group[j].Created)
+			}
+			return group[i].ObjectID.EncodeToString() < group[j].ObjectID.EncodeToString()
+		})
+	}
+	return groups
+}
+
+// olderVersions returns the versions of the file except the current one, named by
+// their timestamps. Versions with the same timestamp are told apart by the object ID.
+func olderVersions(versions []*ObjectInfo) []*ObjectInfo {
+	if len(versions) < 2 {
+		return nil
+	}
+
+	timestamps := make(map[int64]int)
+	for _, obj := range versions[1:] {
+		timestamps[obj.Created.Unix()]++
+	}
+
+	res := make([]*ObjectInfo, 0, len(versions)-1)
+	for _, obj := range versions[1:] {
+		version := *obj
+		version.FileName = strconv.FormatInt(obj.Created.Unix(), 10)
+		if timestamps[obj.Created.Unix()] > 1 {
+			version.FileName += "-" + obj.ObjectID.EncodeToString()
+		}
+		res = append(res, &version)
+	}
+	return res
+}
+
+func versionsDirInfo(cnr *ContainerInfo) *ContainerInfo {
+	info := *cnr
+	info.FileName = versionsDir
+	return &info
+}
+
+// splitVersionPath splits the path relative to the versions directory into the file name
+// and the version, which is the last element.
+func splitVersionPath(path string) (name, version string) {
+	i := strings.LastIndex(path, delimiter)
+	if i < 0 {
+		return path, ""
+	}
+	return path[:i], path[i+1:]
+}
+
+// listVersions lists the versions directory if path is empty, or the older versions of the file.
+func (a *App) listVersions(ctx context.Context, cnr *ContainerInfo, path string) ([]os.FileInfo, error) {
+	objects, err := a.searchObjects(ctx, cnr.CID, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []os.FileInfo
+	for _, versions := range groupVersions(objects) {
+		older := olderVersions(versions)
+		if len(older) == 0 {
+			continue
+		}
+
+		if path == "" {
+			dir := *cnr
+			dir.FileName = versions[0].Name()
+			dir.Created = versions[1].Created
+			result = append(result, &dir)
+			continue
+		}
+		for _, obj := range older {
+			result = append(result, obj)
+		}
+	}
+
+	if path != "" && len(result) == 0 {
+		return nil, sftp.ErrSSHFxNoSuchFile
+	}
+	return result, nil
+}
+
+// versionStat returns the information about the file in the versions directory.
+func (a *App) versionStat(ctx context.Context, cnr *ContainerInfo, path string) (os.FileInfo, error) {
+	if path == "" {
+		return versionsDirInfo(cnr), nil
+	}
+
+	// The path is either the file name or the version of the file.
+	if files, err := a.listVersions(ctx, cnr, path); err == nil {
+		dir := *cnr
+		dir.FileName = path[strings.LastIndex(path, delimiter)+1:]
+		dir.Created = files[0].ModTime()
+		return &dir, nil
+	}
+
+	return a.getVersion(ctx, cnr, path)
+}
+
+// getVersion returns the older version of the file by its path in the versions directory.
+func (a *App) getVersion(ctx context.Context, cnr *ContainerInfo, path string) (*ObjectInfo, error) {
+	name, version := splitVersionPath(path)
+	if name == "" || version == "" {
+		return nil, sftp.ErrSSHFxNoSuchFile
+	}
+
+	files, err := a.listVersions(ctx, cnr, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if file.Name() == version {
+			return file.(*ObjectInfo), nil
+		}
+	}
+	return nil, sftp.ErrSSHFxNoSuchFile
+}
+
+// deleteVersion removes the older version of the file.
+func (a *App) deleteVersion(ctx context.Context, cnr *ContainerInfo, fullPath, path string) error {
+	obj, err := a.getVersion(ctx, cnr, path)
+	if err != nil {
+		return err
+	}
+
+	if err = a.deleteObject(ctx, newAddress(cnr.CID, obj.ObjectID)); err != nil {
+		return err
+	}
+	a.emitEvent(ctx, EventDelete, fullPath, cnr.CID, &obj.ObjectID, obj.PayloadSize)
+	return nil
+}
+
+// versionsPath returns the path relative to the versions directory if the path relative
+// to the container is inside it.
+func versionsPath(path string) (string, bool) {
+	if path == versionsDir {
+		return "", true
+	}
+	if rest := strings.TrimPrefix(path, versionsDir+delimiter); rest != path {
+		return strings.TrimSuffix(rest, delimiter), true
+	}
+	return "", false
+}
+
+// isVersionPath checks whether the full path is inside the versions directory of the container.
+func isVersionPath(fullPath string) bool {
+	_, rest, _ := strings.Cut(strings.TrimPrefix(fullPath, delimiter), delimiter)
+	_, ok := versionsPath(rest)
+	return ok
+}
+
+// restoreVersion makes the older version of the file the current one: it's put again
+// with the target name and the current timestamp. Only the file of the same container
+// can be the target.
+func (a *App) restoreVersion(ctx context.Context, source, target string) error {
+	cnrName, rest, _ := strings.Cut(strings.TrimPrefix(source, delimiter), delimiter)
+	versionPath, _ := versionsPath(rest)
+
+	targetCnr, targetName, _ := strings.Cut(strings.TrimPrefix(target, delimiter), delimiter)
+	if targetCnr != cnrName || targetName == "" || isVersionPath(target) {
+		return sftp.ErrSSHFxOpUnsupported
+	}
+
+	cnr, err := a.getContainerByName(ctx, cnrName)
+	if err != nil {
+		return err
+	}
+	obj, err := a.getVersion(ctx, cnr, versionPath)
+	if err != nil {
+		return err
+	}
+
+	return a.replaceObject(ctx, obj, cnr.CID, source, target, []Attribute{
+		{Key: object.AttributeFileName, Value: path.Base(targetName)},
+		{Key: filePathAttribute, Value: targetName},
+		{Key: object.AttributeTimestamp, Value: strconv.FormatInt(time.Now().UTC().Unix(), 10)},
+	})
+}
+
+// DeleteDuplicates deletes all the versions of files in the container except the current ones
+// and returns the number of deleted objects.
+func (a *App) DeleteDuplicates(ctx context.Context, cnrID cid.ID) (int, error) {
+	l := a.Log.With(zap.Stringer("container", cnrID))
+
+	objects, err := a.searchObjects(ctx, cnrID, "")
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int
+	for _, versions := range groupVersions(objects) {
+		for _, obj := range versions[1:] {
+			if err = a.deleteObject(ctx, newAddress(cnrID, obj.ObjectID)); err != nil {
+				return deleted, fmt.Errorf("delete object %s: %w", obj.ObjectID, err)
+			}
+			deleted++
+			l.Debug("duplicate deleted", zap.String("name", obj.Name()), zap.Stringer("object", obj.ObjectID))
+		}
+	}
+
+	l.Info("duplicates deleted", zap.Int("deleted", deleted), zap.Int("objects", len(objects)))
+	return deleted, nil
+}
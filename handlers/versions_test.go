@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersions(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	newObject := func(name string, created time.Time) *ObjectInfo {
+		return &ObjectInfo{FileName: name, ObjectID: oidtest.ID(), Created: created}
+	}
+
+	old := newObject("a.txt", now.Add(-time.Hour))
+	current := newObject("a.txt", now)
+	sameTime1 := newObject("a.txt", now.Add(-2*time.Hour))
+	sameTime2 := newObject("a.txt", now.Add(-2*time.Hour))
+	single := newObject("b.txt", now)
+
+	groups := groupVersions([]*ObjectInfo{old, single, sameTime1, current, sameTime2})
+	require.Len(t, groups, 2)
+	require.Equal(t, current, groups[0][0])
+	require.Equal(t, old, groups[0][1])
+	require.Equal(t, []*ObjectInfo{single}, groups[1])
+	require.Empty(t, olderVersions(groups[1]))
+
+	older := olderVersions(groups[0])
+	require.Len(t, older, 3)
+	require.Equal(t, "1699996400", older[0].Name())
+	require.Equal(t, old.ObjectID, older[0].ObjectID)
+	require.Equal(t, "a.txt", old.Name(), "original object must not be renamed")
+	for _, obj := range older[1:] {
+		require.Equal(t, "1699992800-"+obj.ObjectID.EncodeToString(), obj.Name())
+	}
+}
+
+func TestVersionsPath(t *testing.T) {
+	for _, tc := range []struct {
+		path, versionPath string
+		ok                bool
+	}{
+		{path: ".versions", ok: true},
+		{path: ".versions/", ok: true},
+		{path: ".versions/dir/a.txt/1700000000", versionPath: "dir/a.txt/1700000000", ok: true},
+		{path: ".versionsfile"},
+		{path: "a.txt"},
+		{path: ""},
+	} {
+		versionPath, ok := versionsPath(tc.path)
+		require.Equal(t, tc.ok, ok, tc.path)
+		require.Equal(t, tc.versionPath, versionPath, tc.path)
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/waiter"
+	"go.uber.org/zap"
+)
+
+// ContainerWaiter defines how Mkdir waits for containers to be created.
+type ContainerWaiter struct {
+	// PollInterval of container checks, waiter.DefaultPollInterval is used if 0.
+	PollInterval time.Duration
+	// Timeout of waiting, it isn't limited if 0.
+	Timeout time.Duration
+	// Async makes Mkdir return once the container transaction is accepted.
+	Async bool
+}
+
+// waiterContext returns the context limited by the waiter timeout.
+func (w ContainerWaiter) waiterContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if w.Timeout > 0 {
+		return context.WithTimeout(ctx, w.Timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// waitContainer polls the network until the container is created.
+func (a *App) waitContainer(ctx context.Context, cnrID cid.ID, interval time.Duration) error {
+	if interval <= 0 {
+		interval = waiter.DefaultPollInterval
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			_, err := a.layer().ContainerGet(ctx, cnrID, client.PrmContainerGet{})
+			if err == nil {
+				return nil
+			}
+			if !errors.Is(err, apistatus.ErrContainerNotFound) {
+				return fmt.Errorf("ContainerGet: %w", err)
+			}
+		case <-ctx.Done():
+			return waiter.ErrConfirmationTimeout
+		}
+	}
+}
+
+// setEACLAsync sets eACL on the container in background once it's created.
+func (a *App) setEACLAsync(cnrID cid.ID, eaclTemplate *eacl.Table) {
+	w := a.config().ContainerWaiter
+	l := a.Log.With(zap.Stringer("container", cnrID))
+
+	go func() {
+		ctx, cancel := w.waiterContext(context.Background())
+		defer cancel()
+
+		if err := a.waitContainer(ctx, cnrID, w.PollInterval); err != nil {
+			l.Error("container isn't created, eACL isn't set", zap.Error(err))
+			return
+		}
+		if err := a.setEACL(ctx, cnrID, eaclTemplate); err != nil {
+			l.Error("failed to set eACL", zap.Error(err))
+		}
+	}()
+}
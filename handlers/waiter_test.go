@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerWaiterContext(t *testing.T) {
+	ctx, cancel := ContainerWaiter{}.waiterContext(context.Background())
+	_, ok := ctx.Deadline()
+	require.False(t, ok)
+	cancel()
+	require.Error(t, ctx.Err())
+
+	ctx, cancel = ContainerWaiter{Timeout: time.Minute}.waiterContext(context.Background())
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+}
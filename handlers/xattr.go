@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/pkg/sftp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// getAttrExtension replies with all the attributes of the object.
+func (a *App) getAttrExtension(ctx context.Context, filePath string, _ []byte) ([]byte, error) {
+	obj, err := a.getObjectByPath(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs, err := a.objectAttributes(ctx, newAddress(obj.Container.CID, obj.ObjectID))
+	if err != nil {
+		return nil, err
+	}
+
+	reply := binary.BigEndian.AppendUint32(nil, uint32(len(attrs)))
+	for _, attr := range attrs {
+		reply = appendString(reply, attr.Key())
+		reply = appendString(reply, attr.Value())
+	}
+	return reply, nil
+}
+
+// setAttrExtension changes the attributes of the object. Objects are immutable, so the object
+// is put again with the new attributes, and the original object is deleted.
+func (a *App) setAttrExtension(ctx context.Context, filePath string, data []byte) ([]byte, error) {
+	if a.isReadOnly(filePath) {
+		return nil, sftp.ErrSSHFxPermissionDenied
+	}
+
+	changes, err := unmarshalAttributes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := a.getObjectByPath(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, a.replaceObject(ctx, obj, obj.Container.CID, filePath, filePath, changes)
+}
+
+// replaceObject puts the copy of the object with the attributes changed into the container
+// and deletes the original object.
+func (a *App) replaceObject(ctx context.Context, obj *ObjectInfo, cnrID cid.ID, oldPath, newPath string, changes []Attribute) error {
+	address := newAddress(obj.Container.CID, obj.ObjectID)
+
+	if err := a.checkAccess(ctx, cnrID, acl.OpObjectPut); err != nil {
+		return err
+	}
+	if err := a.checkAccess(ctx, obj.Container.CID, acl.OpObjectDelete); err != nil {
+		return err
+	}
+
+	var (
+		newID oid.ID
+		err   error
+	)
+	err = withSessionRenewal(requestLogger(ctx), func() error {
+		newID, err = a.putObjectCopy(ctx, address, cnrID, changes)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	a.emitEvent(ctx, EventUpload, newPath, cnrID, &newID, obj.PayloadSize)
+
+	if err = a.deleteObject(ctx, address); err != nil {
+		return fmt.Errorf("delete original object: %w", err)
+	}
+	a.emitEvent(ctx, EventDelete, oldPath, obj.Container.CID, &obj.ObjectID, obj.PayloadSize)
+
+	return nil
+}
+
+// getObjectByPath returns the object by the path of the file in the container.
+func (a *App) getObjectByPath(ctx context.Context, filePath string) (*ObjectInfo, error) {
+	split := strings.SplitN(strings.TrimPrefix(filePath, delimiter), delimiter, 2)
+	if len(split) < 2 || split[1] == "" {
+		return nil, sftp.ErrSSHFxOpUnsupported
+	}
+
+	cnr, err := a.getContainerByName(ctx, split[0])
+	if err != nil {
+		return nil, err
+	}
+	return a.getObjectFileByName(ctx, cnr.CID, split[1])
+}
+
+func (a *App) objectAttributes(ctx context.Context, address oid.Address) ([]object.Attribute, error) {
+	ctx, span := startSpan(ctx, "neofs.head", attribute.Stringer("neofs.address", address))
+
+	var prm client.PrmObjectHead
+	if token := a.bearerToken(address.Container()); token != nil {
+		prm.WithBearerToken(*token)
+	}
+	hdr, err := a.layer().ObjectHead(ctx, address.Container(), address.Object(), a.signerFor(address.Container()), prm)
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return hdr.Attributes(), nil
+}
+
+// putObjectCopy puts the copy of the object with the attributes changed into the container,
+// empty values remove the attributes.
+func (a *App) putObjectCopy(ctx context.Context, address oid.Address, cnrID cid.ID, changes []Attribute) (_ oid.ID, err error) {
+	ctx, span := startSpan(ctx, "neofs.copy",
+		attribute.Stringer("neofs.address", address),
+		attribute.Stringer("neofs.container", cnrID))
+	defer func() { endSpan(span, err) }()
+
+	var getPrm client.PrmObjectGet
+	var putPrm client.PrmObjectPutInit
+	if token := a.bearerToken(address.Container()); token != nil {
+		getPrm.WithBearerToken(*token)
+	}
+	if token := a.bearerToken(cnrID); token != nil {
+		putPrm.WithBearerToken(*token)
+	}
+
+	hdr, payload, err := a.layer().ObjectGet(ctx, address.Container(), address.Object(), a.signerFor(address.Container()), getPrm)
+	if err != nil {
+		return oid.ID{}, err
+	}
+	defer func() { _ = payload.Close() }()
+
+	attrs := hdr.Attributes()
+	for _, change := range changes {
+		attrs = setAttribute(attrs, change.Key, change.Value)
+	}
+	res := attrs[:0]
+	for _, attr := range attrs {
+		if attr.Value() != "" {
+			res = append(res, attr)
+		}
+	}
+
+	obj := object.New()
+	obj.SetOwnerID(a.ownerFor(cnrID))
+	obj.SetContainerID(cnrID)
+	obj.SetAttributes(res...)
+
+	// The payload is read while it's written, so both calls include transferring it.
+	return a.layer().ObjectPut(ctx, *obj, a.signerFor(cnrID), putPrm, payload)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/layer/layertest"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+)
+
+// countingReader counts the payload bytes read.
+type countingReader struct {
+	io.ReadCloser
+	read *int
+}
+
+func (r countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	*r.read += n
+	return n, err
+}
+
+// streamLayer records how much of the object payload was read before the put started.
+type streamLayer struct {
+	*layertest.Memory
+	read        int
+	readAtStart int
+}
+
+func (s *streamLayer) ObjectGet(ctx context.Context, cnrID cid.ID, objID oid.ID, signer user.Signer, prm client.PrmObjectGet) (object.Object, io.ReadCloser, error) {
+	hdr, payload, err := s.Memory.ObjectGet(ctx, cnrID, objID, signer, prm)
+	if err != nil {
+		return hdr, nil, err
+	}
+	return hdr, countingReader{ReadCloser: payload, read: &s.read}, nil
+}
+
+func (s *streamLayer) ObjectPut(ctx context.Context, hdr object.Object, signer user.Signer, prm client.PrmObjectPutInit, payload io.Reader) (oid.ID, error) {
+	s.readAtStart = s.read
+	return s.Memory.ObjectPut(ctx, hdr, signer, prm, payload)
+}
+
+func TestPutObjectCopy(t *testing.T) {
+	app, storage := newMemoryApp(t, &SftpServerConfig{})
+	ctx := context.Background()
+
+	payload := make([]byte, 10<<20+123)
+	_, err := rand.Read(payload)
+	require.NoError(t, err)
+
+	require.NoError(t, app.Filecmd(sftp.NewRequest("Mkdir", "/docs")))
+	uploadFile(t, app, "/docs/big.bin", string(payload))
+	cnr, err := app.getContainerByName(ctx, "docs")
+	require.NoError(t, err)
+	id, _ := storage.Objects(cnr.CID)[0].ID()
+
+	streams := &streamLayer{Memory: storage}
+	app.ReplaceLayer(streams)
+
+	newID, err := app.putObjectCopy(ctx, newAddress(cnr.CID, id), cnr.CID, []Attribute{{Key: "Color", Value: "red"}})
+	require.NoError(t, err)
+	// The payload isn't buffered, it's read while the copy is written.
+	require.Zero(t, streams.readAtStart)
+	require.Equal(t, len(payload), streams.read)
+
+	for _, obj := range storage.Objects(cnr.CID) {
+		if objID, _ := obj.ID(); objID == newID {
+			require.True(t, bytes.Equal(payload, obj.Payload()))
+			require.Contains(t, obj.Attributes(), newAttribute("Color", "red"))
+			return
+		}
+	}
+	t.Fatal("copy isn't stored")
+}
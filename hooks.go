@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/nspcc-dev/neofs-sftp-gw/handlers"
+	"go.uber.org/zap"
+)
+
+// hookQueueSize limits events waiting for hooks, the new ones are dropped when it's full.
+const hookQueueSize = 1000
+
+// hookRunner runs hooks of events one by one in the background.
+type hookRunner struct {
+	log    *zap.Logger
+	hooks  []hookConfig
+	client *http.Client
+	queue  chan handlers.Event
+}
+
+func newHookRunner(l *zap.Logger, hooks []hookConfig) *hookRunner {
+	return &hookRunner{
+		log:    l,
+		hooks:  hooks,
+		client: &http.Client{},
+		queue:  make(chan handlers.Event, hookQueueSize),
+	}
+}
+
+// handles checks whether the hook is run for the event type.
+func (h hookConfig) handles(typ string) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, e := range h.Events {
+		if e == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueue schedules hooks of the event, it's used as the event handler of the App.
+func (r *hookRunner) enqueue(e handlers.Event) {
+	select {
+	case r.queue <- e:
+	default:
+		r.log.Warn("hooks queue is full, event dropped", zap.String("type", e.Type), zap.String("path", e.Path))
+	}
+}
+
+func (r *hookRunner) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-r.queue:
+			for _, h := range r.hooks {
+				if !h.handles(e.Type) {
+					continue
+				}
+				if err := r.runHook(ctx, h, e); err != nil {
+					r.log.Error("hook failed", zap.String("type", e.Type), zap.String("path", e.Path), zap.Error(err))
+				}
+			}
+		}
+	}
+}
+
+func (r *hookRunner) runHook(ctx context.Context, h hookConfig, e handlers.Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+
+	if h.Webhook != "" {
+		return postJSON(ctx, r.client, h.Webhook, payload)
+	}
+
+	cmd := exec.CommandContext(ctx, h.Command[0], h.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(),
+		"SFTP_GW_EVENT="+e.Type,
+		"SFTP_GW_PATH="+e.Path,
+		"SFTP_GW_CONTAINER="+e.Container,
+		"SFTP_GW_OBJECT="+e.Object,
+		"SFTP_GW_SIZE="+strconv.FormatInt(e.Size, 10),
+		"SFTP_GW_USER="+e.User,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("run %s: %w, output: %s", h.Command[0], err, out)
+	}
+	return nil
+}
+
+// postJSON sends the JSON payload to the URL and checks the response status.
+func postJSON(ctx context.Context, client *http.Client, url string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sftp-gw/handlers"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestHookRunner(t *testing.T) {
+	events := make(chan handlers.Event, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e handlers.Event
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&e))
+		events <- e
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := newHookRunner(zap.NewNop(), []hookConfig{{
+		Events:  []string{handlers.EventUpload},
+		Webhook: srv.URL,
+		Timeout: time.Second,
+	}})
+	go runner.run(ctx)
+
+	runner.enqueue(handlers.Event{Type: handlers.EventDelete, Path: "/cnr/deleted"})
+	runner.enqueue(handlers.Event{Type: handlers.EventUpload, Path: "/cnr/uploaded", Size: 42})
+
+	select {
+	case e := <-events:
+		require.Equal(t, "/cnr/uploaded", e.Path)
+		require.EqualValues(t, 42, e.Size)
+	case <-time.After(5 * time.Second):
+		t.Fatal("hook isn't run")
+	}
+	require.Empty(t, events)
+}
@@ -0,0 +1,49 @@
+// Package layer defines the storage the SFTP handlers work with and its NeoFS implementation.
+package layer
+
+import (
+	"context"
+	"io"
+
+	"github.com/nspcc-dev/neofs-sdk-go/accounting"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/container"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	neofscrypto "github.com/nspcc-dev/neofs-sdk-go/crypto"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+)
+
+// Layer is the storage of containers and objects. Container methods have the signatures of
+// the NeoFS client, so SDK waiters can be used with it.
+type Layer interface {
+	// ObjectSearch calls f for IDs of the container objects matching the filters until f returns true.
+	// Filters are passed apart from prm since they can't be read from it.
+	ObjectSearch(ctx context.Context, cnrID cid.ID, signer user.Signer, prm client.PrmObjectSearch, filters object.SearchFilters, f func(oid.ID) bool) error
+	// ObjectHead returns the object header.
+	ObjectHead(ctx context.Context, cnrID cid.ID, objID oid.ID, signer user.Signer, prm client.PrmObjectHead) (*object.Object, error)
+	// ObjectGet returns the object header and the reader of its payload, the reader must be closed.
+	ObjectGet(ctx context.Context, cnrID cid.ID, objID oid.ID, signer user.Signer, prm client.PrmObjectGet) (object.Object, io.ReadCloser, error)
+	// ObjectRange returns the reader of length payload bytes from offset, the reader must be closed.
+	ObjectRange(ctx context.Context, cnrID cid.ID, objID oid.ID, offset, length uint64, signer user.Signer, prm client.PrmObjectRange) (io.ReadCloser, error)
+	// ObjectPut stores the object with the header and the payload read until EOF, it returns the object ID.
+	ObjectPut(ctx context.Context, hdr object.Object, signer user.Signer, prm client.PrmObjectPutInit, payload io.Reader) (oid.ID, error)
+	// ObjectDelete deletes the object and returns the ID of its tombstone.
+	ObjectDelete(ctx context.Context, cnrID cid.ID, objID oid.ID, signer user.Signer, prm client.PrmObjectDelete) (oid.ID, error)
+
+	ContainerPut(ctx context.Context, cnr container.Container, signer neofscrypto.Signer, prm client.PrmContainerPut) (cid.ID, error)
+	ContainerGet(ctx context.Context, cnrID cid.ID, prm client.PrmContainerGet) (container.Container, error)
+	ContainerList(ctx context.Context, owner user.ID, prm client.PrmContainerList) ([]cid.ID, error)
+	ContainerDelete(ctx context.Context, cnrID cid.ID, signer neofscrypto.Signer, prm client.PrmContainerDelete) error
+	ContainerEACL(ctx context.Context, cnrID cid.ID, prm client.PrmContainerEACL) (eacl.Table, error)
+	ContainerSetEACL(ctx context.Context, table eacl.Table, signer user.Signer, prm client.PrmContainerSetEACL) error
+
+	BalanceGet(ctx context.Context, prm client.PrmBalanceGet) (accounting.Decimal, error)
+	NetworkInfo(ctx context.Context, prm client.PrmNetworkInfo) (netmap.NetworkInfo, error)
+
+	// Close releases the connections, the layer can't be used after.
+	Close()
+}
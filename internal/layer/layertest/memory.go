@@ -0,0 +1,445 @@
+// Package layertest provides the in-memory storage layer for unit tests, so handlers can be
+// tested without the NeoFS network.
+package layertest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/nspcc-dev/neofs-sdk-go/accounting"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"github.com/nspcc-dev/neofs-sdk-go/container"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	neofscrypto "github.com/nspcc-dev/neofs-sdk-go/crypto"
+	"github.com/nspcc-dev/neofs-sdk-go/eacl"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/layer"
+)
+
+type (
+	// Memory is the layer.Layer keeping containers and objects in memory. Stored objects are
+	// visible at once, all of them are root ones, and access rules aren't checked.
+	Memory struct {
+		mu         sync.Mutex
+		lastID     uint64
+		containers []*memContainer
+		balance    accounting.Decimal
+		netInfo    netmap.NetworkInfo
+		// errs are returned by the calls of the methods instead of doing them.
+		errs map[string]error
+	}
+
+	memContainer struct {
+		id      cid.ID
+		cnr     container.Container
+		table   *eacl.Table
+		objects []object.Object
+	}
+)
+
+var _ layer.Layer = (*Memory)(nil)
+
+// NewMemory returns the empty Memory layer, the network is at epoch 1 with a block per second.
+func NewMemory() *Memory {
+	m := &Memory{errs: make(map[string]error)}
+	m.netInfo.SetCurrentEpoch(1)
+	m.netInfo.SetMsPerBlock(1000)
+	m.netInfo.SetEpochDuration(240)
+	m.netInfo.SetMaxObjectSize(64 << 20)
+	return m
+}
+
+// SetError makes calls of the Layer method with the name return err without doing anything,
+// nil err makes the method work again.
+func (m *Memory) SetError(method string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err == nil {
+		delete(m.errs, method)
+		return
+	}
+	m.errs[method] = err
+}
+
+// SetBalance sets the balance returned by BalanceGet.
+func (m *Memory) SetBalance(d accounting.Decimal) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.balance = d
+}
+
+// SetNetworkInfo sets the network parameters returned by NetworkInfo, the current epoch
+// is the creation epoch of new objects.
+func (m *Memory) SetNetworkInfo(ni netmap.NetworkInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.netInfo = ni
+}
+
+// Objects returns the headers of the container objects with their payloads in the order they were put.
+func (m *Memory) Objects(cnrID cid.ID) []object.Object {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := m.container(cnrID)
+	if c == nil {
+		return nil
+	}
+	res := make([]object.Object, len(c.objects))
+	for i := range c.objects {
+		c.objects[i].CopyTo(&res[i])
+	}
+	return res
+}
+
+// nextID returns the unique hash for IDs of containers and objects, mu must be held.
+func (m *Memory) nextID() [sha256.Size]byte {
+	m.lastID++
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], m.lastID)
+	return sha256.Sum256(b[:])
+}
+
+// container returns the stored container or nil, mu must be held.
+func (m *Memory) container(cnrID cid.ID) *memContainer {
+	for _, c := range m.containers {
+		if c.id == cnrID {
+			return c
+		}
+	}
+	return nil
+}
+
+// object returns the index of the container object, mu must be held.
+func (m *Memory) object(cnrID cid.ID, objID oid.ID) (*memContainer, int, error) {
+	c := m.container(cnrID)
+	if c == nil {
+		return nil, 0, apistatus.ErrContainerNotFound
+	}
+	for i := range c.objects {
+		if id, _ := c.objects[i].ID(); id == objID {
+			return c, i, nil
+		}
+	}
+	return nil, 0, apistatus.ErrObjectNotFound
+}
+
+// ObjectSearch implements layer.Layer. Filters by attributes are applied, the other
+// ones match all objects.
+func (m *Memory) ObjectSearch(_ context.Context, cnrID cid.ID, _ user.Signer, _ client.PrmObjectSearch, filters object.SearchFilters, f func(oid.ID) bool) error {
+	m.mu.Lock()
+	if err := m.errs["ObjectSearch"]; err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	c := m.container(cnrID)
+	if c == nil {
+		m.mu.Unlock()
+		return apistatus.ErrContainerNotFound
+	}
+	var ids []oid.ID
+	for i := range c.objects {
+		if matchFilters(c.objects[i], filters) {
+			id, _ := c.objects[i].ID()
+			ids = append(ids, id)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, id := range ids {
+		if f(id) {
+			break
+		}
+	}
+	return nil
+}
+
+func matchFilters(obj object.Object, filters object.SearchFilters) bool {
+	for _, f := range filters {
+		if f.IsNonAttribute() {
+			continue
+		}
+
+		value, ok := attribute(obj, f.Header())
+		switch f.Operation() {
+		case object.MatchStringEqual:
+			ok = ok && value == f.Value()
+		case object.MatchStringNotEqual:
+			ok = ok && value != f.Value()
+		case object.MatchNotPresent:
+			ok = !ok
+		case object.MatchCommonPrefix:
+			ok = ok && strings.HasPrefix(value, f.Value())
+		default:
+			ok = false
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func attribute(obj object.Object, key string) (string, bool) {
+	for _, attr := range obj.Attributes() {
+		if attr.Key() == key {
+			return attr.Value(), true
+		}
+	}
+	return "", false
+}
+
+// ObjectHead implements layer.Layer.
+func (m *Memory) ObjectHead(_ context.Context, cnrID cid.ID, objID oid.ID, _ user.Signer, _ client.PrmObjectHead) (*object.Object, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.errs["ObjectHead"]; err != nil {
+		return nil, err
+	}
+	c, i, err := m.object(cnrID, objID)
+	if err != nil {
+		return nil, err
+	}
+	var hdr object.Object
+	c.objects[i].CopyTo(&hdr)
+	return hdr.CutPayload(), nil
+}
+
+// ObjectGet implements layer.Layer.
+func (m *Memory) ObjectGet(_ context.Context, cnrID cid.ID, objID oid.ID, _ user.Signer, _ client.PrmObjectGet) (object.Object, io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.errs["ObjectGet"]; err != nil {
+		return object.Object{}, nil, err
+	}
+	c, i, err := m.object(cnrID, objID)
+	if err != nil {
+		return object.Object{}, nil, err
+	}
+	var hdr object.Object
+	c.objects[i].CopyTo(&hdr)
+	payload := hdr.Payload()
+	return *hdr.CutPayload(), io.NopCloser(bytes.NewReader(payload)), nil
+}
+
+// ObjectRange implements layer.Layer.
+func (m *Memory) ObjectRange(_ context.Context, cnrID cid.ID, objID oid.ID, offset, length uint64, _ user.Signer, _ client.PrmObjectRange) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.errs["ObjectRange"]; err != nil {
+		return nil, err
+	}
+	c, i, err := m.object(cnrID, objID)
+	if err != nil {
+		return nil, err
+	}
+	payload := c.objects[i].Payload()
+	if length == 0 || offset+length < offset || offset+length > uint64(len(payload)) {
+		return nil, apistatus.ErrObjectOutOfRange
+	}
+	res := make([]byte, length)
+	copy(res, payload[offset:])
+	return io.NopCloser(bytes.NewReader(res)), nil
+}
+
+// ObjectPut implements layer.Layer. The object gets the current epoch as the creation one.
+func (m *Memory) ObjectPut(_ context.Context, hdr object.Object, _ user.Signer, _ client.PrmObjectPutInit, payload io.Reader) (oid.ID, error) {
+	data, err := io.ReadAll(payload)
+	if err != nil {
+		return oid.ID{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err = m.errs["ObjectPut"]; err != nil {
+		return oid.ID{}, err
+	}
+	cnrID, ok := hdr.ContainerID()
+	if !ok {
+		return oid.ID{}, errors.New("missing container in object header")
+	}
+	c := m.container(cnrID)
+	if c == nil {
+		return oid.ID{}, apistatus.ErrContainerNotFound
+	}
+
+	var id oid.ID
+	id.SetSHA256(m.nextID())
+
+	var obj object.Object
+	hdr.CopyTo(&obj)
+	obj.SetID(id)
+	obj.SetCreationEpoch(m.netInfo.CurrentEpoch())
+	obj.SetPayload(data)
+	obj.SetPayloadSize(uint64(len(data)))
+	c.objects = append(c.objects, obj)
+	return id, nil
+}
+
+// ObjectDelete implements layer.Layer. The object is removed at once, no tombstone is stored.
+func (m *Memory) ObjectDelete(_ context.Context, cnrID cid.ID, objID oid.ID, _ user.Signer, _ client.PrmObjectDelete) (oid.ID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.errs["ObjectDelete"]; err != nil {
+		return oid.ID{}, err
+	}
+	c, i, err := m.object(cnrID, objID)
+	if err != nil {
+		return oid.ID{}, err
+	}
+	c.objects = append(c.objects[:i], c.objects[i+1:]...)
+
+	var tombstone oid.ID
+	tombstone.SetSHA256(m.nextID())
+	return tombstone, nil
+}
+
+// ContainerPut implements layer.Layer.
+func (m *Memory) ContainerPut(_ context.Context, cnr container.Container, _ neofscrypto.Signer, _ client.PrmContainerPut) (cid.ID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.errs["ContainerPut"]; err != nil {
+		return cid.ID{}, err
+	}
+	var id cid.ID
+	id.SetSHA256(m.nextID())
+
+	c := &memContainer{id: id}
+	cnr.CopyTo(&c.cnr)
+	m.containers = append(m.containers, c)
+	return id, nil
+}
+
+// ContainerGet implements layer.Layer.
+func (m *Memory) ContainerGet(_ context.Context, cnrID cid.ID, _ client.PrmContainerGet) (container.Container, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.errs["ContainerGet"]; err != nil {
+		return container.Container{}, err
+	}
+	c := m.container(cnrID)
+	if c == nil {
+		return container.Container{}, apistatus.ErrContainerNotFound
+	}
+	var cnr container.Container
+	c.cnr.CopyTo(&cnr)
+	return cnr, nil
+}
+
+// ContainerList implements layer.Layer, containers are listed in the order they were put.
+func (m *Memory) ContainerList(_ context.Context, owner user.ID, _ client.PrmContainerList) ([]cid.ID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.errs["ContainerList"]; err != nil {
+		return nil, err
+	}
+	var res []cid.ID
+	for _, c := range m.containers {
+		if c.cnr.Owner().Equals(owner) {
+			res = append(res, c.id)
+		}
+	}
+	return res, nil
+}
+
+// ContainerDelete implements layer.Layer, the container objects are deleted with it.
+func (m *Memory) ContainerDelete(_ context.Context, cnrID cid.ID, _ neofscrypto.Signer, _ client.PrmContainerDelete) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.errs["ContainerDelete"]; err != nil {
+		return err
+	}
+	for i, c := range m.containers {
+		if c.id == cnrID {
+			m.containers = append(m.containers[:i], m.containers[i+1:]...)
+			return nil
+		}
+	}
+	return apistatus.ErrContainerNotFound
+}
+
+// ContainerEACL implements layer.Layer.
+func (m *Memory) ContainerEACL(_ context.Context, cnrID cid.ID, _ client.PrmContainerEACL) (eacl.Table, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.errs["ContainerEACL"]; err != nil {
+		return eacl.Table{}, err
+	}
+	c := m.container(cnrID)
+	if c == nil {
+		return eacl.Table{}, apistatus.ErrContainerNotFound
+	}
+	if c.table == nil {
+		return eacl.Table{}, apistatus.ErrEACLNotFound
+	}
+	var table eacl.Table
+	c.table.CopyTo(&table)
+	return table, nil
+}
+
+// ContainerSetEACL implements layer.Layer.
+func (m *Memory) ContainerSetEACL(_ context.Context, table eacl.Table, _ user.Signer, _ client.PrmContainerSetEACL) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.errs["ContainerSetEACL"]; err != nil {
+		return err
+	}
+	cnrID, ok := table.CID()
+	if !ok {
+		return client.ErrMissingEACLContainer
+	}
+	c := m.container(cnrID)
+	if c == nil {
+		return apistatus.ErrContainerNotFound
+	}
+	c.table = new(eacl.Table)
+	table.CopyTo(c.table)
+	return nil
+}
+
+// BalanceGet implements layer.Layer.
+func (m *Memory) BalanceGet(_ context.Context, _ client.PrmBalanceGet) (accounting.Decimal, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.errs["BalanceGet"]; err != nil {
+		return accounting.Decimal{}, err
+	}
+	return m.balance, nil
+}
+
+// NetworkInfo implements layer.Layer.
+func (m *Memory) NetworkInfo(_ context.Context, _ client.PrmNetworkInfo) (netmap.NetworkInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.errs["NetworkInfo"]; err != nil {
+		return netmap.NetworkInfo{}, err
+	}
+	return m.netInfo, nil
+}
+
+// Close implements layer.Layer, the stored data is kept.
+func (m *Memory) Close() {}
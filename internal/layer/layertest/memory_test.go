@@ -0,0 +1,76 @@
+package layertest
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"github.com/nspcc-dev/neofs-sdk-go/container"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	usertest "github.com/nspcc-dev/neofs-sdk-go/user/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	var cnr container.Container
+	cnr.Init()
+	cnr.SetOwner(usertest.ID(t))
+	cnrID, err := m.ContainerPut(ctx, cnr, nil, client.PrmContainerPut{})
+	require.NoError(t, err)
+
+	_, err = m.ContainerEACL(ctx, cnrID, client.PrmContainerEACL{})
+	require.ErrorIs(t, err, apistatus.ErrEACLNotFound)
+	_, err = m.ContainerGet(ctx, cid.ID{}, client.PrmContainerGet{})
+	require.ErrorIs(t, err, apistatus.ErrContainerNotFound)
+
+	put := func(name, payload string) oid.ID {
+		hdr := object.New()
+		hdr.SetContainerID(cnrID)
+		attr := object.NewAttribute()
+		attr.SetKey(object.AttributeFileName)
+		attr.SetValue(name)
+		hdr.SetAttributes(*attr)
+
+		id, err := m.ObjectPut(ctx, *hdr, nil, client.PrmObjectPutInit{}, strings.NewReader(payload))
+		require.NoError(t, err)
+		return id
+	}
+	first := put("a.txt", "first")
+	second := put("b.txt", "second")
+
+	search := func(filters object.SearchFilters) []oid.ID {
+		var ids []oid.ID
+		err := m.ObjectSearch(ctx, cnrID, nil, client.PrmObjectSearch{}, filters, func(id oid.ID) bool {
+			ids = append(ids, id)
+			return false
+		})
+		require.NoError(t, err)
+		return ids
+	}
+	filters := object.NewSearchFilters()
+	filters.AddRootFilter()
+	require.Equal(t, []oid.ID{first, second}, search(filters))
+	filters.AddFilter(object.AttributeFileName, "b.txt", object.MatchStringEqual)
+	require.Equal(t, []oid.ID{second}, search(filters))
+
+	rng, err := m.ObjectRange(ctx, cnrID, second, 1, 3, nil, client.PrmObjectRange{})
+	require.NoError(t, err)
+	data, err := io.ReadAll(rng)
+	require.NoError(t, err)
+	require.Equal(t, "eco", string(data))
+	_, err = m.ObjectRange(ctx, cnrID, second, 4, 3, nil, client.PrmObjectRange{})
+	require.ErrorIs(t, err, apistatus.ErrObjectOutOfRange)
+
+	_, err = m.ObjectDelete(ctx, cnrID, first, nil, client.PrmObjectDelete{})
+	require.NoError(t, err)
+	_, err = m.ObjectHead(ctx, cnrID, first, nil, client.PrmObjectHead{})
+	require.ErrorIs(t, err, apistatus.ErrObjectNotFound)
+}
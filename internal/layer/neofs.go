@@ -0,0 +1,86 @@
+package layer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+)
+
+// putChunkSize is the size of payload chunks written to the object stream, larger chunks
+// are split by the client anyway.
+const putChunkSize = 3 << 20
+
+// putBuffers keeps the chunk buffers of finished puts for the next ones.
+var putBuffers = sync.Pool{
+	New: func() any {
+		buf := make([]byte, putChunkSize)
+		return &buf
+	},
+}
+
+// NeoFS is the Layer working with the NeoFS network through the connection pool.
+// Container, accounting and netmap methods are the ones of the pool.
+type NeoFS struct {
+	*pool.Pool
+}
+
+var _ Layer = (*NeoFS)(nil)
+
+// NewNeoFS returns the Layer working through the dialed connection pool.
+func NewNeoFS(p *pool.Pool) *NeoFS {
+	return &NeoFS{Pool: p}
+}
+
+// ObjectSearch implements Layer.
+func (n *NeoFS) ObjectSearch(ctx context.Context, cnrID cid.ID, signer user.Signer, prm client.PrmObjectSearch, filters object.SearchFilters, f func(oid.ID) bool) error {
+	prm.SetFilters(filters)
+	res, err := n.ObjectSearchInit(ctx, cnrID, signer, prm)
+	if err != nil {
+		return fmt.Errorf("init searching: %w", err)
+	}
+	defer res.Close()
+
+	return res.Iterate(f)
+}
+
+// ObjectGet implements Layer.
+func (n *NeoFS) ObjectGet(ctx context.Context, cnrID cid.ID, objID oid.ID, signer user.Signer, prm client.PrmObjectGet) (object.Object, io.ReadCloser, error) {
+	hdr, payload, err := n.ObjectGetInit(ctx, cnrID, objID, signer, prm)
+	if err != nil {
+		return object.Object{}, nil, fmt.Errorf("ObjectGetInit: %w", err)
+	}
+	return hdr, payload, nil
+}
+
+// ObjectRange implements Layer.
+func (n *NeoFS) ObjectRange(ctx context.Context, cnrID cid.ID, objID oid.ID, offset, length uint64, signer user.Signer, prm client.PrmObjectRange) (io.ReadCloser, error) {
+	return n.ObjectRangeInit(ctx, cnrID, objID, offset, length, signer, prm)
+}
+
+// ObjectPut implements Layer.
+func (n *NeoFS) ObjectPut(ctx context.Context, hdr object.Object, signer user.Signer, prm client.PrmObjectPutInit, payload io.Reader) (oid.ID, error) {
+	writer, err := n.ObjectPutInit(ctx, hdr, signer, prm)
+	if err != nil {
+		return oid.ID{}, fmt.Errorf("ObjectPutInit: %w", err)
+	}
+
+	buf := putBuffers.Get().(*[]byte)
+	defer putBuffers.Put(buf)
+
+	if _, err = io.CopyBuffer(writer, payload, *buf); err != nil {
+		_ = writer.Close()
+		return oid.ID{}, fmt.Errorf("write: %w", err)
+	}
+	if err = writer.Close(); err != nil {
+		return oid.ID{}, fmt.Errorf("writer close: %w", err)
+	}
+	return writer.GetResult().StoredObjectID(), nil
+}
@@ -0,0 +1,174 @@
+// Package proxyproto implements server side of HAProxy PROXY protocol v1 and v2.
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	v1Prefix    = "PROXY "
+	v1MaxLength = 107
+
+	v2HeaderLength = 16
+
+	v2CmdLocal = 0x0
+	v2CmdProxy = 0x1
+
+	v2FamilyTCP4 = 0x11
+	v2FamilyTCP6 = 0x21
+)
+
+var v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// ErrNoHeader is returned if the connection doesn't start with PROXY protocol header.
+var ErrNoHeader = errors.New("no PROXY protocol header")
+
+// Conn is a connection with the client address taken from PROXY protocol header.
+type Conn struct {
+	net.Conn
+	r      *bufio.Reader
+	remote net.Addr
+}
+
+// Read reads data remaining after the header.
+func (c *Conn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// RemoteAddr returns the original client address, or the peer address for
+// LOCAL and UNKNOWN headers.
+func (c *Conn) RemoteAddr() net.Addr {
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// ReadHeader reads PROXY protocol header from the connection within the timeout
+// and returns the connection reporting the original client address.
+func ReadHeader(conn net.Conn, timeout time.Duration) (*Conn, error) {
+	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	c := &Conn{
+		Conn: conn,
+		r:    bufio.NewReader(conn),
+	}
+
+	var err error
+	c.remote, err = parseHeader(c.r)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, conn.SetReadDeadline(time.Time{})
+}
+
+func parseHeader(r *bufio.Reader) (net.Addr, error) {
+	prefix, err := r.Peek(len(v1Prefix))
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	if string(prefix) == v1Prefix {
+		return parseV1(r)
+	}
+
+	prefix, err = r.Peek(len(v2Signature))
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	if bytes.Equal(prefix, v2Signature) {
+		return parseV2(r)
+	}
+
+	return nil, ErrNoHeader
+}
+
+func parseV1(r *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("read v1 header: %w", err)
+		}
+		line = append(line, b)
+		if len(line) > v1MaxLength {
+			return nil, errors.New("v1 header is too long")
+		}
+	}
+
+	fields := strings.Fields(string(line))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("invalid v1 header %q", strings.TrimSpace(string(line)))
+	}
+
+	ip := net.ParseIP(fields[2])
+	if ip == nil {
+		return nil, fmt.Errorf("invalid v1 source address %q", fields[2])
+	}
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid v1 source port %q", fields[4])
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+func parseV2(r *bufio.Reader) (net.Addr, error) {
+	var hdr [v2HeaderLength]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, fmt.Errorf("read v2 header: %w", err)
+	}
+
+	if version := hdr[12] >> 4; version != 2 {
+		return nil, fmt.Errorf("unsupported v2 header version %d", version)
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(hdr[14:16]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, fmt.Errorf("read v2 addresses: %w", err)
+	}
+
+	switch cmd := hdr[12] & 0x0f; cmd {
+	case v2CmdLocal:
+		return nil, nil
+	case v2CmdProxy:
+	default:
+		return nil, fmt.Errorf("unsupported v2 command %d", cmd)
+	}
+
+	switch hdr[13] {
+	case v2FamilyTCP4:
+		if len(payload) < 12 {
+			return nil, errors.New("short v2 IPv4 addresses")
+		}
+		return &net.TCPAddr{
+			IP:   net.IP(payload[0:4]),
+			Port: int(binary.BigEndian.Uint16(payload[8:10])),
+		}, nil
+	case v2FamilyTCP6:
+		if len(payload) < 36 {
+			return nil, errors.New("short v2 IPv6 addresses")
+		}
+		return &net.TCPAddr{
+			IP:   net.IP(payload[0:16]),
+			Port: int(binary.BigEndian.Uint16(payload[32:34])),
+		}, nil
+	default:
+		// Unsupported families (UDP, UNIX) are handled like LOCAL.
+		return nil, nil
+	}
+}
@@ -0,0 +1,84 @@
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func v2Header(cmd, family byte, payload []byte) []byte {
+	hdr := append([]byte{}, v2Signature...)
+	hdr = append(hdr, 0x20|cmd, family)
+	hdr = binary.BigEndian.AppendUint16(hdr, uint16(len(payload)))
+	return append(hdr, payload...)
+}
+
+func TestParseHeader(t *testing.T) {
+	ipv4 := []byte{192, 168, 0, 1, 10, 0, 0, 1, 0x30, 0x39, 0x00, 0x16}
+	ipv6 := make([]byte, 36)
+	copy(ipv6, net.ParseIP("2001:db8::1"))
+	binary.BigEndian.PutUint16(ipv6[32:], 2022)
+
+	for name, tc := range map[string]struct {
+		header string
+		addr   string
+	}{
+		"v1 tcp4":    {header: "PROXY TCP4 192.168.0.1 10.0.0.1 12345 22\r\n", addr: "192.168.0.1:12345"},
+		"v1 tcp6":    {header: "PROXY TCP6 2001:db8::1 ::1 2022 22\r\n", addr: "[2001:db8::1]:2022"},
+		"v1 unknown": {header: "PROXY UNKNOWN\r\n"},
+		"v2 tcp4":    {header: string(v2Header(v2CmdProxy, v2FamilyTCP4, ipv4)), addr: "192.168.0.1:12345"},
+		"v2 tcp6":    {header: string(v2Header(v2CmdProxy, v2FamilyTCP6, ipv6)), addr: "[2001:db8::1]:2022"},
+		"v2 local":   {header: string(v2Header(v2CmdLocal, 0, nil))},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := bufio.NewReader(bytes.NewBufferString(tc.header + "SSH-2.0-client\r\n"))
+
+			addr, err := parseHeader(r)
+			require.NoError(t, err)
+			if tc.addr == "" {
+				require.Nil(t, addr)
+			} else {
+				require.Equal(t, tc.addr, addr.String())
+			}
+
+			rest, err := r.ReadString('\n')
+			require.NoError(t, err)
+			require.Equal(t, "SSH-2.0-client\r\n", rest)
+		})
+	}
+
+	for name, header := range map[string]string{
+		"no header":   "SSH-2.0-client\r\n",
+		"v1 garbage":  "PROXY TCP4 nonsense\r\n",
+		"v1 too long": "PROXY " + string(bytes.Repeat([]byte{'1'}, v1MaxLength)) + "\r\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseHeader(bufio.NewReader(bytes.NewBufferString(header)))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestReadHeader(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+
+	go func() {
+		_, _ = client.Write([]byte("PROXY TCP4 203.0.113.7 10.0.0.1 40000 22\r\nhello"))
+		_ = client.Close()
+	}()
+
+	conn, err := ReadHeader(server, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, "203.0.113.7:40000", conn.RemoteAddr().String())
+
+	buf := make([]byte, 5)
+	_, err = conn.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(buf))
+}
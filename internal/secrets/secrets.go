@@ -0,0 +1,126 @@
+// Package secrets resolves references to secrets kept out of the configuration files.
+//
+// A reference is a URI with one of the supported schemes:
+//
+//	vault://secret/data/sftp-gw#passphrase  field of HashiCorp Vault secret (KV v1 or v2 API path)
+//	env://WALLET_PASSPHRASE                 environment variable
+//	file:///run/secrets/passphrase          file content without the trailing newline
+//
+// Vault is accessed with VAULT_ADDR and VAULT_TOKEN environment variables, VAULT_NAMESPACE
+// is sent if it's set. Values without these schemes are returned as is.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// vaultTimeout limits requests to Vault.
+const vaultTimeout = 10 * time.Second
+
+type resolver func(ctx context.Context, ref *url.URL) (string, error)
+
+var resolvers = map[string]resolver{
+	"vault": readVault,
+	"env":   readEnv,
+	"file":  readFile,
+}
+
+// IsReference checks whether the value refers to a secret.
+func IsReference(value string) bool {
+	scheme, _, ok := strings.Cut(value, "://")
+	if !ok {
+		return false
+	}
+	_, ok = resolvers[strings.ToLower(scheme)]
+	return ok
+}
+
+// Resolve returns the secret the value refers to or the value itself if it's not a reference.
+func Resolve(ctx context.Context, value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+	ref, err := url.Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid secret reference: %w", err)
+	}
+	secret, err := resolvers[strings.ToLower(ref.Scheme)](ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("secret %s://%s%s: %w", ref.Scheme, ref.Host, ref.Path, err)
+	}
+	return secret, nil
+}
+
+func readEnv(_ context.Context, ref *url.URL) (string, error) {
+	value, ok := os.LookupEnv(ref.Host)
+	if !ok {
+		return "", errors.New("environment variable isn't set")
+	}
+	return value, nil
+}
+
+func readFile(_ context.Context, ref *url.URL) (string, error) {
+	data, err := os.ReadFile(ref.Host + ref.Path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func readVault(ctx context.Context, ref *url.URL) (string, error) {
+	addr, token := os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return "", errors.New("VAULT_ADDR and VAULT_TOKEN must be set")
+	}
+	if ref.Fragment == "" {
+		return "", errors.New("field of the secret must be set after #")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, vaultTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		strings.TrimRight(addr, "/")+"/v1/"+ref.Host+ref.Path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("vault responded %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var res struct {
+		Data map[string]any `json:"data"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", fmt.Errorf("decode vault response: %w", err)
+	}
+	// KV v2 nests the fields of the secret in data.data.
+	fields := res.Data
+	if nested, ok := fields["data"].(map[string]any); ok {
+		fields = nested
+	}
+	value, ok := fields[ref.Fragment].(string)
+	if !ok {
+		return "", fmt.Errorf("no string field %q", ref.Fragment)
+	}
+	return value, nil
+}
@@ -0,0 +1,80 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("plain", func(t *testing.T) {
+		res, err := Resolve(ctx, "password")
+		require.NoError(t, err)
+		require.Equal(t, "password", res)
+		require.False(t, IsReference("http://example.com"))
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("SECRETS_TEST_PASS", "from env")
+		res, err := Resolve(ctx, "env://SECRETS_TEST_PASS")
+		require.NoError(t, err)
+		require.Equal(t, "from env", res)
+
+		_, err = Resolve(ctx, "env://SECRETS_TEST_MISSING")
+		require.Error(t, err)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pass")
+		require.NoError(t, os.WriteFile(path, []byte("from file\n"), 0o600))
+		res, err := Resolve(ctx, "file://"+path)
+		require.NoError(t, err)
+		require.Equal(t, "from file", res)
+	})
+
+	t.Run("vault", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Vault-Token") != "token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			switch r.URL.Path {
+			case "/v1/secret/data/sftp-gw":
+				_, _ = w.Write([]byte(`{"data":{"data":{"passphrase":"kv2"},"metadata":{"version":1}}}`))
+			case "/v1/kv/sftp-gw":
+				_, _ = w.Write([]byte(`{"data":{"passphrase":"kv1"}}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer srv.Close()
+		t.Setenv("VAULT_ADDR", srv.URL)
+		t.Setenv("VAULT_TOKEN", "token")
+
+		res, err := Resolve(ctx, "vault://secret/data/sftp-gw#passphrase")
+		require.NoError(t, err)
+		require.Equal(t, "kv2", res)
+
+		res, err = Resolve(ctx, "vault://kv/sftp-gw#passphrase")
+		require.NoError(t, err)
+		require.Equal(t, "kv1", res)
+
+		_, err = Resolve(ctx, "vault://kv/sftp-gw#missing")
+		require.Error(t, err)
+		_, err = Resolve(ctx, "vault://kv/sftp-gw")
+		require.Error(t, err)
+		_, err = Resolve(ctx, "vault://kv/other#passphrase")
+		require.Error(t, err)
+
+		t.Setenv("VAULT_TOKEN", "")
+		_, err = Resolve(ctx, "vault://kv/sftp-gw#passphrase")
+		require.Error(t, err)
+	})
+}
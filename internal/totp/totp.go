@@ -0,0 +1,68 @@
+// Package totp implements time-based one-time passwords (RFC 6238).
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// Period is a time step of generated codes.
+	Period = 30 * time.Second
+
+	digits = 6
+)
+
+// DecodeSecret decodes base32 encoded shared secret as authenticator applications provide it.
+func DecodeSecret(s string) ([]byte, error) {
+	s = strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, fmt.Errorf("decode base32 secret: %w", err)
+	}
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("empty secret")
+	}
+	return secret, nil
+}
+
+// Generate returns code for the secret at the given moment.
+func Generate(secret []byte, t time.Time) string {
+	return generate(secret, uint64(t.Unix())/uint64(Period/time.Second))
+}
+
+// Validate checks code against the secret at the given moment. Skew is the number of
+// adjacent time steps accepted to tolerate clock drift.
+func Validate(secret []byte, code string, t time.Time, skew int) bool {
+	counter := int64(t.Unix()) / int64(Period/time.Second)
+	for i := -skew; i <= skew; i++ {
+		if counter+int64(i) < 0 {
+			continue
+		}
+		expected := generate(secret, uint64(counter+int64(i)))
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func generate(secret []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", digits, value%1000000)
+}
@@ -0,0 +1,50 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test vectors from RFC 6238 appendix B truncated to 6 digits.
+var rfcSecret = []byte("12345678901234567890")
+
+func TestGenerate(t *testing.T) {
+	for unix, code := range map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	} {
+		require.Equal(t, code, Generate(rfcSecret, time.Unix(unix, 0)), unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	code := Generate(rfcSecret, now)
+
+	require.True(t, Validate(rfcSecret, code, now, 0))
+	require.True(t, Validate(rfcSecret, code, now.Add(Period), 1))
+	require.False(t, Validate(rfcSecret, code, now.Add(2*Period), 1))
+	require.False(t, Validate(rfcSecret, "000000", now, 1))
+}
+
+func TestDecodeSecret(t *testing.T) {
+	encoded := base32.StdEncoding.EncodeToString(rfcSecret)
+
+	secret, err := DecodeSecret(encoded)
+	require.NoError(t, err)
+	require.Equal(t, rfcSecret, secret)
+
+	secret, err = DecodeSecret("gezd gnbv gy3t qojq gezd gnbv gy3t qojq")
+	require.NoError(t, err)
+	require.Equal(t, rfcSecret, secret)
+
+	_, err = DecodeSecret("not base32!")
+	require.Error(t, err)
+}
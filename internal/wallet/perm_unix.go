@@ -0,0 +1,16 @@
+//go:build !windows
+
+package wallet
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// checkPermissions checks that the secret file is accessible by its owner only.
+func checkPermissions(info fs.FileInfo) error {
+	if perm := info.Mode().Perm(); perm&0o077 != 0 {
+		return fmt.Errorf("permissions %#o are too open, it must not be accessible by group and others", perm)
+	}
+	return nil
+}
@@ -1,24 +1,110 @@
 package wallet
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/nspcc-dev/neo-go/cli/flags"
 	"github.com/nspcc-dev/neo-go/cli/input"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/secrets"
 	"github.com/spf13/viper"
 )
 
-// GetPassword gets passphrase for wallet.
-func GetPassword(v *viper.Viper, variable string) *string {
+// GetPassword gets passphrase for wallet from the variable or from the file the file variable
+// refers to. The variable may refer to the secret store. It returns nil if neither is set,
+// the passphrase is prompted then.
+func GetPassword(v *viper.Viper, variable, fileVariable string) (*string, error) {
 	var password *string
 	if v.IsSet(variable) {
-		pwd := v.GetString(variable)
+		pwd, err := secrets.Resolve(context.Background(), v.GetString(variable))
+		if err != nil {
+			return nil, err
+		}
+		password = &pwd
+	} else if path := v.GetString(fileVariable); path != "" {
+		pwd, err := ReadPassphraseFile(path)
+		if err != nil {
+			return nil, err
+		}
 		password = &pwd
 	}
-	return password
+	return password, nil
+}
+
+// ReadPassphraseFile reads the passphrase from the first line of the file, the file must not be
+// accessible by other users.
+func ReadPassphraseFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("passphrase file: %w", err)
+	}
+	if err = checkPermissions(info); err != nil {
+		return "", fmt.Errorf("passphrase file %s: %w", path, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("passphrase file: %w", err)
+	}
+	pwd, _, _ := strings.Cut(string(data), "\n")
+	return strings.TrimSuffix(pwd, "\r"), nil
+}
+
+// NewWallet creates NEP-6 wallet at the path with a new account encrypted with the passphrase
+// and returns the key of the account. The file is accessible by its owner only.
+func NewWallet(path, label, passphrase string) (*keys.PrivateKey, error) {
+	w, err := wallet.NewWallet(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Chmod(path, 0o600); err != nil {
+		return nil, err
+	}
+
+	acc, err := wallet.NewAccount()
+	if err != nil {
+		return nil, err
+	}
+	acc.Label = label
+	if err = acc.Encrypt(passphrase, w.Scrypt); err != nil {
+		return nil, fmt.Errorf("encrypt account: %w", err)
+	}
+	w.AddAccount(acc)
+	if err = w.SavePretty(); err != nil {
+		return nil, fmt.Errorf("save wallet: %w", err)
+	}
+	return acc.PrivateKey(), nil
+}
+
+// ReadNewPassword prompts the passphrase of a new wallet on the terminal twice.
+func ReadNewPassword() (string, error) {
+	pwd, err := input.ReadPassword("Enter passphrase > ")
+	if err != nil {
+		return "", fmt.Errorf("couldn't read passphrase from the terminal: %w", err)
+	}
+	confirm, err := input.ReadPassword("Confirm passphrase > ")
+	if err != nil {
+		return "", fmt.Errorf("couldn't read passphrase from the terminal: %w", err)
+	}
+	if pwd != confirm {
+		return "", errors.New("passphrases don't match")
+	}
+	return pwd, nil
+}
+
+// GetKeyFromHex decodes the private key from the hex string.
+func GetKeyFromHex(keyHex string) (*keys.PrivateKey, error) {
+	key, err := keys.NewPrivateKeyFromHex(strings.TrimPrefix(strings.TrimSpace(keyHex), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return key, nil
 }
 
 // GetKeyFromPath reads wallet and gets private key.
@@ -47,9 +133,10 @@ func GetKeyFromPath(walletPath, addrStr string, password *string) (*keys.Private
 	}
 
 	if password == nil {
+		// The prompt is read from the terminal, never from stdin serving SFTP.
 		pwd, err := input.ReadPassword(fmt.Sprintf("Enter password for %s > ", walletPath))
 		if err != nil {
-			return nil, fmt.Errorf("couldn't read password")
+			return nil, fmt.Errorf("passphrase isn't set and couldn't be read from the terminal: %w", err)
 		}
 		password = &pwd
 	}
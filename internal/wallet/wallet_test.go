@@ -0,0 +1,56 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPassword(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "passphrase")
+	require.NoError(t, os.WriteFile(path, []byte("secret\r\nignored\n"), 0o600))
+
+	v := viper.New()
+	pwd, err := GetPassword(v, "wallet.passphrase", "wallet.passphrase_file")
+	require.NoError(t, err)
+	require.Nil(t, pwd)
+
+	v.Set("wallet.passphrase_file", path)
+	pwd, err = GetPassword(v, "wallet.passphrase", "wallet.passphrase_file")
+	require.NoError(t, err)
+	require.Equal(t, "secret", *pwd)
+
+	v.Set("wallet.passphrase", "")
+	pwd, err = GetPassword(v, "wallet.passphrase", "wallet.passphrase_file")
+	require.NoError(t, err)
+	require.Equal(t, "", *pwd)
+
+	if runtime.GOOS != "windows" {
+		require.NoError(t, os.Chmod(path, 0o644))
+		_, err = ReadPassphraseFile(path)
+		require.Error(t, err)
+	}
+}
+
+func TestGetKeyFromHex(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	for _, s := range []string{
+		hex.EncodeToString(key.Bytes()),
+		"0x" + hex.EncodeToString(key.Bytes()) + "\n",
+	} {
+		res, err := GetKeyFromHex(s)
+		require.NoError(t, err)
+		require.Equal(t, key.Bytes(), res.Bytes())
+	}
+
+	_, err = GetKeyFromHex("abc")
+	require.Error(t, err)
+}
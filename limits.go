@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// sessionLimiter caps the number of simultaneous sessions globally and per source IP.
+// Zero limit means no limit.
+type sessionLimiter struct {
+	mu       sync.Mutex
+	maxTotal int
+	maxPerIP int
+	total    int
+	perIP    map[string]int
+}
+
+func newSessionLimiter(maxTotal, maxPerIP int) *sessionLimiter {
+	return &sessionLimiter{
+		maxTotal: maxTotal,
+		maxPerIP: maxPerIP,
+		perIP:    make(map[string]int),
+	}
+}
+
+// setLimits changes limits, sessions above the new limits are not terminated.
+func (l *sessionLimiter) setLimits(maxTotal, maxPerIP int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.maxTotal, l.maxPerIP = maxTotal, maxPerIP
+}
+
+func (l *sessionLimiter) acquire(ip string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.maxTotal > 0 && l.total >= l.maxTotal {
+		return fmt.Errorf("too many sessions, limit is %d", l.maxTotal)
+	}
+	if l.maxPerIP > 0 && l.perIP[ip] >= l.maxPerIP {
+		return fmt.Errorf("too many sessions from %s, limit is %d", ip, l.maxPerIP)
+	}
+
+	l.total++
+	l.perIP[ip]++
+	return nil
+}
+
+func (l *sessionLimiter) release(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.total--
+	if l.perIP[ip]--; l.perIP[ip] <= 0 {
+		delete(l.perIP, ip)
+	}
+}
@@ -0,0 +1,39 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// watchLevelSignals changes the logger level until ctx is done: SIGUSR1 enables debug logging,
+// SIGUSR2 restores the configured level.
+func (r *reloader) watchLevelSignals(ctx context.Context) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGUSR1, syscall.SIGUSR2)
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-ch:
+			if sig == syscall.SIGUSR1 {
+				r.level.SetLevel(zapcore.DebugLevel)
+				r.log.Info("logger level changed by signal", zap.Stringer("level", zapcore.DebugLevel))
+				continue
+			}
+			if err := r.restoreLevel(); err != nil {
+				r.log.Error("invalid logger level", zap.Error(err))
+				continue
+			}
+			r.log.Info("logger level restored by signal", zap.Stringer("level", r.level.Level()))
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package main
+
+import "context"
+
+// watchLevelSignals does nothing, there are no SIGUSR1 and SIGUSR2 on Windows, the logger
+// level can be changed with the admin API.
+func (r *reloader) watchLevelSignals(context.Context) {}
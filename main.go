@@ -3,68 +3,284 @@ package main
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
 	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/nspcc-dev/neofs-sdk-go/stat"
 	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"github.com/nspcc-dev/neofs-sftp-gw/handlers"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/layer"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/secrets"
 	"github.com/nspcc-dev/neofs-sftp-gw/internal/wallet"
 	"github.com/pkg/sftp"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
-	"golang.org/x/crypto/ssh"
 )
 
 func main() {
-	v, sftpConfig, devConf := newSettings()
-	l := newLogger(v, sftpConfig)
-	g, _ := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
-	app := newHandler(g, l, v, sftpConfig)
+	if runCommand(os.Args[1:]) {
+		return
+	}
+
+	v, cmdline, sftpConfig, err := newSettings(os.Args[1:])
+	if errors.Is(err, pflag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		exitOnError(nil, err)
+	}
+	devConf, err := newDevConfig(v)
+	if err != nil {
+		exitOnError(nil, newStartupError(exitConfig, "invalid built-in server configuration", err))
+	}
+	userV, err := userSettings(v, cmdline)
+	if err != nil {
+		exitOnError(nil, newStartupError(exitConfig, "read user configuration", err))
+	}
+
+	l, level, err := newLogger(v, sftpConfig)
+	if err != nil {
+		exitOnError(nil, newStartupError(exitConfig, "invalid logger configuration", err))
+	}
+	signals := []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	if !devConf.Enabled {
+		// The subsystem serves a single session, so it's finished on hang up instead of reloading.
+		signals = append(signals, syscall.SIGHUP)
+	}
+	g, _ := signal.NotifyContext(context.Background(), signals...)
+	g, stopService := startService(g, l)
+	defer stopService()
+	if err = checkConfigKeys(l, v); err != nil {
+		exitOnError(l, newStartupError(exitConfig, "invalid configuration", err))
+	}
+	if err = validateContainerSettings(userV); err != nil {
+		exitOnError(l, newStartupError(exitConfig, "invalid container settings", err))
+	}
+	wallets := loadWallets(l, userV)
+	fillServerConfig(l, v, userV, wallets, sftpConfig)
+	var tuner *weightTuner
+	if userV.GetBool(cfgWeightsAuto) {
+		tuner = newWeightTuner()
+	}
+
+	nodes := newNodeCalls(sftpConfig.SlowThreshold, tuner.statistic())
+
+	app, signer := newHandler(g, l, userV, sftpConfig, nodes.statistic())
+	app.SetNodeCalls(nodes)
 
 	zap.ReplaceGlobals(l)
 
+	if tracingConf := newTracingConfig(v); tracingConf.Enabled {
+		shutdownTracing, err := initTracing(g, tracingConf)
+		if err != nil {
+			exitOnError(l, newStartupError(exitFailure, "failed to init tracing", err))
+		}
+		flushTraces := func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdownTracing(ctx); err != nil {
+				l.Error("failed to flush traces", zap.Error(err))
+			}
+		}
+		// Fatal errors exit without running the deferred calls.
+		onExit(flushTraces)
+		defer flushTraces()
+	}
+
+	var auth *authenticator
+	limiter := newSessionLimiter(devConf.MaxSessions, devConf.MaxSessionsPerIP)
 	if devConf.Enabled {
-		devServer(app, devConf)
-	} else {
-		server(app)
+		if auth, err = newAuthenticator(l, devConf); err != nil {
+			exitOnError(l, newStartupError(exitConfig, "failed to init authentication", err))
+		}
 	}
-}
 
-func newHandler(ctx context.Context, l *zap.Logger, v *viper.Viper, sftpConfig *handlers.SftpServerConfig) *handlers.App {
-	var (
-		reBalance  = defaultRebalanceTimer
-		conTimeout = defaultConnectTimeout
-		reqTimeout = defaultRequestTimeout
-		poolPeers  = fetchPeers(l, v)
-	)
+	if endpoint := userV.GetString(cfgNeoFSNNSEndpoint); endpoint != "" {
+		resolver, err := newNNSResolver(g, endpoint)
+		if err != nil {
+			exitOnError(l, newStartupError(exitNeoFS, "failed to init NNS resolver", err))
+		}
+		app.SetNNSResolver(resolver)
+	}
 
-	if val := v.GetDuration(cfgConnectTimeout); val > 0 {
-		conTimeout = val
-	} else {
-		l.Warn("invalid connection_timeout, default one will be used", zap.Duration("default", defaultConnectTimeout))
+	if redisConf := newRedisConfig(v); redisConf.Address != "" {
+		cache, err := newRedisCache(g, redisConf)
+		if err != nil {
+			exitOnError(l, newStartupError(exitFailure, "failed to connect to shared cache", err))
+		}
+		defer cache.Close()
+		app.SetSharedCache(cache)
+	}
+
+	if v.GetBool(cfgAuditEnabled) {
+		auditConf, err := newAuditConfig(v)
+		if err != nil {
+			exitOnError(l, newStartupError(exitConfig, "invalid audit configuration", err))
+		}
+		app.StartAudit(g, auditConf)
+	}
+
+	app.StartUsageRefresh(g, v.GetDuration(cfgUsageRefreshInterval))
+
+	if hooks, err := fetchHooks(v); err != nil {
+		exitOnError(l, newStartupError(exitConfig, "invalid hooks configuration", err))
+	} else if len(hooks) > 0 {
+		runner := newHookRunner(l, hooks)
+		app.OnEvent(runner.enqueue)
+		go runner.run(g)
 	}
-	if val := v.GetDuration(cfgRequestTimeout); val > 0 {
-		reqTimeout = val
+
+	r := &reloader{
+		log:        l,
+		v:          v,
+		sftpConfig: sftpConfig,
+		cmdline:    cmdline,
+		level:      level,
+		app:        app,
+		auth:       auth,
+		limiter:    limiter,
+		signer:     signer,
+		wallets:    wallets,
+		poolCtx:    g,
+		poolV:      userV,
+		peers:      fetchPeers(zap.NewNop(), userV),
+		tuner:      tuner,
+		nodes:      nodes,
+		settings:   settingsSnapshot(v, userV),
+	}
+	if devConf.Enabled {
+		go r.watchSignals(g)
+	}
+	go r.watchLevelSignals(g)
+	if v.GetBool(cfgReloadWatch) {
+		go r.watchConfig(g, configFiles(v), v.GetDuration(cfgReloadDelay))
+	}
+	if tuner != nil {
+		interval := userV.GetDuration(cfgWeightsInterval)
+		if interval <= 0 {
+			interval = defaultWeightsInterval
+		}
+		go r.tuneWeights(g, interval)
+	}
+
+	syncConf, err := newSyncConfig(v)
+	if err != nil {
+		exitOnError(l, newStartupError(exitConfig, "invalid sync configuration", err))
+	}
+	// Every session process spawned by sshd in the subsystem mode would run the jobs.
+	if devConf.Enabled || syncConf.Standalone {
+		startSync(g, l, app, syncConf)
+	} else if len(syncConf.Jobs) > 0 {
+		l.Warn("sync jobs run with the built-in server or standalone only, they're not started")
+	}
+
+	exportConf, err := newExportConfig(v)
+	if err != nil {
+		exitOnError(l, newStartupError(exitConfig, "invalid export configuration", err))
+	}
+	var exportDone <-chan struct{}
+	if devConf.Enabled || exportConf.Standalone {
+		exportDone = startExport(g, l, app, exportConf)
+	} else if len(exportConf.Jobs) > 0 {
+		l.Warn("export jobs run with the built-in server or standalone only, they're not started")
+	}
+
+	// sshd spawns the gateway for every session in the subsystem mode, so the admin API, balance
+	// refreshing and the expiration sweep run with the built-in server or standalone jobs only.
+	daemon := devConf.Enabled || syncConf.Standalone || exportConf.Standalone
+
+	// Balances are requested on demand otherwise.
+	if daemon {
+		app.StartBalanceRefresh(g, v.GetDuration(cfgBalanceRefreshInterval))
+	}
+
+	if sweep := v.GetDuration(cfgUploadsSweep); !daemon && sweep > 0 {
+		l.Warn("expiration sweep runs with the built-in server or standalone jobs only, it's not started")
 	} else {
-		l.Warn("invalid request_timeout, default one will be used", zap.Duration("default", defaultRequestTimeout))
+		app.StartExpirationSweep(g, sweep)
+	}
+
+	if adminConf, err := newAdminConfig(v); err != nil {
+		exitOnError(l, newStartupError(exitConfig, "invalid admin API configuration", err))
+	} else if adminConf.Enabled {
+		if daemon {
+			go runAdminServer(g, l, app, r.reload, &level, adminConf)
+		} else {
+			l.Warn("admin API is available with the built-in server or standalone jobs only, it's not started")
+		}
 	}
-	if val := v.GetDuration(cfgRebalanceTimer); val > 0 {
-		reBalance = val
+
+	if devConf.Enabled {
+		srv := &devServer{
+			app:     app,
+			auth:    auth,
+			limiter: limiter,
+			conf:    devConf,
+		}
+		srv.run(g)
+	} else if syncConf.Standalone || exportConf.Standalone {
+		l.Info("running sync and export jobs only")
+		done := g.Done()
+		if !syncConf.Standalone {
+			// One-shot export finishes on its own.
+			done = exportDone
+		}
+		<-done
+		shutdown(app, devConf.ShutdownTimeout)
 	} else {
-		l.Warn("invalid rebalance_timeout, default one will be used", zap.Duration("default", defaultRebalanceTimer))
+		server(g, app, devConf.ShutdownTimeout)
+	}
+}
+
+// loadKey returns the raw key of the wallet section (`wallet` or `wallets.<label>`) if it's set,
+// the key of the wallet account otherwise.
+func loadKey(v *viper.Viper, section string) (*keys.PrivateKey, error) {
+	if keyHex := v.GetString(section + ".key"); keyHex != "" {
+		keyHex, err := secrets.Resolve(context.Background(), keyHex)
+		if err != nil {
+			return nil, err
+		}
+		return wallet.GetKeyFromHex(keyHex)
 	}
 
-	password := wallet.GetPassword(v, cfgWalletPassphrase)
-	key, err := wallet.GetKeyFromPath(v.GetString(cfgWallet), v.GetString(cfgAddress), password)
+	password, err := wallet.GetPassword(v, section+".passphrase", section+".passphrase_file")
 	if err != nil {
-		l.Fatal("could not load NeoFS private key", zap.Error(err))
+		return nil, err
+	}
+	return wallet.GetKeyFromPath(v.GetString(section+".path"), v.GetString(section+".address"), password)
+}
+
+// loadWallets returns the signers of the labelled wallets by lower-case labels. Wallets are
+// loaded once on start, so passphrases may be prompted.
+func loadWallets(l *zap.Logger, v *viper.Viper) map[string]user.Signer {
+	wallets := make(map[string]user.Signer)
+	for label := range v.GetStringMap(cfgWallets) {
+		key, err := loadKey(v, cfgWallets+"."+label)
+		if err != nil {
+			exitOnError(l, newStartupError(exitWallet, "could not load wallet "+label, err))
+		}
+		wallets[label] = user.NewAutoIDSignerRFC6979(key.PrivateKey)
+	}
+	return wallets
+}
+
+func newHandler(ctx context.Context, l *zap.Logger, v *viper.Viper, sftpConfig *handlers.SftpServerConfig,
+	statistic stat.OperationCallback) (*handlers.App, user.Signer) {
+	key, err := loadKey(v, cfgWalletSection)
+	if err != nil {
+		exitOnError(l, newStartupError(exitWallet, "could not load NeoFS private key", err))
 	}
 
 	l.Info("using credentials", zap.String("NeoFS", hex.EncodeToString(key.PublicKey().Bytes())))
@@ -72,54 +288,203 @@ func newHandler(ctx context.Context, l *zap.Logger, v *viper.Viper, sftpConfig *
 	signer := user.NewAutoIDSignerRFC6979(key.PrivateKey)
 	ownerID := signer.UserID()
 
+	conns, _, err := connectNeoFS(ctx, l, v, signer, statistic)
+	if err != nil {
+		exitOnError(l, newStartupError(exitNeoFS, "failed to connect to NeoFS", err))
+	}
+
+	defaultPolicy := v.GetString(cfgNeoFSContainerPolicy)
+	if defaultPolicy == "" {
+		l.Warn("default container policy isn't set, mkdir fails unless the policy is set by a rule or the directory name")
+	}
+
+	return handlers.NewApp(layer.NewNeoFS(conns), signer, &ownerID, l, sftpConfig, defaultPolicy), signer
+}
+
+// connectNeoFS creates the connection pool and gets the network info. If startup retries are enabled,
+// failed attempts are repeated with exponential backoff until max_wait passes or ctx is done.
+func connectNeoFS(ctx context.Context, l *zap.Logger, v *viper.Viper, signer user.Signer,
+	statistic stat.OperationCallback) (*pool.Pool, netmap.NetworkInfo, error) {
+	var (
+		peers    = fetchPeers(l, v)
+		retry    = v.GetBool(cfgStartupRetry)
+		backoff  = startupInitialBackoff
+		deadline time.Time
+	)
+	if maxWait := v.GetDuration(cfgStartupMaxWait); maxWait > 0 {
+		deadline = time.Now().Add(maxWait)
+	}
+
+	for {
+		conns, ni, err := tryConnectNeoFS(ctx, l, v, signer, peers, statistic)
+		if err == nil || !retry {
+			return conns, ni, err
+		}
+		if !deadline.IsZero() && time.Now().Add(backoff).After(deadline) {
+			return nil, ni, fmt.Errorf("gave up waiting for NeoFS: %w", err)
+		}
+
+		l.Warn("NeoFS is unreachable, retrying", zap.Duration("backoff", backoff), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return nil, ni, ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > startupMaxBackoff {
+			backoff = startupMaxBackoff
+		}
+	}
+}
+
+func tryConnectNeoFS(ctx context.Context, l *zap.Logger, v *viper.Viper, signer user.Signer, peers []peerConfig,
+	statistic stat.OperationCallback) (*pool.Pool, netmap.NetworkInfo, error) {
+	conns, err := newPool(ctx, l, v, signer, peers, statistic)
+	if err != nil {
+		return nil, netmap.NetworkInfo{}, err
+	}
+
+	niCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	ni, err := conns.NetworkInfo(niCtx, client.PrmNetworkInfo{})
+	if err != nil {
+		conns.Close()
+		return nil, ni, fmt.Errorf("get network info: %w", err)
+	}
+
+	return conns, ni, nil
+}
+
+// newPool creates the connection pool to the peers and dials it. Statistic is optional.
+func newPool(ctx context.Context, l *zap.Logger, v *viper.Viper, signer user.Signer, peers []peerConfig,
+	statistic stat.OperationCallback) (*pool.Pool, error) {
+	var (
+		conTimeout = defaultConnectTimeout
+		strTimeout = defaultStreamTimeout
+	)
+
+	if val := v.GetDuration(cfgConnectTimeout); val > 0 {
+		conTimeout = val
+	} else {
+		l.Warn("invalid connection_timeout, default one will be used", zap.Duration("default", defaultConnectTimeout))
+	}
+	if val := v.GetDuration(cfgStreamTimeout); val > 0 {
+		strTimeout = val
+	} else {
+		l.Warn("invalid stream_timeout, default one will be used", zap.Duration("default", defaultStreamTimeout))
+	}
+	reBalance, reqTimeout := healthcheckParams(l, v)
+
 	var prm pool.InitParameters
 	prm.SetSigner(signer)
 	prm.SetNodeDialTimeout(conTimeout)
 	prm.SetHealthcheckTimeout(reqTimeout)
+	prm.SetNodeStreamTimeout(strTimeout)
 	prm.SetClientRebalanceInterval(reBalance)
+	if threshold := v.GetUint32(cfgErrorThreshold); threshold > 0 {
+		prm.SetErrorThreshold(threshold)
+	}
+	if lifetime := v.GetUint64(cfgNeoFSSessionLifetime); lifetime > 0 {
+		prm.SetSessionExpirationDuration(lifetime)
+	}
 
-	for _, peer := range poolPeers {
+	if statistic != nil {
+		prm.SetStatisticCallback(statistic)
+	}
+
+	for _, peer := range nodeParams(peers) {
 		prm.AddNode(peer)
 	}
 
 	conns, err := pool.NewPool(prm)
 	if err != nil {
-		l.Fatal("failed to create connection pool", zap.Error(err))
+		return nil, fmt.Errorf("create connection pool: %w", err)
 	}
 
 	if err = conns.Dial(ctx); err != nil {
-		l.Fatal("failed to dial connection pool", zap.Error(err))
+		return nil, fmt.Errorf("dial connection pool: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	return conns, nil
+}
 
-	ni, err := conns.NetworkInfo(ctx, client.PrmNetworkInfo{})
-	if err != nil {
-		l.Fatal("failed to get network info", zap.Error(err))
+// healthcheckParams returns the interval and the timeout of peers health checks. Unhealthy peers
+// are probed with every check as well, so the interval is the one of recovery probes too.
+func healthcheckParams(l *zap.Logger, v *viper.Viper) (interval, timeout time.Duration) {
+	interval, timeout = defaultRebalanceTimer, defaultRequestTimeout
+
+	if val := v.GetDuration(cfgHealthcheckInterval); val > 0 {
+		interval = val
+	} else if val = v.GetDuration(cfgRebalanceTimer); val > 0 {
+		interval = val
+	} else {
+		l.Warn("invalid rebalance_timeout, default one will be used", zap.Duration("default", defaultRebalanceTimer))
+	}
+	if val := v.GetDuration(cfgHealthcheckTimeout); val > 0 {
+		timeout = val
+	} else if val = v.GetDuration(cfgRequestTimeout); val > 0 {
+		timeout = val
+	} else {
+		l.Warn("invalid request_timeout, default one will be used", zap.Duration("default", defaultRequestTimeout))
 	}
+	return interval, timeout
+}
 
-	return handlers.NewApp(conns, signer, &ownerID, l, sftpConfig, ni.MaxObjectSize(), v.GetString(cfgNeoFSContainerPolicy))
+func shutdown(app *handlers.App, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	app.Shutdown(ctx)
+}
+
+// sshClientAddress returns the client address set by sshd for the subsystem.
+func sshClientAddress() string {
+	if fields := strings.Fields(os.Getenv("SSH_CLIENT")); len(fields) >= 2 {
+		return net.JoinHostPort(fields[0], fields[1])
+	}
+	return ""
 }
 
-func server(app *handlers.App) {
+// server serves the subsystem session over stdin and stdout. When ctx is done, the session is finished
+// after the active uploads are completed or shutdownTimeout passes, the processed requests are answered.
+func server(ctx context.Context, app *handlers.App, shutdownTimeout time.Duration) {
+	sessionApp := app.StartSession(os.Getenv("USER"), sshClientAddress(), nil)
+	stdin := pollableStdin(app.Log)
+
 	svr := sftp.NewRequestServer(
-		struct {
+		sessionApp.ExtendConn(struct {
 			io.Reader
 			io.WriteCloser
 		}{
-			os.Stdin,
+			stdin,
 			os.Stdout,
-		},
+		}),
 		sftp.Handlers{
-			FileGet:  app,
-			FilePut:  app,
-			FileCmd:  app,
-			FileList: app,
+			FileGet:  sessionApp,
+			FilePut:  sessionApp,
+			FileCmd:  sessionApp,
+			FileList: sessionApp,
 		},
 	)
 
-	if err := svr.Serve(); err == io.EOF {
+	done := make(chan error, 1)
+	go func() { done <- svr.Serve() }()
+
+	var err error
+	select {
+	case err = <-done:
+		shutdown(app, shutdownTimeout)
+	case <-ctx.Done():
+		app.Log.Info("finishing the session on signal")
+		shutdown(app, shutdownTimeout)
+		// Stop reading requests, the server waits for the responses to be sent and exits.
+		_ = stdin.Close()
+		if err = <-done; errors.Is(err, os.ErrClosed) {
+			err = io.EOF
+		}
+	}
+
+	if err == io.EOF {
 		if err2 := svr.Close(); err2 != nil {
 			app.Log.Fatal("sftp server completed with error:", zap.Error(err2))
 		}
@@ -128,92 +493,3 @@ func server(app *handlers.App) {
 		app.Log.Fatal("sftp server completed with error:", zap.Error(err))
 	}
 }
-
-func devServer(app *handlers.App, devConf devConfig) {
-	config := &ssh.ServerConfig{
-		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
-			app.Log.Debug("Login", zap.String("user", c.User()))
-			if c.User() == "test" && string(pass) == "test" {
-				return nil, nil
-			}
-			return nil, fmt.Errorf("password rejected for %q", c.User())
-		},
-	}
-
-	privateBytes, err := os.ReadFile(devConf.SSHKeyPath)
-	if err != nil {
-		app.Log.Fatal("Failed to load private key", zap.Error(err))
-	}
-
-	private, err := ssh.ParsePrivateKeyWithPassphrase(privateBytes, []byte(devConf.Passphrase))
-	if err != nil {
-		app.Log.Fatal("Failed to parse private key", zap.Error(err))
-	}
-	config.AddHostKey(private)
-
-	listener, err := net.Listen("tcp", devConf.Address)
-	if err != nil {
-		app.Log.Fatal("failed to listen for connection", zap.Error(err))
-	}
-	app.Log.Info("Listening", zap.String("address", listener.Addr().String()))
-
-	nConn, err := listener.Accept()
-	if err != nil {
-		app.Log.Fatal("failed to accept incoming connection", zap.Error(err))
-	}
-
-	_, chans, reqs, err := ssh.NewServerConn(nConn, config)
-	if err != nil {
-		app.Log.Fatal("failed to handshake", zap.Error(err))
-	}
-
-	// The incoming Request channel must be serviced.
-	go ssh.DiscardRequests(reqs)
-
-	for newChannel := range chans {
-		app.Log.Debug("Incoming channel", zap.String("channel type", newChannel.ChannelType()))
-		if newChannel.ChannelType() != "session" {
-			if err := newChannel.Reject(ssh.UnknownChannelType, "unknown channel type"); err != nil {
-				app.Log.Error("reject error", zap.Error(err))
-			}
-			app.Log.Warn("Unknown channel type", zap.String("type", newChannel.ChannelType()))
-			continue
-		}
-		channel, requests, err := newChannel.Accept()
-		if err != nil {
-			app.Log.Fatal("could not accept channel.", zap.Error(err))
-		}
-		app.Log.Debug("Channel accepted")
-
-		go func(in <-chan *ssh.Request) {
-			for req := range in {
-				ok := false
-				switch req.Type {
-				case "subsystem":
-					if string(req.Payload[4:]) == "sftp" {
-						ok = true
-					}
-				}
-				if err := req.Reply(ok, nil); err != nil {
-					app.Log.Error("reply error", zap.Error(err))
-				}
-			}
-		}(requests)
-
-		server := sftp.NewRequestServer(channel, sftp.Handlers{
-			FileGet:  app,
-			FilePut:  app,
-			FileCmd:  app,
-			FileList: app,
-		})
-
-		if err := server.Serve(); err == io.EOF {
-			if err2 := server.Close(); err2 != nil {
-				app.Log.Fatal("sftp server close error", zap.Error(err2))
-			}
-			app.Log.Info("sftp client exited session.")
-		} else if err != nil {
-			app.Log.Fatal("sftp server completed with error:", zap.Error(err))
-		}
-	}
-}
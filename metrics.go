@@ -0,0 +1,167 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/nspcc-dev/neofs-sftp-gw/handlers"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsNamespace = "neofs_sftp_gw"
+
+// usageCollector exposes the cached usage of containers.
+type usageCollector struct {
+	app     *handlers.App
+	size    *prometheus.Desc
+	objects *prometheus.Desc
+}
+
+func newUsageCollector(app *handlers.App) *usageCollector {
+	return &usageCollector{
+		app: app,
+		size: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "container", "used_bytes"),
+			"Sum of payload sizes of the container objects.", []string{"container"}, nil),
+		objects: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "container", "objects"),
+			"Number of the container objects.", []string{"container"}, nil),
+	}
+}
+
+func (c *usageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.size
+	ch <- c.objects
+}
+
+func (c *usageCollector) Collect(ch chan<- prometheus.Metric) {
+	for cnrID, u := range c.app.ContainerUsage() {
+		ch <- prometheus.MustNewConstMetric(c.size, prometheus.GaugeValue, float64(u.Size), cnrID.EncodeToString())
+		ch <- prometheus.MustNewConstMetric(c.objects, prometheus.GaugeValue, float64(u.Objects), cnrID.EncodeToString())
+	}
+}
+
+// balanceCollector exposes the cached balances of the gateway accounts.
+type balanceCollector struct {
+	app     *handlers.App
+	balance *prometheus.Desc
+}
+
+func newBalanceCollector(app *handlers.App) *balanceCollector {
+	return &balanceCollector{
+		app: app,
+		balance: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "account", "balance"),
+			"NeoFS accounting balance of the account.", []string{"account"}, nil),
+	}
+}
+
+func (c *balanceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.balance
+}
+
+func (c *balanceCollector) Collect(ch chan<- prometheus.Metric) {
+	for account, b := range c.app.Balances() {
+		ch <- prometheus.MustNewConstMetric(c.balance, prometheus.GaugeValue, b.Float(), account)
+	}
+}
+
+// transferCollector exposes the transfer counters of the users and of the active sessions.
+// Sessions are labeled only while active to keep the number of series bounded.
+type transferCollector struct {
+	app *handlers.App
+
+	userUploaded   *prometheus.Desc
+	userDownloaded *prometheus.Desc
+	userSessions   *prometheus.Desc
+	userOperations *prometheus.Desc
+	userErrors     *prometheus.Desc
+
+	sessionUploaded   *prometheus.Desc
+	sessionDownloaded *prometheus.Desc
+	sessionOperations *prometheus.Desc
+	sessionErrors     *prometheus.Desc
+}
+
+func newTransferCollector(app *handlers.App) *transferCollector {
+	userDesc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "user", name), help,
+			append([]string{"user"}, labels...), nil)
+	}
+	sessionDesc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "session", name), help,
+			[]string{"session", "user"}, nil)
+	}
+	return &transferCollector{
+		app:            app,
+		userUploaded:   userDesc("uploaded_bytes_total", "Bytes uploaded by all sessions of the user."),
+		userDownloaded: userDesc("downloaded_bytes_total", "Bytes downloaded by all sessions of the user."),
+		userSessions:   userDesc("sessions_total", "Number of sessions started by the user."),
+		userOperations: userDesc("operations_total", "Number of SFTP requests of the user by method.", "method"),
+		userErrors:     userDesc("errors_total", "Number of failed SFTP requests of the user by method.", "method"),
+
+		sessionUploaded:   sessionDesc("uploaded_bytes_total", "Bytes uploaded by the active session."),
+		sessionDownloaded: sessionDesc("downloaded_bytes_total", "Bytes downloaded by the active session."),
+		sessionOperations: sessionDesc("operations_total", "Number of SFTP requests of the active session."),
+		sessionErrors:     sessionDesc("errors_total", "Number of failed SFTP requests of the active session."),
+	}
+}
+
+func (c *transferCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.userUploaded
+	ch <- c.userDownloaded
+	ch <- c.userSessions
+	ch <- c.userOperations
+	ch <- c.userErrors
+	ch <- c.sessionUploaded
+	ch <- c.sessionDownloaded
+	ch <- c.sessionOperations
+	ch <- c.sessionErrors
+}
+
+func (c *transferCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, u := range c.app.UserStats() {
+		ch <- prometheus.MustNewConstMetric(c.userUploaded, prometheus.CounterValue, float64(u.Uploaded), u.User)
+		ch <- prometheus.MustNewConstMetric(c.userDownloaded, prometheus.CounterValue, float64(u.Downloaded), u.User)
+		ch <- prometheus.MustNewConstMetric(c.userSessions, prometheus.CounterValue, float64(u.Sessions), u.User)
+		for method, n := range u.Operations {
+			ch <- prometheus.MustNewConstMetric(c.userOperations, prometheus.CounterValue, float64(n), u.User, method)
+			ch <- prometheus.MustNewConstMetric(c.userErrors, prometheus.CounterValue, float64(u.Errors[method]), u.User, method)
+		}
+	}
+	for _, s := range c.app.Sessions() {
+		ch <- prometheus.MustNewConstMetric(c.sessionUploaded, prometheus.CounterValue, float64(s.Uploaded), s.ID, s.User)
+		ch <- prometheus.MustNewConstMetric(c.sessionDownloaded, prometheus.CounterValue, float64(s.Downloaded), s.ID, s.User)
+		ch <- prometheus.MustNewConstMetric(c.sessionOperations, prometheus.CounterValue, float64(s.Operations), s.ID, s.User)
+		ch <- prometheus.MustNewConstMetric(c.sessionErrors, prometheus.CounterValue, float64(s.Errors), s.ID, s.User)
+	}
+}
+
+// latencyCollector exposes the histograms of NeoFS call durations.
+type latencyCollector struct {
+	app      *handlers.App
+	duration *prometheus.Desc
+}
+
+func newLatencyCollector(app *handlers.App) *latencyCollector {
+	return &latencyCollector{
+		app: app,
+		duration: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "neofs", "request_duration_seconds"),
+			"Duration of NeoFS calls by operation.", []string{"operation"}, nil),
+	}
+}
+
+func (c *latencyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.duration
+}
+
+func (c *latencyCollector) Collect(ch chan<- prometheus.Metric) {
+	for op, l := range c.app.NeoFSLatencies() {
+		ch <- prometheus.MustNewConstHistogram(c.duration, l.Count, l.Sum, l.Buckets, op)
+	}
+}
+
+// newMetricsHandler returns the handler exposing metrics of the App.
+func newMetricsHandler(app *handlers.App) http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(newUsageCollector(app), newBalanceCollector(app), newTransferCollector(app),
+		newLatencyCollector(app))
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/invoker"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/nns"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/unwrap"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sftp-gw/handlers"
+)
+
+// nnsContractID is the ID of NNS contract, it's deployed first in NeoFS chains.
+const nnsContractID = 1
+
+// nnsResolver resolves container domains with NNS contract of NeoFS chain.
+type nnsResolver struct {
+	invoker *invoker.Invoker
+	hash    util.Uint160
+}
+
+func newNNSResolver(ctx context.Context, endpoint string) (*nnsResolver, error) {
+	cli, err := rpcclient.New(ctx, endpoint, rpcclient.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("create RPC client: %w", err)
+	}
+	if err = cli.Init(); err != nil {
+		return nil, fmt.Errorf("init RPC client: %w", err)
+	}
+
+	cs, err := cli.GetContractStateByID(nnsContractID)
+	if err != nil {
+		return nil, fmt.Errorf("get NNS contract: %w", err)
+	}
+
+	return &nnsResolver{
+		invoker: invoker.New(cli, nil),
+		hash:    cs.Hash,
+	}, nil
+}
+
+// ResolveContainerDomain returns the container ID from TXT records of the domain. RPC calls don't
+// accept the context, so the call continues in background if it's done earlier.
+func (r *nnsResolver) ResolveContainerDomain(ctx context.Context, domain string) (cid.ID, error) {
+	type result struct {
+		records [][]byte
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		records, err := unwrap.ArrayOfBytes(r.invoker.Call(r.hash, "resolve", domain, int64(nns.TXT)))
+		done <- result{records: records, err: err}
+	}()
+
+	var records [][]byte
+	select {
+	case <-ctx.Done():
+		return cid.ID{}, fmt.Errorf("resolve %s: %w", domain, ctx.Err())
+	case res := <-done:
+		if res.err != nil {
+			// NNS contract panics with this message for domains that aren't registered.
+			if strings.Contains(res.err.Error(), "token not found") {
+				return cid.ID{}, fmt.Errorf("resolve %s: %w", domain, handlers.ErrDomainNotFound)
+			}
+			return cid.ID{}, fmt.Errorf("resolve %s: %w", domain, res.err)
+		}
+		records = res.records
+	}
+
+	var cnrID cid.ID
+	for _, record := range records {
+		if err := cnrID.DecodeString(string(record)); err == nil {
+			return cnrID, nil
+		}
+	}
+
+	return cid.ID{}, fmt.Errorf("resolve %s: no container ID in TXT records: %w", domain, handlers.ErrDomainNotFound)
+}
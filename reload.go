@@ -0,0 +1,371 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/nspcc-dev/neofs-sftp-gw/handlers"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/layer"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	// oldPoolGrace is the time requests started before the peers change have to be finished with
+	// the previous connection pool.
+	oldPoolGrace = 30 * time.Second
+	// oldPoolCloseDelay limits the time files opened before the peers change have to be finished
+	// with the previous connection pool, it's closed earlier once they're all closed.
+	oldPoolCloseDelay = time.Hour
+
+	// defaultReloadDelay is the time the watched configuration files must stay unchanged
+	// before they're reloaded, editors often write files in several steps.
+	defaultReloadDelay = time.Second
+)
+
+// reloadableSettings are the settings applied on reload with their subkeys, changes
+// of the others are logged as requiring restart.
+var reloadableSettings = []string{
+	cfgLoggerLevel,
+	cfgLoggerSlowThreshold,
+	"limits",
+	cfgUsers,
+	cfgMounts,
+	cfgPeers,
+	cfgPolicyAliases,
+	cfgEACLTemplates,
+	cfgPutTimeout,
+	cfgGetTimeout,
+	cfgUploadsExpiration,
+	cfgUploadsAttributes,
+	cfgUploadsDirectories,
+	cfgUploadsMirrors,
+	"trash",
+	"files",
+	cfgReadOnlyPaths,
+	cfgBalanceForeignOwners,
+	cfgNeoFSTombstoneLifetime,
+	cfgNeoFSContainerRules,
+	cfgNeoFSContainerInline,
+	cfgNeoFSContainerBasicACL,
+	cfgNeoFSContainerEACL,
+	cfgNeoFSContainerAttrs,
+	"neofs.container.nns",
+	cfgNeoFSContainerRmdir,
+	cfgNeoFSContainerHashing,
+	cfgNeoFSContainerQuotas,
+	"neofs.container.waiter",
+	cfgDevUsers,
+	cfgDevAuthorizedKeys,
+	cfgDevRevokedKeys,
+	cfgDevMaxSessions,
+	cfgDevMaxSessionsIP,
+	cfgDevMinRSASize,
+}
+
+// reloader re-reads configuration and applies settings that can be changed
+// without restart: logger level, user mappings, built-in server users, limits and peers.
+type reloader struct {
+	log *zap.Logger
+	v   *viper.Viper
+	// sftpConfig contains command line params the reloaded config is based on.
+	sftpConfig *handlers.SftpServerConfig
+	level      zap.AtomicLevel
+	app        *handlers.App
+	// auth is nil if the built-in server is disabled.
+	auth    *authenticator
+	limiter *sessionLimiter
+	signer  user.Signer
+	// wallets are loaded on start, added ones aren't available until restart.
+	wallets map[string]user.Signer
+	// cmdline are the settings of the flags, they're applied to the reloaded user config.
+	cmdline cmdlineSettings
+
+	// mu serializes reloads by signal and admin API and weights tuning.
+	mu sync.Mutex
+	// poolCtx is the context new connection pools work within, it's also used to dial them.
+	poolCtx context.Context
+	// poolV is the configuration the current connection pool is created with.
+	poolV *viper.Viper
+	// peers are configured peers of the current connection pool.
+	peers []peerConfig
+	// tuner is nil if weights aren't tuned, tuned are peers with the weights applied last.
+	tuner *weightTuner
+	tuned []peerConfig
+	// retired is closed once no files are opened with the pool replaced last.
+	retired <-chan struct{}
+	// nodes provides the statistic callback of connection pools, it passes the calls to tuner.
+	nodes *nodeCalls
+	// settings are the values of the configuration applied last, used to log the changes.
+	settings map[string]string
+}
+
+// watchSignals reloads configuration on every SIGHUP until ctx is done.
+func (r *reloader) watchSignals(ctx context.Context) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ch:
+			if err := r.reload(); err != nil {
+				r.log.Error("failed to reload configuration, keep the current one", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (r *reloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.log.Info("reloading configuration")
+
+	if err := readConfig(r.v); err != nil {
+		return fmt.Errorf("read configuration: %w", err)
+	}
+	if err := checkConfigKeys(r.log, r.v); err != nil {
+		return err
+	}
+	userV, err := userSettings(r.v, r.cmdline)
+	if err != nil {
+		return fmt.Errorf("read user configuration: %w", err)
+	}
+
+	if r.auth != nil {
+		devConf, err := newDevConfig(r.v)
+		if err == nil {
+			err = r.auth.update(devConf)
+			r.limiter.setLimits(devConf.MaxSessions, devConf.MaxSessionsPerIP)
+		}
+		if err != nil {
+			r.log.Error("failed to reload users, keep the current ones", zap.Error(err))
+		}
+	}
+
+	if err = r.level.UnmarshalText([]byte(loggerLevel(r.v, r.sftpConfig))); err != nil {
+		r.log.Error("invalid logger level", zap.Error(err))
+	}
+
+	cfg := *r.sftpConfig
+	fillServerConfig(r.log, r.v, userV, r.wallets, &cfg)
+	r.app.UpdateConfig(&cfg)
+	r.nodes.setThreshold(cfg.SlowThreshold)
+
+	r.reloadPeers(userV)
+
+	settings := settingsSnapshot(r.v, userV)
+	applied, restart := changedSettings(r.settings, settings)
+	r.settings = settings
+	if len(restart) > 0 {
+		r.log.Warn("changed settings are applied after restart", zap.Strings("keys", restart))
+	}
+	r.log.Info("configuration reloaded", zap.Strings("changed", applied))
+	return nil
+}
+
+// restoreLevel sets the configured logger level after it's changed at runtime.
+func (r *reloader) restoreLevel() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.level.UnmarshalText([]byte(loggerLevel(r.v, r.sftpConfig)))
+}
+
+// watchConfig reloads configuration when the configuration files are changed until ctx is done.
+// Directories of the files are watched, so files replaced by editors are noticed too. Any file
+// of the configuration directories is watched.
+func (r *reloader) watchConfig(ctx context.Context, files []string, delay time.Duration) {
+	if delay <= 0 {
+		delay = defaultReloadDelay
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		r.log.Error("failed to watch configuration", zap.Error(err))
+		return
+	}
+	defer watcher.Close()
+
+	watched := make(map[string]struct{}, len(files))
+	for _, file := range files {
+		file = filepath.Clean(file)
+		dir := filepath.Dir(file)
+		if info, err := os.Stat(file); err == nil && info.IsDir() {
+			dir = file
+		}
+		if err = watcher.Add(dir); err != nil {
+			r.log.Error("failed to watch configuration", zap.String("file", file), zap.Error(err))
+			return
+		}
+		watched[file] = struct{}{}
+	}
+	r.log.Info("watching configuration files", zap.Strings("files", files))
+
+	timer := time.NewTimer(delay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-watcher.Errors:
+			r.log.Warn("configuration watcher error", zap.Error(err))
+		case e := <-watcher.Events:
+			if e.Has(fsnotify.Chmod) {
+				continue
+			}
+			_, ok := watched[filepath.Clean(e.Name)]
+			if _, inDir := watched[filepath.Dir(filepath.Clean(e.Name))]; ok || inDir {
+				timer.Reset(delay)
+			}
+		case <-timer.C:
+			if err := r.reload(); err != nil {
+				r.log.Error("failed to reload configuration, keep the current one", zap.Error(err))
+			}
+		}
+	}
+}
+
+// configFiles returns the paths of the main configuration files and directories and the user
+// configuration file, if they're used.
+func configFiles(v *viper.Viper) []string {
+	files := configPaths(v)
+	if v.GetBool(cfgUserEnabled) && v.GetString(cfgUserPath) != "" {
+		files = append(files, v.GetString(cfgUserPath))
+	}
+	return files
+}
+
+// userSettingsPrefix marks the keys of the user configuration in the settings snapshot.
+const userSettingsPrefix = "user:"
+
+// settingsSnapshot returns the settings of the main and the user configuration as strings by
+// their full keys, the keys of the user configuration are prefixed with userSettingsPrefix if
+// it's used.
+func settingsSnapshot(v, userV *viper.Viper) map[string]string {
+	settings := make(map[string]string)
+	for _, key := range v.AllKeys() {
+		settings[key] = fmt.Sprint(v.Get(key))
+	}
+	if userV != v {
+		for _, key := range userV.AllKeys() {
+			settings[userSettingsPrefix+key] = fmt.Sprint(userV.Get(key))
+		}
+	}
+	return settings
+}
+
+// changedSettings returns sorted keys of the settings differing in the snapshots: the ones
+// applied on reload and the ones requiring restart.
+func changedSettings(before, after map[string]string) (applied, restart []string) {
+	keys := make(map[string]struct{})
+	for key, value := range after {
+		if old, ok := before[key]; !ok || old != value {
+			keys[key] = struct{}{}
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			keys[key] = struct{}{}
+		}
+	}
+
+	for key := range keys {
+		if isReloadable(key) {
+			applied = append(applied, key)
+		} else {
+			restart = append(restart, key)
+		}
+	}
+	sort.Strings(applied)
+	sort.Strings(restart)
+	return applied, restart
+}
+
+func isReloadable(key string) bool {
+	key = strings.TrimPrefix(key, userSettingsPrefix)
+	for _, prefix := range reloadableSettings {
+		if key == prefix || strings.HasPrefix(key, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// reloadPeers switches the application to the new connection pool if peers are changed.
+// The previous pool is closed once the opened files are finished.
+func (r *reloader) reloadPeers(v *viper.Viper) {
+	peers := fetchPeers(r.log, v)
+	if samePeers(peers, r.peers) {
+		return
+	}
+
+	if !r.switchPool(v, peers, oldPoolCloseDelay) {
+		return
+	}
+	r.poolV = v
+	r.peers = peers
+	r.tuned = nil
+	r.log.Info("switched to new peers", zap.Int("count", len(peers)))
+}
+
+// switchPool replaces the pool with the new one created for peers, the previous pool is closed
+// once the files opened with it are closed, but not later than maxDelay if it's positive.
+func (r *reloader) switchPool(v *viper.Viper, peers []peerConfig, maxDelay time.Duration) bool {
+	conns, err := newPool(r.poolCtx, r.log, v, r.signer, peers, r.nodes.statistic())
+	if err != nil {
+		r.log.Error("failed to connect to peers, keep the current pool", zap.Error(err))
+		return false
+	}
+
+	old, idle := r.app.ReplaceLayer(layer.NewNeoFS(conns))
+	r.retired = idle
+	go closeOldPool(r.log, old, idle, oldPoolGrace, maxDelay)
+	return true
+}
+
+// closeOldPool closes the replaced pool once the files opened with it are closed, but not earlier
+// than grace passes for the requests started with it and not later than maxDelay if it's positive.
+func closeOldPool(l *zap.Logger, old layer.Layer, idle <-chan struct{}, grace, maxDelay time.Duration) {
+	defer old.Close()
+
+	time.Sleep(grace)
+	var limit <-chan time.Time
+	if maxDelay > 0 {
+		timer := time.NewTimer(maxDelay - grace)
+		defer timer.Stop()
+		limit = timer.C
+	}
+
+	select {
+	case <-idle:
+	case <-limit:
+		l.Warn("previous connection pool is closed with files still open", zap.Duration("delay", maxDelay))
+	}
+}
+
+func samePeers(a, b []peerConfig) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
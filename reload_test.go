@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sftp-gw/handlers"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/layer"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/layer/layertest"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestChangedSettings(t *testing.T) {
+	applied, restart := changedSettings(map[string]string{
+		"logger.level":           "info",
+		"dev.address":            "0.0.0.0:2022",
+		"neofs.container.policy": "REP 2",
+		"users.alice.owners":     "[]",
+	}, map[string]string{
+		"logger.level":                   "debug",
+		"dev.address":                    "0.0.0.0:2222",
+		"neofs.container.policy":         "REP 2",
+		"neofs.container.policy_rules.0": "tmp-*",
+		"mounts.0.name":                  "partner",
+	})
+	require.Equal(t, []string{"logger.level", "mounts.0.name", "neofs.container.policy_rules.0", "users.alice.owners"}, applied)
+	require.Equal(t, []string{"dev.address"}, restart)
+}
+
+func TestSettingsSnapshot(t *testing.T) {
+	v := viper.New()
+	v.Set(cfgLoggerLevel, "info")
+	v.Set(cfgDevListenAddress, "0.0.0.0:2022")
+	userV := viper.New()
+	userV.Set(cfgNeoFSContainerPolicy, "REP 2")
+
+	require.Equal(t, map[string]string{
+		cfgLoggerLevel:      "info",
+		cfgDevListenAddress: "0.0.0.0:2022",
+	}, settingsSnapshot(v, v))
+
+	before := settingsSnapshot(v, userV)
+	require.Equal(t, map[string]string{
+		cfgLoggerLevel:                    "info",
+		cfgDevListenAddress:               "0.0.0.0:2022",
+		"user:" + cfgNeoFSContainerPolicy: "REP 2",
+	}, before)
+
+	v.Set(cfgLoggerLevel, "debug")
+	v.Set(cfgDevListenAddress, "0.0.0.0:2222")
+	userV.Set(cfgNeoFSContainerPolicy, "REP 3")
+	applied, restart := changedSettings(before, settingsSnapshot(v, userV))
+	require.Equal(t, []string{cfgLoggerLevel}, applied)
+	require.Equal(t, []string{cfgDevListenAddress, "user:" + cfgNeoFSContainerPolicy}, restart)
+}
+
+func TestWatchConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte("logger:\n  level: info\n"), 0o600))
+
+	v := viper.New()
+	v.Set(cfgConfigPath, path)
+	require.NoError(t, readConfig(v))
+
+	level := zap.NewAtomicLevelAt(zap.InfoLevel)
+	r := &reloader{
+		log:        zap.NewNop(),
+		v:          v,
+		sftpConfig: &handlers.SftpServerConfig{},
+		level:      level,
+		app:        handlers.NewApp(nil, nil, nil, zap.NewNop(), &handlers.SftpServerConfig{}, ""),
+		settings:   settingsSnapshot(v, v),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.watchConfig(ctx, configFiles(v), 10*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		// The file is rewritten until the watcher is started.
+		_ = os.WriteFile(path, []byte("logger:\n  level: debug\n"), 0o600)
+		return level.Level() == zap.DebugLevel
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+// closeRecorder is the layer reporting its closing.
+type closeRecorder struct {
+	layer.Layer
+	closed chan struct{}
+}
+
+func (c *closeRecorder) Close() {
+	close(c.closed)
+}
+
+func TestCloseOldPool(t *testing.T) {
+	old := &closeRecorder{Layer: layertest.NewMemory(), closed: make(chan struct{})}
+	idle := make(chan struct{})
+	go closeOldPool(zap.NewNop(), old, idle, time.Millisecond, time.Hour)
+
+	select {
+	case <-old.closed:
+		t.Fatal("pool is closed with files open")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(idle)
+	<-old.closed
+
+	// The pool is closed after the delay even if files are still open.
+	old = &closeRecorder{Layer: layertest.NewMemory(), closed: make(chan struct{})}
+	go closeOldPool(zap.NewNop(), old, make(chan struct{}), time.Millisecond, 10*time.Millisecond)
+	<-old.closed
+
+	// Without the delay the pool is closed only after the files.
+	old = &closeRecorder{Layer: layertest.NewMemory(), closed: make(chan struct{})}
+	idle = make(chan struct{})
+	go closeOldPool(zap.NewNop(), old, idle, time.Millisecond, 0)
+
+	select {
+	case <-old.closed:
+		t.Fatal("pool is closed with files open")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(idle)
+	<-old.closed
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/version"
+	"go.uber.org/zap/zapcore"
+)
+
+// reportQueueSize limits reports waiting to be sent, the new ones are dropped when it's full.
+const reportQueueSize = 100
+
+type (
+	reportConfig struct {
+		Enabled bool
+		URL     string
+		Level   zapcore.Level
+		Timeout time.Duration
+	}
+
+	// errorReport is the payload posted to the webhook.
+	errorReport struct {
+		Time    time.Time      `json:"time"`
+		Level   string         `json:"level"`
+		Message string         `json:"message"`
+		Caller  string         `json:"caller,omitempty"`
+		Stack   string         `json:"stack,omitempty"`
+		Fields  map[string]any `json:"fields,omitempty"`
+		Version string         `json:"version"`
+		Host    string         `json:"host,omitempty"`
+	}
+
+	// errorReporter is the logger core posting high-severity entries to the webhook,
+	// so session and request fields the logger is tagged with are reported too.
+	errorReporter struct {
+		*reportSender
+		fields []zapcore.Field
+	}
+
+	reportSender struct {
+		conf   reportConfig
+		client *http.Client
+		queue  chan errorReport
+		host   string
+	}
+)
+
+func newErrorReporter(conf reportConfig) *errorReporter {
+	s := &reportSender{
+		conf:   conf,
+		client: &http.Client{Timeout: conf.Timeout},
+		queue:  make(chan errorReport, reportQueueSize),
+	}
+	s.host, _ = os.Hostname()
+
+	go s.run()
+
+	return &errorReporter{reportSender: s}
+}
+
+func (r *errorReporter) Enabled(lvl zapcore.Level) bool {
+	return lvl >= r.conf.Level
+}
+
+func (r *errorReporter) With(fields []zapcore.Field) zapcore.Core {
+	return &errorReporter{
+		reportSender: r.reportSender,
+		fields:       append(r.fields[:len(r.fields):len(r.fields)], fields...),
+	}
+}
+
+func (r *errorReporter) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if r.Enabled(ent.Level) {
+		return ce.AddCore(ent, r)
+	}
+	return ce
+}
+
+func (r *errorReporter) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range r.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	rep := errorReport{
+		Time:    ent.Time.UTC(),
+		Level:   ent.Level.String(),
+		Message: ent.Message,
+		Stack:   ent.Stack,
+		Fields:  enc.Fields,
+		Version: version.Version,
+		Host:    r.host,
+	}
+	if ent.Caller.Defined {
+		rep.Caller = ent.Caller.TrimmedPath()
+	}
+
+	// The process is going to exit after such entries, so they can't wait in the queue.
+	if ent.Level > zapcore.ErrorLevel {
+		return r.send(rep)
+	}
+
+	select {
+	case r.queue <- rep:
+	default:
+		fmt.Fprintln(os.Stderr, "error report dropped, queue is full")
+	}
+	return nil
+}
+
+func (r *errorReporter) Sync() error {
+	return nil
+}
+
+func (s *reportSender) run() {
+	for rep := range s.queue {
+		if err := s.send(rep); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+}
+
+func (s *reportSender) send(rep errorReport) error {
+	body, err := json.Marshal(rep)
+	if err != nil {
+		return fmt.Errorf("encode error report: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.conf.Timeout)
+	defer cancel()
+
+	if err = postJSON(ctx, s.client, s.conf.URL, body); err != nil {
+		return fmt.Errorf("send error report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestErrorReporter(t *testing.T) {
+	reports := make(chan errorReport, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var rep errorReport
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&rep))
+		reports <- rep
+	}))
+	defer srv.Close()
+
+	reporter := newErrorReporter(reportConfig{URL: srv.URL, Level: zapcore.ErrorLevel, Timeout: time.Second})
+	l := zap.New(reporter).With(zap.String("session", "abc"))
+
+	l.Warn("not reported")
+	l.Error("upload failed", zap.String("request", "def"))
+
+	select {
+	case rep := <-reports:
+		require.Equal(t, "upload failed", rep.Message)
+		require.Equal(t, "error", rep.Level)
+		require.Equal(t, "abc", rep.Fields["session"])
+		require.Equal(t, "def", rep.Fields["request"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("error isn't reported")
+	}
+
+	select {
+	case rep := <-reports:
+		t.Fatalf("unexpected report: %s", rep.Message)
+	default:
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+// revocationList is a set of revoked client keys loaded from a file. Each line of the file is
+// either a SHA256 fingerprint as printed by `ssh-keygen -l` or a public key in authorized_keys
+// format. The file is re-read on modification, so keys are revoked without restart.
+type revocationList struct {
+	log  *zap.Logger
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	revoked map[string]struct{}
+}
+
+func newRevocationList(l *zap.Logger, path string) (*revocationList, error) {
+	rl := &revocationList{
+		log:  l,
+		path: path,
+	}
+	if err := rl.reload(); err != nil {
+		return nil, err
+	}
+	return rl, nil
+}
+
+// isRevoked checks the key and, for certificates, the certified key and the signing CA.
+func (r *revocationList) isRevoked(key ssh.PublicKey) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.reloadIfChanged(); err != nil {
+		// Keep the previously loaded list rather than accepting everything.
+		r.log.Error("couldn't reload revocation list", zap.String("path", r.path), zap.Error(err))
+	}
+
+	keys := []ssh.PublicKey{key}
+	if cert, ok := key.(*ssh.Certificate); ok {
+		keys = append(keys, cert.Key, cert.SignatureKey)
+	}
+
+	for _, k := range keys {
+		if _, ok := r.revoked[ssh.FingerprintSHA256(k)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *revocationList) reloadIfChanged() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(r.modTime) {
+		return nil
+	}
+	return r.reload()
+}
+
+func (r *revocationList) reload() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := parseRevocationList(data)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", r.path, err)
+	}
+
+	r.revoked = revoked
+	r.modTime = info.ModTime()
+	r.log.Info("revocation list loaded", zap.String("path", r.path), zap.Int("keys", len(revoked)))
+	return nil
+}
+
+func parseRevocationList(data []byte) (map[string]struct{}, error) {
+	revoked := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(text, "SHA256:") {
+			revoked[strings.Fields(text)[0]] = struct{}{}
+			continue
+		}
+
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(text))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		revoked[ssh.FingerprintSHA256(key)] = struct{}{}
+	}
+
+	return revoked, scanner.Err()
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestKey(t *testing.T) ssh.PublicKey {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	return key
+}
+
+func TestRevocationList(t *testing.T) {
+	byFingerprint, byKey, allowed := newTestKey(t), newTestKey(t), newTestKey(t)
+
+	path := filepath.Join(t.TempDir(), "revoked")
+	content := "# revoked keys\n" +
+		ssh.FingerprintSHA256(byFingerprint) + " compromised laptop\n" +
+		string(ssh.MarshalAuthorizedKey(byKey))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	rl, err := newRevocationList(zap.NewNop(), path)
+	require.NoError(t, err)
+
+	require.True(t, rl.isRevoked(byFingerprint))
+	require.True(t, rl.isRevoked(byKey))
+	require.False(t, rl.isRevoked(allowed))
+
+	t.Run("reload", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, ssh.MarshalAuthorizedKey(allowed), 0600))
+		require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+
+		require.True(t, rl.isRevoked(allowed))
+		require.False(t, rl.isRevoked(byKey))
+	})
+
+	t.Run("invalid line", func(t *testing.T) {
+		_, err := parseRevocationList([]byte("garbage"))
+		require.Error(t, err)
+	})
+}
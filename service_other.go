@@ -0,0 +1,14 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// startService returns ctx as is, the gateway is run as a service by the init system.
+func startService(ctx context.Context, _ *zap.Logger) (context.Context, func()) {
+	return ctx, func() {}
+}
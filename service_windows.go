@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"golang.org/x/sys/windows/svc"
+)
+
+// service handles requests of the service control manager.
+type service struct {
+	cancel context.CancelFunc
+	// stopped is closed when the gateway is shut down.
+	stopped chan struct{}
+}
+
+// Execute implements svc.Handler. The gateway context is done on the stop request,
+// the service is reported stopped after the gateway is shut down.
+func (s *service) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case <-s.stopped:
+			return false, 0
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				s.cancel()
+				<-s.stopped
+				return false, 0
+			}
+		}
+	}
+}
+
+// startService runs the gateway as a Windows service if it's started by the service control manager.
+// The returned context is done on the stop request, the returned function must be called after
+// the gateway is shut down.
+func startService(ctx context.Context, l *zap.Logger) (context.Context, func()) {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		l.Fatal("failed to detect Windows service mode", zap.Error(err))
+	}
+	if !isService {
+		return ctx, func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s := &service{cancel: cancel, stopped: make(chan struct{})}
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		if err := svc.Run(serviceName, s); err != nil {
+			l.Error("windows service failed", zap.Error(err))
+			cancel()
+		}
+	}()
+
+	return ctx, func() {
+		close(s.stopped)
+		<-finished
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/stat"
+)
+
+// nodeCallsLimit is the number of the latest node calls kept to find the nodes of slow operations.
+const nodeCallsLimit = 1024
+
+type (
+	// nodeCalls keeps the latest NeoFS node calls while slow operations are logged, so that they're
+	// logged with the nodes serving them, and passes all the calls to the next statistic callback.
+	nodeCalls struct {
+		// threshold is the duration of operations logged as slow, calls aren't kept if it's 0.
+		threshold atomic.Int64
+		// next is called for every call, nil if not needed.
+		next stat.OperationCallback
+
+		mu sync.Mutex
+		// calls is the ring of the latest calls, last is the index of the latest one.
+		calls []nodeCall
+		last  int
+	}
+
+	nodeCall struct {
+		endpoint string
+		method   stat.Method
+		start    time.Time
+		end      time.Time
+	}
+)
+
+func newNodeCalls(threshold time.Duration, next stat.OperationCallback) *nodeCalls {
+	n := &nodeCalls{next: next, calls: make([]nodeCall, 0, nodeCallsLimit), last: -1}
+	n.setThreshold(threshold)
+	return n
+}
+
+func (n *nodeCalls) setThreshold(threshold time.Duration) {
+	if n != nil {
+		n.threshold.Store(int64(threshold))
+	}
+}
+
+// statistic returns the pool statistic callback, nil if n is nil.
+func (n *nodeCalls) statistic() stat.OperationCallback {
+	if n == nil {
+		return nil
+	}
+	return n.record
+}
+
+// record is the pool statistic callback.
+func (n *nodeCalls) record(nodeKey []byte, endpoint string, method stat.Method, duration time.Duration, err error) {
+	if n.next != nil {
+		n.next(nodeKey, endpoint, method, duration, err)
+	}
+	if n.threshold.Load() <= 0 {
+		return
+	}
+
+	end := time.Now()
+	call := nodeCall{endpoint: endpoint, method: method, start: end.Add(-duration), end: end}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.last = (n.last + 1) % nodeCallsLimit
+	if len(n.calls) < nodeCallsLimit {
+		n.calls = append(n.calls, call)
+	} else {
+		n.calls[n.last] = call
+	}
+}
+
+// Nodes implements handlers.NodeCalls. Calls made concurrently by other operations of the same
+// kind can't be told apart, so their nodes are returned too.
+func (n *nodeCalls) Nodes(methods []stat.Method, start, end time.Time) []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	seen := make(map[string]struct{})
+	var nodes []string
+	for _, call := range n.calls {
+		if call.start.Before(start) || call.end.After(end) {
+			continue
+		}
+		for _, m := range methods {
+			if call.method != m {
+				continue
+			}
+			if _, ok := seen[call.endpoint]; !ok {
+				seen[call.endpoint] = struct{}{}
+				nodes = append(nodes, call.endpoint)
+			}
+			break
+		}
+	}
+	sort.Strings(nodes)
+	return nodes
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/stat"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeCalls(t *testing.T) {
+	tuner := newWeightTuner()
+	n := newNodeCalls(0, tuner.statistic())
+
+	start := time.Now()
+	n.record(nil, "node1:8080", stat.MethodObjectHead, time.Nanosecond, nil)
+	require.Empty(t, n.Nodes([]stat.Method{stat.MethodObjectHead}, start, time.Now()), "calls aren't kept without threshold")
+
+	n.setThreshold(time.Second)
+	start = time.Now()
+	n.record(nil, "node2:8080", stat.MethodObjectHead, time.Nanosecond, nil)
+	n.record(nil, "node1:8080", stat.MethodObjectHead, time.Nanosecond, nil)
+	n.record(nil, "node2:8080", stat.MethodObjectHead, time.Nanosecond, nil)
+	n.record(nil, "node3:8080", stat.MethodObjectPut, time.Nanosecond, nil)
+	// The call started before the operation isn't its one.
+	n.record(nil, "node4:8080", stat.MethodObjectHead, time.Hour, nil)
+	end := time.Now()
+
+	require.Equal(t, []string{"node1:8080", "node2:8080"}, n.Nodes([]stat.Method{stat.MethodObjectHead}, start, end))
+	require.Equal(t, []string{"node3:8080"}, n.Nodes([]stat.Method{stat.MethodObjectPut, stat.MethodObjectPutStream}, start, end))
+	require.Empty(t, n.Nodes([]stat.Method{stat.MethodObjectHead}, end, time.Now()))
+
+	// Only the latest calls are kept.
+	for i := 0; i < nodeCallsLimit; i++ {
+		n.record(nil, "node5:8080", stat.MethodObjectHead, time.Nanosecond, nil)
+	}
+	require.Equal(t, []string{"node5:8080"}, n.Nodes([]stat.Method{stat.MethodObjectHead}, start, time.Now()))
+
+	// All the calls are passed to the tuner, kept or not.
+	require.Equal(t, uint64(2), tuner.nodes["node1:8080"].requests)
+	require.Equal(t, uint64(nodeCallsLimit), tuner.nodes["node5:8080"].requests)
+
+	var disabled *nodeCalls
+	require.Nil(t, disabled.statistic())
+	disabled.setThreshold(time.Second)
+}
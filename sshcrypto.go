@@ -0,0 +1,91 @@
+package main
+
+import (
+	"crypto/rsa"
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Algorithms supported by the SSH library, including the legacy ones it doesn't enable by default.
+var (
+	sshKeyExchanges = []string{
+		"curve25519-sha256", "curve25519-sha256@libssh.org",
+		"ecdh-sha2-nistp256", "ecdh-sha2-nistp384", "ecdh-sha2-nistp521",
+		"diffie-hellman-group14-sha256", "diffie-hellman-group16-sha512",
+		"diffie-hellman-group14-sha1", "diffie-hellman-group1-sha1",
+	}
+	sshCiphers = []string{
+		"aes128-gcm@openssh.com", "aes256-gcm@openssh.com", "chacha20-poly1305@openssh.com",
+		"aes128-ctr", "aes192-ctr", "aes256-ctr",
+		"aes128-cbc", "3des-cbc", "arcfour256", "arcfour128", "arcfour",
+	}
+	sshMACs = []string{
+		"hmac-sha2-256-etm@openssh.com", "hmac-sha2-512-etm@openssh.com",
+		"hmac-sha2-256", "hmac-sha2-512", "hmac-sha1", "hmac-sha1-96",
+	}
+)
+
+// sshCryptoConfig restricts the algorithms of the built-in server, the defaults of the SSH
+// library are used for empty lists. Lists are in preference order.
+type sshCryptoConfig struct {
+	KeyExchanges []string
+	Ciphers      []string
+	MACs         []string
+	// MinRSAKeySize is the minimum size of RSA host and client keys in bits, 0 means no limit.
+	MinRSAKeySize int
+}
+
+func (c sshCryptoConfig) validate() error {
+	for _, list := range []struct {
+		name      string
+		algos     []string
+		supported []string
+	}{
+		{"key exchange", c.KeyExchanges, sshKeyExchanges},
+		{"cipher", c.Ciphers, sshCiphers},
+		{"MAC", c.MACs, sshMACs},
+	} {
+		for _, algo := range list.algos {
+			if !contains(list.supported, algo) {
+				return fmt.Errorf("unsupported %s algorithm %q", list.name, algo)
+			}
+		}
+	}
+	if c.MinRSAKeySize < 0 {
+		return fmt.Errorf("negative minimum RSA key size %d", c.MinRSAKeySize)
+	}
+	return nil
+}
+
+// apply sets the algorithms of the server config.
+func (c sshCryptoConfig) apply(config *ssh.ServerConfig) {
+	config.KeyExchanges = c.KeyExchanges
+	config.Ciphers = c.Ciphers
+	config.MACs = c.MACs
+}
+
+// checkRSAKeySize returns an error if the key or the key of the certificate is RSA key
+// shorter than the minimum size.
+func checkRSAKeySize(key ssh.PublicKey, minSize int) error {
+	if cert, ok := key.(*ssh.Certificate); ok {
+		key = cert.Key
+	}
+	cryptoKey, ok := key.(ssh.CryptoPublicKey)
+	if !ok {
+		return nil
+	}
+	if rsaKey, ok := cryptoKey.CryptoPublicKey().(*rsa.PublicKey); ok && rsaKey.N.BitLen() < minSize {
+		return fmt.Errorf("RSA key size %d is less than %d bits", rsaKey.N.BitLen(), minSize)
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHCryptoConfig(t *testing.T) {
+	require.NoError(t, sshCryptoConfig{}.validate())
+	require.NoError(t, sshCryptoConfig{
+		KeyExchanges: []string{"curve25519-sha256", "diffie-hellman-group1-sha1"},
+		Ciphers:      []string{"aes256-gcm@openssh.com", "3des-cbc"},
+		MACs:         []string{"hmac-sha2-256-etm@openssh.com"},
+	}.validate())
+	require.Error(t, sshCryptoConfig{Ciphers: []string{"aes256-gcm"}}.validate())
+	require.Error(t, sshCryptoConfig{MACs: []string{"hmac-md5"}}.validate())
+	require.Error(t, sshCryptoConfig{MinRSAKeySize: -1}.validate())
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	rsaPub, err := ssh.NewPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	require.NoError(t, checkRSAKeySize(rsaPub, 0))
+	require.NoError(t, checkRSAKeySize(rsaPub, 1024))
+	require.Error(t, checkRSAKeySize(rsaPub, 2048))
+	require.Error(t, checkRSAKeySize(&ssh.Certificate{Key: rsaPub}, 2048))
+
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshEdPub, err := ssh.NewPublicKey(edPub)
+	require.NoError(t, err)
+	require.NoError(t, checkRSAKeySize(sshEdPub, 4096))
+}
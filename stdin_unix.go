@@ -0,0 +1,20 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+
+	"go.uber.org/zap"
+)
+
+// pollableStdin returns stdin which reading is interrupted by Close, or os.Stdin if it's not supported.
+func pollableStdin(l *zap.Logger) *os.File {
+	fd := os.Stdin.Fd()
+	if err := syscall.SetNonblock(int(fd), true); err != nil {
+		l.Warn("stdin can't be interrupted, signals are handled after the client disconnects", zap.Error(err))
+		return os.Stdin
+	}
+	return os.NewFile(fd, os.Stdin.Name())
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// pollableStdin returns os.Stdin, reading of pipes can't be interrupted on Windows,
+// so signals are handled after the client disconnects.
+func pollableStdin(*zap.Logger) *os.File {
+	return os.Stdin
+}
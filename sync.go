@@ -0,0 +1,384 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/nspcc-dev/neofs-sftp-gw/handlers"
+	"github.com/pkg/sftp"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultSyncInterval = 10 * time.Minute
+	defaultSyncDelay    = 2 * time.Second
+
+	// syncChunkSize is the size of file chunks passed to the writer.
+	syncChunkSize = 1 << 20
+)
+
+type syncJobConfig struct {
+	// Source is the local directory.
+	Source string
+	// Target is the gateway path: the container and the optional prefix, e.g. `/backups/host1`.
+	Target string
+	// Delete removes files deleted locally from the container.
+	Delete bool
+}
+
+type syncConfig struct {
+	Jobs []syncJobConfig
+	// Interval of full rescans catching the changes missed by the watcher.
+	Interval time.Duration
+	// Delay of uploads after the last change of the file, so that it isn't uploaded while it's written.
+	Delay time.Duration
+	// Standalone runs sync jobs only, without serving SFTP.
+	Standalone bool
+}
+
+// syncedFile is the state of the local file when it was synced.
+type syncedFile struct {
+	size    int64
+	modTime time.Time
+}
+
+// syncer mirrors changes of the local directory into the container. Files are written and removed
+// with the SFTP handlers of the session, so they are processed as if they were uploaded by a client.
+type syncer struct {
+	log      *zap.Logger
+	app      *handlers.App
+	job      syncJobConfig
+	interval time.Duration
+	delay    time.Duration
+
+	// synced files by their paths relative to the source directory.
+	synced map[string]syncedFile
+	// dirty files by their paths relative to the source directory and the time of the last change.
+	dirty map[string]time.Time
+}
+
+// startSync runs the sync jobs in background until ctx is done, every job is a separate session.
+func startSync(ctx context.Context, l *zap.Logger, app *handlers.App, conf syncConfig) {
+	for _, job := range conf.Jobs {
+		ctx, cancel := context.WithCancel(ctx)
+		sess := app.StartSession("sync", job.Source, func() error {
+			cancel()
+			return nil
+		})
+
+		s := &syncer{
+			log:      sess.Log.With(zap.String("source", job.Source), zap.String("target", job.Target)),
+			app:      sess,
+			job:      job,
+			interval: conf.Interval,
+			delay:    conf.Delay,
+			synced:   make(map[string]syncedFile),
+			dirty:    make(map[string]time.Time),
+		}
+		go func() {
+			defer sess.EndSession()
+			defer cancel()
+			if err := s.run(ctx); err != nil {
+				s.log.Error("sync job stopped", zap.Error(err))
+			}
+		}()
+	}
+}
+
+func (s *syncer) run(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("create watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err = s.watch(watcher, s.job.Source); err != nil {
+		return err
+	}
+	s.log.Info("sync job started")
+	s.reconcile(ctx)
+
+	rescan := time.NewTicker(s.interval)
+	defer rescan.Stop()
+	flush := time.NewTicker(s.delay)
+	defer flush.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-rescan.C:
+			s.reconcile(ctx)
+		case <-flush.C:
+			s.flush(ctx)
+		case err := <-watcher.Errors:
+			s.log.Warn("watcher error, changes may be missed until rescan", zap.Error(err))
+		case e := <-watcher.Events:
+			s.handleEvent(watcher, e)
+		}
+	}
+}
+
+// watch adds the directory and its subdirectories to the watcher.
+func (s *syncer) watch(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if err = watcher.Add(p); err != nil {
+				return fmt.Errorf("watch %s: %w", p, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *syncer) handleEvent(watcher *fsnotify.Watcher, e fsnotify.Event) {
+	rel, err := filepath.Rel(s.job.Source, e.Name)
+	if err != nil {
+		return
+	}
+	rel = filepath.ToSlash(rel)
+
+	if e.Has(fsnotify.Create) {
+		if info, err := os.Stat(e.Name); err == nil && info.IsDir() {
+			// Files of the new directory may be created before it's watched.
+			if err = s.watch(watcher, e.Name); err != nil {
+				s.log.Warn("failed to watch directory", zap.String("dir", e.Name), zap.Error(err))
+			}
+			s.markDir(e.Name)
+			return
+		}
+	}
+	s.dirty[rel] = time.Now()
+}
+
+// markDir marks all the files of the directory dirty.
+func (s *syncer) markDir(dir string) {
+	_ = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if rel, err := filepath.Rel(s.job.Source, p); err == nil {
+				s.dirty[filepath.ToSlash(rel)] = time.Now()
+			}
+		}
+		return nil
+	})
+}
+
+// flush syncs the dirty files not changed during the delay.
+func (s *syncer) flush(ctx context.Context) {
+	for rel, changed := range s.dirty {
+		if time.Since(changed) < s.delay {
+			continue
+		}
+		delete(s.dirty, rel)
+
+		info, err := os.Stat(filepath.Join(s.job.Source, filepath.FromSlash(rel)))
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			if _, ok := s.synced[rel]; ok && s.job.Delete {
+				s.remove(ctx, rel)
+			}
+			// Files of the removed directory are removed as well.
+			for file := range s.synced {
+				if strings.HasPrefix(file, rel+"/") && s.job.Delete {
+					s.remove(ctx, file)
+				}
+			}
+		case err != nil:
+			s.log.Warn("failed to stat file", zap.String("file", rel), zap.Error(err))
+		case info.Mode().IsRegular() && s.changed(rel, info):
+			s.upload(ctx, rel, info)
+		}
+	}
+}
+
+// changed checks whether the file differs from the synced one.
+func (s *syncer) changed(rel string, info fs.FileInfo) bool {
+	synced, ok := s.synced[rel]
+	return !ok || synced.size != info.Size() || !synced.modTime.Equal(info.ModTime())
+}
+
+// reconcile compares the source directory with the container and syncs the differences.
+func (s *syncer) reconcile(ctx context.Context) {
+	remote, err := s.listRemote(ctx)
+	if err != nil {
+		s.log.Error("failed to list target", zap.Error(err))
+		return
+	}
+
+	local := make(map[string]struct{})
+	err = filepath.WalkDir(s.job.Source, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(s.job.Source, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		local[rel] = struct{}{}
+
+		// Remote files are timestamped on upload, so they are older only if the local file is changed since.
+		if obj, ok := remote[rel]; ok && obj.Size() == info.Size() && !info.ModTime().After(obj.ModTime()) {
+			s.synced[rel] = syncedFile{size: info.Size(), modTime: info.ModTime()}
+			return nil
+		}
+		s.upload(ctx, rel, info)
+		return ctx.Err()
+	})
+	if err != nil {
+		s.log.Error("failed to scan source", zap.Error(err))
+		return
+	}
+
+	if s.job.Delete {
+		for rel := range remote {
+			if _, ok := local[rel]; !ok {
+				s.remove(ctx, rel)
+			}
+		}
+	}
+}
+
+// listRemote returns the files of the target by their paths relative to the target.
+func (s *syncer) listRemote(ctx context.Context) (map[string]*handlers.ObjectInfo, error) {
+	return listTargetFiles(ctx, s.app, s.job.Target)
+}
+
+// listTargetFiles returns the current files of the gateway path `/<container>/<prefix>`
+// by their paths relative to the prefix.
+func listTargetFiles(ctx context.Context, app *handlers.App, target string) (map[string]*handlers.ObjectInfo, error) {
+	cnrName, prefix := splitSyncTarget(target)
+
+	lister, err := app.Filelist(sftp.NewRequest("List", gatewayPath(cnrName)).WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]*handlers.ObjectInfo)
+	files := make([]os.FileInfo, 100)
+	for offset := int64(0); ; {
+		n, err := lister.ListAt(files, offset)
+		for _, file := range files[:n] {
+			// Virtual directories and files of the gateway are skipped.
+			obj, ok := file.(*handlers.ObjectInfo)
+			if !ok {
+				continue
+			}
+			if rel, ok := syncRelativePath(prefix, obj.Name()); ok {
+				res[rel] = obj
+			}
+		}
+		offset += int64(n)
+		if errors.Is(err, io.EOF) || n == 0 {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// upload writes the local file with the handlers of the session.
+func (s *syncer) upload(ctx context.Context, rel string, info fs.FileInfo) {
+	l := s.log.With(zap.String("file", rel))
+	if err := s.writeFile(ctx, rel); err != nil {
+		l.Error("failed to sync file", zap.Error(err))
+		return
+	}
+	s.synced[rel] = syncedFile{size: info.Size(), modTime: info.ModTime()}
+	l.Debug("file synced", zap.Int64("size", info.Size()))
+}
+
+func (s *syncer) writeFile(ctx context.Context, rel string) (err error) {
+	f, err := os.Open(filepath.Join(s.job.Source, filepath.FromSlash(rel)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w, err := s.app.Filewrite(sftp.NewRequest("Put", s.remotePath(rel)).WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			// The incomplete file isn't stored.
+			if t, ok := w.(interface{ TransferError(error) }); ok {
+				t.TransferError(err)
+			}
+		}
+		if closer, ok := w.(io.Closer); ok {
+			if closeErr := closer.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	}()
+
+	buf := make([]byte, syncChunkSize)
+	for off := int64(0); ; {
+		n, readErr := f.ReadAt(buf, off)
+		if n > 0 {
+			if _, err = w.WriteAt(buf[:n], off); err != nil {
+				return err
+			}
+			off += int64(n)
+		}
+		if errors.Is(readErr, io.EOF) {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+// remove removes the file from the target with the handlers of the session.
+func (s *syncer) remove(ctx context.Context, rel string) {
+	l := s.log.With(zap.String("file", rel))
+	if err := s.app.Filecmd(sftp.NewRequest("Remove", s.remotePath(rel)).WithContext(ctx)); err != nil {
+		l.Error("failed to remove synced file", zap.Error(err))
+		return
+	}
+	delete(s.synced, rel)
+	l.Debug("synced file removed")
+}
+
+func (s *syncer) remotePath(rel string) string {
+	return path.Join(gatewayPath(s.job.Target), rel)
+}
+
+// splitSyncTarget splits the target path into the container name and the prefix of file paths.
+func splitSyncTarget(target string) (cnrName, prefix string) {
+	cnrName, prefix, _ = strings.Cut(strings.Trim(target, "/"), "/")
+	return cnrName, prefix
+}
+
+// syncRelativePath returns the path of the container file relative to the prefix, if it's under it.
+func syncRelativePath(prefix, name string) (string, bool) {
+	if prefix == "" {
+		return name, true
+	}
+	rel := strings.TrimPrefix(name, prefix+"/")
+	return rel, rel != name
+}
+
+// gatewayPath returns the absolute path of the gateway.
+func gatewayPath(p string) string {
+	return "/" + strings.Trim(p, "/")
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-sdk-go/container/acl"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/nspcc-dev/neofs-sftp-gw/handlers"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/layer/layertest"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newMemoryApp returns the App working with the in-memory layer and the container created with it.
+func newMemoryApp(t *testing.T, cnrName string) *handlers.App {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	signer := user.NewAutoIDSignerRFC6979(key.PrivateKey)
+	owner := signer.UserID()
+
+	app := handlers.NewApp(layertest.NewMemory(), signer, &owner, zap.NewNop(), &handlers.SftpServerConfig{
+		BasicACL:        acl.PrivateExtended,
+		ContainerWaiter: handlers.ContainerWaiter{PollInterval: time.Millisecond},
+	}, "REP 1")
+	sess := app.StartSession("test", "", nil)
+	t.Cleanup(sess.EndSession)
+
+	require.NoError(t, sess.Filecmd(sftp.NewRequest("Mkdir", gatewayPath(cnrName))))
+	return sess
+}
+
+// targetSizes returns the sizes of the target files by their relative paths.
+func targetSizes(t *testing.T, app *handlers.App, target string) map[string]int64 {
+	files, err := listTargetFiles(context.Background(), app, target)
+	require.NoError(t, err)
+
+	res := make(map[string]int64, len(files))
+	for rel, obj := range files {
+		res[rel] = obj.Size()
+	}
+	return res
+}
+
+func TestSyncPaths(t *testing.T) {
+	cnrName, prefix := splitSyncTarget("/backups/host1/daily/")
+	require.Equal(t, "backups", cnrName)
+	require.Equal(t, "host1/daily", prefix)
+
+	cnrName, prefix = splitSyncTarget("backups")
+	require.Equal(t, "backups", cnrName)
+	require.Empty(t, prefix)
+
+	rel, ok := syncRelativePath("host1", "host1/a/b.txt")
+	require.True(t, ok)
+	require.Equal(t, "a/b.txt", rel)
+
+	_, ok = syncRelativePath("host1", "host10/b.txt")
+	require.False(t, ok)
+
+	rel, ok = syncRelativePath("", "a.txt")
+	require.True(t, ok)
+	require.Equal(t, "a.txt", rel)
+
+	require.Equal(t, "/backups/host1", gatewayPath("backups/host1/"))
+}
+
+func TestSync(t *testing.T) {
+	app := newMemoryApp(t, "backups")
+	source := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(source, "a.txt"), []byte("alpha"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(source, "sub"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "sub", "b.txt"), []byte("beta"), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	const target = "/backups/host1"
+	startSync(ctx, zap.NewNop(), app, syncConfig{
+		Jobs:     []syncJobConfig{{Source: source, Target: target, Delete: true}},
+		Interval: time.Hour,
+		Delay:    10 * time.Millisecond,
+	})
+
+	// Existing files are synced on start.
+	require.Eventually(t, func() bool {
+		sizes := targetSizes(t, app, target)
+		return sizes["a.txt"] == 5 && sizes["sub/b.txt"] == 4
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Changes are picked up by the watcher: new, updated and removed files.
+	require.NoError(t, os.MkdirAll(filepath.Join(source, "new"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "new", "c.txt"), []byte("gamma"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "a.txt"), []byte("alpha, updated"), 0o600))
+	require.NoError(t, os.RemoveAll(filepath.Join(source, "sub")))
+
+	require.Eventually(t, func() bool {
+		sizes := targetSizes(t, app, target)
+		names := make([]string, 0, len(sizes))
+		for rel := range sizes {
+			names = append(names, rel)
+		}
+		sort.Strings(names)
+		return len(names) == 2 && names[0] == "a.txt" && names[1] == "new/c.txt" && sizes["a.txt"] == 14
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/nspcc-dev/neofs-sftp-gw/internal/wallet"
+	"github.com/spf13/pflag"
+)
+
+// runWallet runs the wallet subcommands.
+func runWallet(args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] != "new" {
+		return errors.New("usage: wallet new [flags]")
+	}
+	return runWalletNew(args[1:], out)
+}
+
+// runWalletNew creates NEP-6 wallet with a new account and prints its NeoFS owner address. The wallet
+// is written to wallet.path and encrypted with wallet.passphrase of the configuration unless they're
+// set by the flags, the passphrase is prompted if it's not set at all.
+func runWalletNew(args []string, out io.Writer) error {
+	flags := pflag.NewFlagSet("wallet new", pflag.ContinueOnError)
+	path := flags.StringP("out", "o", "", "path to the wallet, wallet.path of the configuration if not set")
+	passFile := flags.String("passphrase-file", "", "file with the passphrase of the account, wallet.passphrase of the configuration if not set")
+	label := flags.String("label", "sftp-gw", "label of the account")
+	force := flags.Bool("force", false, "overwrite the existing wallet")
+
+	v, err := commandSettings(flags, args)
+	if err != nil {
+		return err
+	}
+	if *path == "" {
+		if *path = v.GetString(cfgWallet); *path == "" {
+			return errors.New("wallet path isn't set, use --out or wallet.path setting")
+		}
+	}
+	if *passFile != "" {
+		v.Set(cfgWalletPassFile, *passFile)
+	}
+	password, err := wallet.GetPassword(v, cfgWalletSection+".passphrase", cfgWalletPassFile)
+	if err != nil {
+		return err
+	}
+	if password == nil {
+		pwd, err := wallet.ReadNewPassword()
+		if err != nil {
+			return err
+		}
+		password = &pwd
+	}
+
+	if _, err = os.Stat(*path); err == nil && !*force {
+		return fmt.Errorf("wallet %s already exists", *path)
+	}
+	if err = os.MkdirAll(filepath.Dir(*path), 0o755); err != nil {
+		return err
+	}
+	key, err := wallet.NewWallet(*path, *label, *password)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Wallet is written to %s\nOwner: %s\nPublic key: %s\n", *path,
+		user.NewAutoIDSignerRFC6979(key.PrivateKey).UserID(), hex.EncodeToString(key.PublicKey().Bytes()))
+	return nil
+}
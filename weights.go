@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/stat"
+	"go.uber.org/zap"
+)
+
+const (
+	// minWeightFactor keeps slow nodes in rotation, so their latency is still measured.
+	minWeightFactor = 0.05
+	// weightChangeThreshold is the relative change of a weight required to rebuild the pool.
+	weightChangeThreshold = 0.2
+)
+
+type (
+	// weightTuner collects latency and errors of the nodes to calculate their weights.
+	weightTuner struct {
+		mu    sync.Mutex
+		nodes map[string]*nodeStat
+	}
+
+	nodeStat struct {
+		requests uint64
+		errors   uint64
+		// latency is the total duration of requests not depending on payload size.
+		latency  time.Duration
+		measured uint64
+	}
+)
+
+func newWeightTuner() *weightTuner {
+	return &weightTuner{nodes: make(map[string]*nodeStat)}
+}
+
+// record is the pool statistic callback.
+func (t *weightTuner) record(_ []byte, endpoint string, method stat.Method, duration time.Duration, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.nodes[endpoint]
+	if !ok {
+		s = new(nodeStat)
+		t.nodes[endpoint] = s
+	}
+
+	s.requests++
+	if err != nil {
+		s.errors++
+		return
+	}
+
+	switch method {
+	case stat.MethodObjectPut, stat.MethodObjectGet, stat.MethodObjectRange, stat.MethodObjectHash,
+		stat.MethodObjectGetStream, stat.MethodObjectRangeStream, stat.MethodObjectPutStream,
+		stat.MethodObjectSearchStream:
+		// Duration of these depends on the amount of data transferred.
+	default:
+		s.latency += duration
+		s.measured++
+	}
+}
+
+// statistic returns the pool statistic callback, nil if t is nil.
+func (t *weightTuner) statistic() stat.OperationCallback {
+	if t == nil {
+		return nil
+	}
+	return t.record
+}
+
+// weights returns peers with the configured weights scaled by the ratio of the overall average
+// latency to the node one and by the node success rate observed since the previous call.
+func (t *weightTuner) weights(peers []peerConfig) []peerConfig {
+	t.mu.Lock()
+	nodes := t.nodes
+	t.nodes = make(map[string]*nodeStat)
+	t.mu.Unlock()
+
+	// Latency is compared to the average one, so nodes without measurements keep their weights.
+	var total time.Duration
+	var measured uint64
+	for _, s := range nodes {
+		total += s.latency
+		measured += s.measured
+	}
+
+	res := make([]peerConfig, len(peers))
+	for i, peer := range peers {
+		res[i] = peer
+
+		s, ok := nodes[peer.Address]
+		if !ok || s.measured == 0 {
+			continue
+		}
+
+		avg := float64(s.latency) / float64(s.measured)
+		overall := float64(total) / float64(measured)
+		success := 1 - float64(s.errors)/float64(s.requests)
+		weight := peer.Weight * success * overall / math.Max(avg, 1)
+		res[i].Weight = math.Max(weight, peer.Weight*minWeightFactor)
+	}
+
+	return res
+}
+
+// tuneWeights periodically rebuilds the pool with weights calculated from the observed latency.
+// The pool can't change weights of connected nodes, so the previous one is closed only after all
+// the files opened with it are closed, and weights aren't tuned until then.
+func (r *reloader) tuneWeights(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		r.mu.Lock()
+		if !isClosed(r.retired) {
+			r.mu.Unlock()
+			r.log.Debug("previous connection pool is still used, skip weights tuning")
+			continue
+		}
+		current := r.tuned
+		if current == nil {
+			current = r.peers
+		}
+		tuned := r.tuner.weights(r.peers)
+		if weightsChanged(current, tuned) && r.switchPool(r.poolV, tuned, 0) {
+			r.tuned = tuned
+			for _, peer := range tuned {
+				r.log.Debug("peer weight tuned", zap.String("address", peer.Address), zap.Float64("weight", peer.Weight))
+			}
+		}
+		r.mu.Unlock()
+	}
+}
+
+// isClosed checks whether the channel is closed, nil one is considered closed.
+func isClosed(ch <-chan struct{}) bool {
+	if ch == nil {
+		return true
+	}
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// weightsChanged checks whether any weight is changed significantly. Pool uses weights relatively,
+// so they are compared normalized.
+func weightsChanged(prev, next []peerConfig) bool {
+	if len(prev) != len(next) {
+		return true
+	}
+
+	prevSum, nextSum := 0.0, 0.0
+	for i := range prev {
+		prevSum += prev[i].Weight
+		nextSum += next[i].Weight
+	}
+	for i := range prev {
+		p, n := prev[i].Weight/prevSum, next[i].Weight/nextSum
+		if math.Abs(n-p) > p*weightChangeThreshold {
+			return true
+		}
+	}
+	return false
+}
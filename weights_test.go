@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/stat"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeightTuner(t *testing.T) {
+	peers := []peerConfig{
+		{Address: "fast:8080", Priority: 1, Weight: 1},
+		{Address: "slow:8080", Priority: 1, Weight: 1},
+		{Address: "idle:8080", Priority: 1, Weight: 1},
+	}
+
+	tuner := newWeightTuner()
+	for i := 0; i < 10; i++ {
+		tuner.record(nil, "fast:8080", stat.MethodObjectHead, 10*time.Millisecond, nil)
+		tuner.record(nil, "slow:8080", stat.MethodObjectHead, 30*time.Millisecond, nil)
+		// Payload transfers don't affect latency.
+		tuner.record(nil, "fast:8080", stat.MethodObjectPutStream, time.Minute, nil)
+	}
+	tuner.record(nil, "slow:8080", stat.MethodObjectHead, 0, errors.New("internal error"))
+
+	tuned := tuner.weights(peers)
+	require.InDelta(t, 2, tuned[0].Weight, 0.01)
+	require.Less(t, tuned[1].Weight, 0.67)
+	require.Equal(t, 1.0, tuned[2].Weight)
+	require.True(t, weightsChanged(peers, tuned))
+
+	// Statistic is reset on every call.
+	require.Equal(t, peers, tuner.weights(peers))
+	require.False(t, weightsChanged(peers, peers))
+}